@@ -12,7 +12,7 @@ import (
 	"github.com/ava-labs/hypersdk/consts"
 )
 
-const BaseSize = consts.Uint64Len*2 + ids.IDLen
+const BaseSize = consts.Uint64Len*3 + ids.IDLen
 
 type Base struct {
 	// Timestamp is the expiry of the transaction (inclusive). Once this time passes and the
@@ -27,6 +27,14 @@ type Base struct {
 	//
 	// If the fee is too low to pay all fees, the transaction will be dropped.
 	MaxFee uint64 `json:"maxFee"`
+
+	// PriorityFee is paid on top of the fee to be included ahead of transactions with
+	// a lower priority fee. It is part of [MaxFee] and is charged in full, whether or not
+	// there was any congestion.
+	//
+	// It is a flat bid rather than a price per unit: units are already priced in each
+	// dimension by the fee, and the mempool bounds transactions by count, not units.
+	PriorityFee uint64 `json:"priorityFee"`
 }
 
 func (b *Base) Execute(chainID ids.ID, r Rules, timestamp int64) error {
@@ -40,6 +48,8 @@ func (b *Base) Execute(chainID ids.ID, r Rules, timestamp int64) error {
 		return ErrTimestampTooEarly
 	case b.ChainID != chainID:
 		return ErrInvalidChainID
+	case b.PriorityFee > b.MaxFee:
+		return fmt.Errorf("%w: priorityFee=%d maxFee=%d", ErrInvalidPriorityFee, b.PriorityFee, b.MaxFee)
 	default:
 		return nil
 	}
@@ -53,6 +63,7 @@ func (b *Base) Marshal(p *codec.Packer) {
 	p.PackInt64(b.Timestamp)
 	p.PackID(b.ChainID)
 	p.PackUint64(b.MaxFee)
+	p.PackUint64(b.PriorityFee)
 }
 
 func UnmarshalBase(p *codec.Packer) (*Base, error) {
@@ -64,5 +75,9 @@ func UnmarshalBase(p *codec.Packer) (*Base, error) {
 	}
 	p.UnpackID(true, &base.ChainID)
 	base.MaxFee = p.UnpackUint64(true)
+	base.PriorityFee = p.UnpackUint64(false)
+	if base.PriorityFee > base.MaxFee {
+		return nil, fmt.Errorf("%w: priorityFee=%d maxFee=%d", ErrInvalidPriorityFee, base.PriorityFee, base.MaxFee)
+	}
 	return &base, p.Err()
 }
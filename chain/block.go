@@ -29,8 +29,9 @@ import (
 )
 
 var (
-	_ snowman.Block      = &StatelessBlock{}
-	_ block.StateSummary = &SyncableBlock{}
+	_ snowman.Block           = &StatelessBlock{}
+	_ block.WithVerifyContext = &StatelessBlock{}
+	_ block.StateSummary      = &SyncableBlock{}
 )
 
 type StatefulBlock struct {
@@ -98,6 +99,11 @@ type StatelessBlock struct {
 	bytes  []byte
 	txsSet set.Set[ids.ID]
 
+	// bctx is provided by the ProposerVM and is required to verify warp
+	// messages
+	bctx         *block.Context
+	containsWarp bool
+
 	results    []*Result
 	feeManager *fees.Manager
 
@@ -167,6 +173,11 @@ func (b *StatelessBlock) populateTxs(ctx context.Context) error {
 		}
 		b.txsSet.Add(tx.ID())
 
+		// Track if we need a block context to verify warp messages
+		if tx.WarpMessage != nil {
+			b.containsWarp = true
+		}
+
 		// Verify signature async
 		if b.vm.GetVerifyAuth() {
 			txDigest, err := tx.Digest()
@@ -244,6 +255,9 @@ func (b *StatelessBlock) initializeBuilt(
 	b.txsSet = set.NewSet[ids.ID](len(b.Txs))
 	for _, tx := range b.Txs {
 		b.txsSet.Add(tx.ID())
+		if tx.WarpMessage != nil {
+			b.containsWarp = true
+		}
 	}
 	return nil
 }
@@ -251,6 +265,17 @@ func (b *StatelessBlock) initializeBuilt(
 // implements "snowman.Block.choices.Decidable"
 func (b *StatelessBlock) ID() ids.ID { return b.id }
 
+// implements "block.WithVerifyContext"
+func (b *StatelessBlock) ShouldVerifyWithContext(context.Context) (bool, error) {
+	return b.containsWarp, nil
+}
+
+// implements "block.WithVerifyContext"
+func (b *StatelessBlock) VerifyWithContext(ctx context.Context, bctx *block.Context) error {
+	b.bctx = bctx
+	return b.Verify(ctx)
+}
+
 // implements "snowman.Block"
 func (b *StatelessBlock) Verify(ctx context.Context) error {
 	start := time.Now()
@@ -347,6 +372,9 @@ func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) er
 	if b.Timestamp().UnixMilli() > time.Now().Add(FutureBound).UnixMilli() {
 		return ErrTimestampTooLate
 	}
+	if b.containsWarp && b.bctx == nil {
+		return ErrMissingBlockContext
+	}
 
 	// Fetch view where we will apply block state transitions
 	//
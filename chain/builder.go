@@ -13,6 +13,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"go.opentelemetry.io/otel/attribute"
@@ -60,10 +61,14 @@ func HandlePreExecute(log logging.Logger, err error) bool {
 }
 
 // TODO: This code is terrible and will be removed during the Vryx integration.
+//
+// [bctx] is the context provided by the ProposerVM, if it is activated. Warp
+// messages can only be verified, and included, with a context.
 func BuildBlock(
 	ctx context.Context,
 	vm VM,
 	parent *StatelessBlock,
+	bctx *block.Context,
 ) (*StatelessBlock, error) {
 	ctx, span := vm.Tracer().Start(ctx, "chain.BuildBlock")
 	defer span.End()
@@ -80,6 +85,7 @@ func BuildBlock(
 		return nil, ErrTimestampTooEarly
 	}
 	b := NewBlock(vm, parent, nextTime)
+	b.bctx = bctx
 
 	// Fetch view where we will apply block state transitions
 	//
@@ -127,6 +133,10 @@ func BuildBlock(
 		txsAttempted = 0
 		results      = []*Result{}
 
+		// refunds are units reserved by included transactions but not used,
+		// which are returned once the block is complete (matching verification)
+		refunds fees.Dimensions
+
 		sm = vm.StateManager()
 
 		// prepareStreamLock ensures we don't overwrite stream prefetching spawned
@@ -138,6 +148,10 @@ func BuildBlock(
 	)
 
 	// Batch fetch items from mempool to unblock incoming RPC/Gossip traffic
+	//
+	// Items are streamed by descending [Base.PriorityFee]. The executor runs
+	// transactions with conflicting keys in the order they are streamed, so
+	// transactions with higher priority fees are included first.
 	mempool.StartStreaming(ctx)
 	b.Txs = []*Transaction{}
 	for time.Since(start) < vm.GetTargetBuildDuration() && !stop {
@@ -170,6 +184,15 @@ func BuildBlock(
 				continue
 			}
 
+			// Keep transactions with warp messages until we build with a
+			// context
+			if tx.WarpMessage != nil && bctx == nil {
+				restorableLock.Lock()
+				restorable = append(restorable, tx)
+				restorableLock.Unlock()
+				continue
+			}
+
 			stateKeys, err := tx.StateKeys(sm)
 			if err != nil {
 				// Drop bad transaction and continue
@@ -178,6 +201,11 @@ func BuildBlock(
 				// adding a transaction to the mempool.
 				continue
 			}
+			units, err := tx.Units(sm, r)
+			if err != nil {
+				// Drop bad transaction and continue
+				continue
+			}
 
 			// Once we get part way through a prefetching job, we start
 			// to prepare for the next stream.
@@ -270,6 +298,19 @@ func BuildBlock(
 					}
 					return nil
 				}
+
+				// We verify the warp message as late as possible, to avoid the
+				// work for transactions that are dropped. Transactions with
+				// invalid warp messages are still included, so they are charged
+				// for the verification.
+				warpVerified := false
+				if tx.WarpMessage != nil {
+					err := tx.VerifyWarpMessage(ctx, r, vm.ValidatorState(), bctx.PChainHeight)
+					if err != nil {
+						log.Debug("warp message verification failed", zap.Stringer("txID", tx.ID()), zap.Error(err))
+					}
+					warpVerified = err == nil
+				}
 				result, err := tx.Execute(
 					ctx,
 					feeManager,
@@ -277,6 +318,7 @@ func BuildBlock(
 					r,
 					tsv,
 					nextTime,
+					warpVerified,
 				)
 				if err != nil {
 					// Returning an error here should be avoided at all costs (can be a DoS). Rather,
@@ -290,11 +332,14 @@ func BuildBlock(
 				defer blockLock.Unlock()
 
 				// Ensure block isn't too big
-				if ok, dimension := feeManager.Consume(result.Units, maxUnits); !ok {
+				//
+				// We reserve the maximum units of the transaction (rather than
+				// [result.Units]) because this is what is checked during verification.
+				if ok, dimension := feeManager.Consume(units, maxUnits); !ok {
 					log.Debug(
 						"skipping tx: too many units",
 						zap.Int("dimension", int(dimension)),
-						zap.Uint64("tx", result.Units[dimension]),
+						zap.Uint64("tx", units[dimension]),
 						zap.Uint64("block units", feeManager.LastConsumed(dimension)),
 						zap.Uint64("max block units", maxUnits[dimension]),
 					)
@@ -309,6 +354,20 @@ func BuildBlock(
 					}
 				}
 
+				refund, err := fees.Sub(units, result.Units)
+				if err != nil {
+					// Should never happen
+					log.Warn("result units exceed reserved units", zap.Error(err))
+					restore = true
+					return err
+				}
+				refunds, err = fees.Add(refunds, refund)
+				if err != nil {
+					// Should never happen
+					restore = true
+					return err
+				}
+
 				// Update block with new transaction
 				tsv.Commit()
 				b.Txs = append(b.Txs, tx)
@@ -365,6 +424,9 @@ func BuildBlock(
 		vm.RecordEmptyBlockBuilt()
 	}
 
+	// Return units reserved but not used by included transactions
+	feeManager.Refund(refunds)
+
 	// Update chain metadata
 	heightKey := HeightKey(sm.HeightKey())
 	heightKeyStr := string(heightKey)
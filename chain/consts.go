@@ -6,6 +6,8 @@ package chain
 import (
 	"time"
 
+	"github.com/ava-labs/avalanchego/utils/units"
+
 	"github.com/ava-labs/hypersdk/keys"
 )
 
@@ -24,6 +26,10 @@ const (
 	// MaxKeyDependencies must be greater than the maximum number of key dependencies
 	// any single task could have when executing a task.
 	MaxKeyDependencies = 100_000_000
+
+	// MaxWarpMessageSize is the max size of the warp message of a transaction
+	// and of the warp message sent by a transaction.
+	MaxWarpMessageSize = 256 * units.KiB
 )
 
 func HeightKey(prefix []byte) []byte {
@@ -152,6 +152,14 @@ type Rules interface {
 	FetchCustom(string) (any, bool)
 }
 
+// RegistryRules is an optional extension of [Rules] that disables registered
+// action and auth types, see [Upgrade]. Transactions using disabled types are
+// dropped.
+type RegistryRules interface {
+	ActionEnabled(typeID uint8) bool
+	AuthEnabled(typeID uint8) bool
+}
+
 type MetadataManager interface {
 	HeightKey() []byte
 	TimestampKey() []byte
@@ -174,6 +182,14 @@ type FeeHandler interface {
 	Deduct(ctx context.Context, addr codec.Address, mu state.Mutable, amount uint64) error
 }
 
+// FeeRefunder is an optional extension of [FeeHandler]. If it is not implemented,
+// [MeteredAction]s are always charged their maximum [ComputeUnits].
+type FeeRefunder interface {
+	// Refund returns [amount] to [addr] during transaction execution when a transaction
+	// used fewer units than were paid for in [Deduct].
+	Refund(ctx context.Context, addr codec.Address, mu state.Mutable, amount uint64) error
+}
+
 // StateManager allows [Chain] to safely store certain types of items in state
 // in a structured manner. If we did not use [StateManager], we may overwrite
 // state written by actions or auth.
@@ -242,6 +258,38 @@ type Action interface {
 	) (outputs [][]byte, err error)
 }
 
+// MeteredAction is an optional interface for [Action]s whose compute usage is only
+// known after execution (e.g. running user code).
+//
+// [ComputeUnits] is treated as a ceiling: it is reserved from the block and paid
+// upfront, and the units that were not consumed are refunded if the transaction
+// succeeds.
+type MeteredAction interface {
+	Action
+
+	// ExecuteMetered is called instead of [Execute] and additionally returns the
+	// compute units consumed. Any value above [ComputeUnits] is capped.
+	ExecuteMetered(
+		ctx context.Context,
+		r Rules,
+		mu state.Mutable,
+		timestamp int64,
+		actor codec.Address,
+		actionID ids.ID,
+	) (outputs [][]byte, computeUnits uint64, err error)
+}
+
+// EventAction is an optional interface for [Action]s that emit [Event]s. Events
+// are encoded in the outputs of the action, which only the action knows how
+// to decode.
+type EventAction interface {
+	Action
+
+	// Events returns the events recorded in [outputs], which were produced by a
+	// successful execution of the action.
+	Events(outputs [][]byte) ([]*Event, error)
+}
+
 type Auth interface {
 	Object
 
@@ -274,6 +322,28 @@ type Auth interface {
 	Sponsor() codec.Address
 }
 
+// StatefulAuth is an optional interface for [Auth]s that also need state to
+// authorize a transaction, for instance when the [Actor] is a contract
+// deciding which signers may act on its behalf.
+//
+// [Authorize] is run by [Transaction.PreExecute], once the [Sponsor] is known
+// to afford the fee: a transaction it rejects is dropped and never charged, so
+// the [Sponsor] only pays for transactions it accepts. The work it does is
+// unpaid for rejected transactions and must be bounded, and is covered by
+// [ComputeUnits] for accepted ones.
+type StatefulAuth interface {
+	Auth
+
+	// StateKeys is a full enumeration of the keys [Authorize] may read, in the
+	// format of [Action.StateKeys]. They are declared with the keys of the
+	// transaction.
+	StateKeys() state.Keys
+
+	// Authorize returns an error if the [Actor] does not accept the transaction.
+	// It is run after [Verify] and may only read state.
+	Authorize(ctx context.Context, r Rules, im state.Immutable, timestamp int64) error
+}
+
 type AuthBatchVerifier interface {
 	Add([]byte, Auth) func() error
 	Done() []func() error
@@ -284,3 +354,23 @@ type AuthFactory interface {
 	Sign(msg []byte) (Auth, error)
 	MaxUnits() (bandwidth uint64, compute uint64)
 }
+
+// UnsignedAuthFactory is an optional interface for [AuthFactory]s that can
+// create their [Auth] before signing, so that transactions can be simulated
+// before their [Base.MaxFee] is set.
+type UnsignedAuthFactory interface {
+	AuthFactory
+
+	// Unsigned returns the [Auth] [Sign] would, with an invalid signature of
+	// the same size. It must never be submitted.
+	Unsigned() (Auth, error)
+}
+
+// StatefulAuthFactory is an optional interface for [AuthFactory]s that sign
+// [StatefulAuth]s, so that their keys are included in [EstimateUnits].
+type StatefulAuthFactory interface {
+	AuthFactory
+
+	// StateKeysMaxChunks is the max chunks of each key in [StatefulAuth.StateKeys].
+	StateKeysMaxChunks() []uint16
+}
@@ -28,6 +28,7 @@ var (
 	ErrStateRootMismatch    = errors.New("state root mismatch")
 	ErrInvalidResult        = errors.New("invalid result")
 	ErrInvalidBlockHeight   = errors.New("invalid block height")
+	ErrMissingBlockContext  = errors.New("missing block context")
 
 	// Tx Correctness
 	ErrInvalidSignature     = errors.New("invalid signature")
@@ -41,18 +42,29 @@ var (
 	ErrContentMissing       = errors.New("content does not exist")
 	ErrWrongOwner           = errors.New("wrong owner")
 	ErrInsufficientTip      = errors.New("insufficient tip")
+	ErrInvalidPriorityFee   = errors.New("priority fee exceeds max fee")
 	ErrAccountNotEmpty      = errors.New("account not empty")
 	ErrServicerMissing      = errors.New("servicer missing")
 	ErrTooManyTxs           = errors.New("too many transactions")
 	ErrActionNotActivated   = errors.New("action not activated")
 	ErrAuthNotActivated     = errors.New("auth not activated")
 	ErrAuthFailed           = errors.New("auth failed")
+	ErrSimulationTooLarge   = errors.New("simulation exceeds max block units")
 	ErrMisalignedTime       = errors.New("misaligned time")
+	ErrInvalidUpgrade       = errors.New("invalid upgrade")
+	ErrUpgradeNotApplied    = errors.New("upgrade not applied by rules")
 	ErrInvalidActor         = errors.New("invalid actor")
 	ErrInvalidSponsor       = errors.New("invalid sponsor")
 	ErrTooManyActions       = errors.New("too many actions")
 	ErrTooManyOutputs       = errors.New("too many outputs")
 
+	// Warp
+	ErrWarpMessageNotAllowed  = errors.New("warp message not allowed")
+	ErrWarpMessageNotVerified = errors.New("warp message not verified")
+	ErrTooManyWarpMessages    = errors.New("too many warp messages")
+	ErrWarpMessageTooLarge    = errors.New("warp message too large")
+	ErrNoWarpSignatures       = errors.New("no warp signatures")
+
 	// Execution Correctness
 	ErrInvalidBalance  = errors.New("invalid balance")
 	ErrBlockTooBig     = errors.New("block too big")
@@ -0,0 +1,42 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+// Event is a message emitted during the execution of an [EventAction]. It
+// is recorded in the outputs of the action, so it is part of the [Result]
+// of the transaction.
+type Event struct {
+	Emitter codec.Address `json:"emitter"`
+	Topic   string        `json:"topic"`
+	Data    []byte        `json:"data"`
+}
+
+func (e *Event) Size() int {
+	return codec.AddressLen + codec.StringLen(e.Topic) + codec.BytesLen(e.Data)
+}
+
+func (e *Event) Marshal(p *codec.Packer) {
+	p.PackAddress(e.Emitter)
+	p.PackString(e.Topic)
+	p.PackBytes(e.Data)
+}
+
+func (e *Event) Bytes() []byte {
+	p := codec.NewWriter(e.Size(), consts.MaxInt)
+	e.Marshal(p)
+	return p.Bytes()
+}
+
+func UnmarshalEvent(p *codec.Packer) (*Event, error) {
+	var e Event
+	p.UnpackAddress(&e.Emitter)
+	e.Topic = p.UnpackString(true)
+	p.UnpackBytes(consts.MaxInt, false, &e.Data)
+	return &e, p.Err()
+}
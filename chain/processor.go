@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/ava-labs/avalanchego/trace"
+	"go.uber.org/zap"
 
 	"github.com/ava-labs/hypersdk/executor"
 	"github.com/ava-labs/hypersdk/fees"
@@ -42,6 +43,10 @@ func (b *StatelessBlock) Execute(
 		e       = executor.New(numTxs, b.vm.GetTransactionExecutionCores(), MaxKeyDependencies, b.vm.GetExecutorVerifyRecorder())
 		ts      = tstate.New(numTxs * 2) // TODO: tune this heuristic
 		results = make([]*Result, numTxs)
+
+		// reserved tracks the units consumed by each transaction before
+		// execution, so any refund can be returned once all results are known
+		reserved = make([]fees.Dimensions, numTxs)
 	)
 
 	// Fetch required keys and execute transactions
@@ -68,6 +73,7 @@ func (b *StatelessBlock) Execute(
 			e.Stop()
 			return nil, nil, fmt.Errorf("%w: %d too large", ErrInvalidUnitsConsumed, d)
 		}
+		reserved[i] = units
 
 		// Prefetch state keys from disk
 		txID := tx.ID()
@@ -92,7 +98,18 @@ func (b *StatelessBlock) Execute(
 				return err
 			}
 
-			result, err := tx.Execute(ctx, feeManager, sm, r, tsv, t)
+			// Invalid warp messages don't invalidate the block, the
+			// transaction is charged for the verification
+			warpVerified := false
+			if tx.WarpMessage != nil {
+				err := tx.VerifyWarpMessage(ctx, r, b.vm.ValidatorState(), b.bctx.PChainHeight)
+				if err != nil {
+					b.vm.Logger().Debug("warp message verification failed", zap.Stringer("txID", txID), zap.Error(err))
+				}
+				warpVerified = err == nil
+			}
+
+			result, err := tx.Execute(ctx, feeManager, sm, r, tsv, t, warpVerified)
 			if err != nil {
 				return err
 			}
@@ -110,6 +127,17 @@ func (b *StatelessBlock) Execute(
 		return nil, nil, err
 	}
 
+	// Return units reserved but not used by any transaction. This happens
+	// after all transactions have been checked against the block limits, so
+	// the block builder performs the exact same checks.
+	for i, result := range results {
+		refund, err := fees.Sub(reserved[i], result.Units)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: result units exceed reserved units", ErrInvalidUnitsConsumed)
+		}
+		feeManager.Refund(refund)
+	}
+
 	// Return tstate that can be used to add block-level keys to state
 	return results, ts, nil
 }
@@ -4,6 +4,8 @@
 package chain
 
 import (
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/fees"
@@ -19,6 +21,10 @@ type Result struct {
 	// to make life easier for indexers.
 	Units fees.Dimensions
 	Fee   uint64
+
+	// WarpMessage is sent by a successful [WarpAction], and signed by the
+	// validators once the transaction is accepted
+	WarpMessage *warp.UnsignedMessage
 }
 
 func (r *Result) Size() int {
@@ -29,7 +35,8 @@ func (r *Result) Size() int {
 			outputSize += codec.BytesLen(output)
 		}
 	}
-	return consts.BoolLen + codec.BytesLen(r.Error) + outputSize + fees.DimensionsLen + consts.Uint64Len
+	return consts.BoolLen + codec.BytesLen(r.Error) + outputSize + fees.DimensionsLen + consts.Uint64Len +
+		codec.BytesLen(unsignedWarpMessageBytes(r.WarpMessage))
 }
 
 func (r *Result) Marshal(p *codec.Packer) error {
@@ -44,6 +51,7 @@ func (r *Result) Marshal(p *codec.Packer) error {
 	}
 	p.PackFixedBytes(r.Units.Bytes())
 	p.PackUint64(r.Fee)
+	p.PackBytes(unsignedWarpMessageBytes(r.WarpMessage))
 	return nil
 }
 
@@ -85,6 +93,11 @@ func UnmarshalResult(p *codec.Packer) (*Result, error) {
 	}
 	result.Units = units
 	result.Fee = p.UnpackUint64(false)
+	warpMessage, err := unpackUnsignedWarpMessage(p)
+	if err != nil {
+		return nil, err
+	}
+	result.WarpMessage = warpMessage
 	// Wait to check if empty until after all results are unpacked.
 	return result, p.Err()
 }
@@ -0,0 +1,154 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// simulationKey marks the context of [Transaction.Simulate].
+type simulationKey struct{}
+
+// SimulationDeadline returns the deadline of [ctx] if it is simulating a
+// transaction. Actions running arbitrary code may stop at it. It is never set
+// when executing blocks, which must be deterministic.
+func SimulationDeadline(ctx context.Context) (time.Time, bool) {
+	if ctx.Value(simulationKey{}) == nil {
+		return time.Time{}, false
+	}
+	return ctx.Deadline()
+}
+
+// SimulatedKey is a key touched by a simulated transaction.
+type SimulatedKey struct {
+	Key []byte `json:"key"`
+
+	// Permissions are the permissions the transaction declared
+	Permissions state.Permissions `json:"permissions"`
+
+	// Chunks is the size of the value of the key, the largest of the value
+	// read and the value written
+	Chunks uint16 `json:"chunks"`
+
+	// Allocated and Written are set if the transaction created or changed
+	// the key
+	Allocated bool `json:"allocated"`
+	Written   bool `json:"written"`
+}
+
+// Simulation is the outcome of [Transaction.Simulate].
+type Simulation struct {
+	Result *Result
+
+	// MaxUnits are the units reserved by the transaction, which its
+	// [Base.MaxFee] must cover
+	MaxUnits fees.Dimensions
+
+	// StateKeys are the declared keys the transaction read, wrote or removed,
+	// even in actions that failed, sorted by key
+	StateKeys []*SimulatedKey
+}
+
+// Simulate executes [t] on top of [im] like a block would, without verifying
+// its signature or changing [im]. Transactions that could not be included in a
+// block return an error, and transactions that could be included but fail
+// return a failed [Result]. Simulations are bounded like a block: transactions
+// that reserve more than [Rules.GetMaxBlockUnits], or that could pay for more,
+// are rejected, and execution is aborted at the deadline of [ctx].
+//
+// [warpVerified] is the result of [VerifyWarpMessage].
+func (t *Transaction) Simulate(
+	ctx context.Context,
+	feeManager *fees.Manager,
+	s StateManager,
+	r Rules,
+	im state.Immutable,
+	timestamp int64,
+	warpVerified bool,
+) (*Simulation, error) {
+	stateKeys, err := t.StateKeys(s)
+	if err != nil {
+		return nil, err
+	}
+	maxUnits, err := t.Units(s, r)
+	if err != nil {
+		return nil, err
+	}
+	maxBlockUnits := r.GetMaxBlockUnits()
+	if !maxBlockUnits.Greater(maxUnits) {
+		return nil, fmt.Errorf("%w: reserves %v (max %v)", ErrSimulationTooLarge, maxUnits, maxBlockUnits)
+	}
+	maxBlockFee, err := t.fee(feeManager, maxBlockUnits)
+	if err != nil {
+		return nil, err
+	}
+	if t.Base.MaxFee > maxBlockFee {
+		return nil, fmt.Errorf("%w: max fee %d (max %d)", ErrSimulationTooLarge, t.Base.MaxFee, maxBlockFee)
+	}
+	ctx = context.WithValue(ctx, simulationKey{}, struct{}{})
+
+	// Fetch the declared keys, like the builder does
+	storage := make(map[string][]byte, len(stateKeys))
+	for k := range stateKeys {
+		v, err := im.GetValue(ctx, []byte(k))
+		if errors.Is(err, database.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		storage[k] = v
+	}
+	tsv := tstate.New(len(stateKeys)).NewView(stateKeys, storage)
+	tsv.TrackTouchedKeys()
+	if err := t.PreExecute(ctx, feeManager, s, r, tsv, timestamp); err != nil {
+		return nil, err
+	}
+	result, err := t.Execute(ctx, feeManager, s, r, tsv, timestamp, warpVerified)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		// Actions may have been cut short, so the result is meaningless
+		return nil, err
+	}
+
+	allocates, writes := tsv.KeyOperations()
+	touched := tsv.TouchedKeys()
+	simulatedKeys := make([]*SimulatedKey, 0, touched.Len())
+	for k := range touched {
+		var chunks uint16
+		if v, ok := storage[k]; ok {
+			chunks, _ = keys.NumChunks(v)
+		}
+		written, ok := writes[k]
+		chunks = max(chunks, written)
+		_, allocated := allocates[k]
+		simulatedKeys = append(simulatedKeys, &SimulatedKey{
+			Key:         []byte(k),
+			Permissions: stateKeys[k],
+			Chunks:      chunks,
+			Allocated:   allocated,
+			Written:     ok,
+		})
+	}
+	sort.Slice(simulatedKeys, func(i, j int) bool {
+		return string(simulatedKeys[i].Key) < string(simulatedKeys[j].Key)
+	})
+	return &Simulation{
+		Result:    result,
+		MaxUnits:  maxUnits,
+		StateKeys: simulatedKeys,
+	}, nil
+}
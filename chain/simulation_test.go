@@ -0,0 +1,119 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// testSimulationState is the state of [Transaction.Simulate] tests, where
+// [testSponsor] holds [testBalance].
+func testSimulationState() state.Immutable {
+	return testMemoryState{
+		string(testBalanceKey(testSponsor)): binary.BigEndian.AppendUint64(nil, testBalance),
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	require := require.New(t)
+
+	tx := newTestTx(1_000, &testAction{computeUnits: 5})
+	sim, err := tx.Simulate(context.Background(), newTestFeeManager(1), &testStateManager{}, &testRules{}, testSimulationState(), testTimestamp, false)
+	require.NoError(err)
+	require.True(sim.Result.Success)
+	require.Equal(sim.MaxUnits, sim.Result.Units)
+	require.Len(sim.StateKeys, 2)
+}
+
+func TestSimulateTouchedKeys(t *testing.T) {
+	require := require.New(t)
+
+	// Keys are reported if they are touched, even by failed actions
+	tx := newTestTx(1_000, &testAction{untouched: true}, &testAction{value: 1, err: errors.New("failed")})
+	sim, err := tx.Simulate(context.Background(), newTestFeeManager(1), &testStateManager{}, &testRules{}, testSimulationState(), testTimestamp, false)
+	require.NoError(err)
+	require.False(sim.Result.Success)
+
+	failedKey := (&testAction{}).testKey(CreateActionID(tx.ID(), 1))
+	require.Len(sim.StateKeys, 2)
+	for _, k := range sim.StateKeys {
+		switch string(k.Key) {
+		case string(testBalanceKey(testSponsor)):
+			require.True(k.Written)
+			require.Equal(state.Read|state.Write, k.Permissions)
+		case string(failedKey):
+			require.False(k.Written)
+			require.Equal(state.Allocate|state.Write, k.Permissions)
+		default:
+			require.FailNow("untouched key", "%x", k.Key)
+		}
+	}
+}
+
+func TestSimulateBounds(t *testing.T) {
+	maxBlockUnits := (&testRules{}).GetMaxBlockUnits()
+	tests := []struct {
+		name string
+		tx   *Transaction
+		err  error
+	}{
+		{
+			name: "max units",
+			tx:   newTestTx(1_000, &testAction{computeUnits: maxBlockUnits[fees.Compute] - 2}),
+		},
+		{
+			name: "too many units",
+			tx:   newTestTx(1_000, &testAction{computeUnits: maxBlockUnits[fees.Compute] - 1}),
+			err:  ErrSimulationTooLarge,
+		},
+		{
+			name: "max fee above the max block fee",
+			tx:   newTestTx(5*1_000+1, &testAction{computeUnits: 1}),
+			err:  ErrSimulationTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.tx.Simulate(context.Background(), newTestFeeManager(1), &testStateManager{}, &testRules{}, testSimulationState(), testTimestamp, false)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestSimulateDeadline(t *testing.T) {
+	require := require.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	_, ok := SimulationDeadline(ctx)
+	require.False(ok)
+
+	// Actions see the deadline of the simulation
+	action := &testAction{computeUnits: 1}
+	tx := newTestTx(1_000, action)
+	_, err := tx.Simulate(ctx, newTestFeeManager(1), &testStateManager{}, &testRules{}, testSimulationState(), testTimestamp, false)
+	require.NoError(err)
+	require.True(action.simulated)
+	require.Equal(deadline, action.deadline)
+
+	// but not when executed in a block
+	_, err = tx.Execute(ctx, newTestFeeManager(1), &testStateManager{}, &testRules{}, newTestView(t, tx), testTimestamp, false)
+	require.NoError(err)
+	require.False(action.simulated)
+
+	// Simulations cut short by their deadline have no result
+	cancel()
+	_, err = tx.Simulate(ctx, newTestFeeManager(1), &testStateManager{}, &testRules{}, testSimulationState(), testTimestamp, false)
+	require.ErrorIs(err, context.Canceled)
+}
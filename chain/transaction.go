@@ -8,6 +8,9 @@ import (
 	"fmt"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	smath "github.com/ava-labs/avalanchego/utils/math"
 
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
@@ -29,6 +32,10 @@ var (
 type Transaction struct {
 	Base *Base `json:"base"`
 
+	// WarpMessage is an optional signed warp message, which is passed to
+	// [WarpAction]s if its signature is valid
+	WarpMessage *warp.Message `json:"warpMessage"`
+
 	Actions []Action `json:"actions"`
 	Auth    Auth     `json:"auth"`
 
@@ -50,12 +57,13 @@ func (t *Transaction) Digest() ([]byte, error) {
 	if len(t.digest) > 0 {
 		return t.digest, nil
 	}
-	size := t.Base.Size() + consts.Uint8Len
+	size := t.Base.Size() + t.warpMessageSize() + consts.Uint8Len
 	for _, action := range t.Actions {
 		size += consts.ByteLen + action.Size()
 	}
 	p := codec.NewWriter(size, consts.NetworkSizeLimit)
 	t.Base.Marshal(p)
+	packWarpMessage(p, t.WarpMessage)
 	p.PackByte(uint8(len(t.Actions)))
 	for _, action := range t.Actions {
 		p.PackByte(action.GetTypeID())
@@ -93,6 +101,13 @@ func (t *Transaction) Sign(
 	return UnmarshalTx(p, actionRegistry, authRegistry)
 }
 
+func (t *Transaction) warpMessageSize() int {
+	if t.WarpMessage == nil {
+		return consts.IntLen
+	}
+	return codec.BytesLen(t.WarpMessage.Bytes())
+}
+
 func (t *Transaction) Bytes() []byte { return t.bytes }
 
 func (t *Transaction) Size() int { return t.size }
@@ -103,6 +118,10 @@ func (t *Transaction) Expiry() int64 { return t.Base.Timestamp }
 
 func (t *Transaction) MaxFee() uint64 { return t.Base.MaxFee }
 
+// Priority orders the transaction in the mempool. It is the absolute
+// [Base.PriorityFee], which is not divided by the units of the transaction.
+func (t *Transaction) Priority() uint64 { return t.Base.PriorityFee }
+
 func (t *Transaction) StateKeys(sm StateManager) (state.Keys, error) {
 	if t.stateKeys != nil {
 		return t.stateKeys, nil
@@ -122,6 +141,13 @@ func (t *Transaction) StateKeys(sm StateManager) (state.Keys, error) {
 			return nil, ErrInvalidKeyValue
 		}
 	}
+	if auth, ok := t.Auth.(StatefulAuth); ok {
+		for k, v := range auth.StateKeys() {
+			if !stateKeys.Add(k, v) {
+				return nil, ErrInvalidKeyValue
+			}
+		}
+	}
 
 	// Cache keys if called again
 	t.stateKeys = stateKeys
@@ -139,6 +165,18 @@ func (t *Transaction) Units(sm StateManager, r Rules) (fees.Dimensions, error) {
 		computeOp.Add(action.ComputeUnits(r))
 	}
 	computeOp.Add(t.Auth.ComputeUnits(r))
+	if t.WarpMessage != nil {
+		warpRules, ok := r.(WarpRules)
+		if !ok {
+			return fees.Dimensions{}, ErrWarpMessageNotAllowed
+		}
+		numSigners, err := t.WarpMessage.Signature.NumSigners()
+		if err != nil {
+			return fees.Dimensions{}, err
+		}
+		computeOp.Add(warpRules.GetWarpBaseComputeUnits())
+		computeOp.MulAdd(uint64(numSigners), warpRules.GetWarpComputeUnitsPerSigner())
+	}
 	maxComputeUnits, err := computeOp.Value()
 	if err != nil {
 		return fees.Dimensions{}, err
@@ -196,8 +234,8 @@ func EstimateUnits(r Rules, actions []Action, authFactory AuthFactory) (fees.Dim
 		writesOp           = math.NewUint64Operator(0)
 	)
 
-	// Calculate over action/auth
-	bandwidth += consts.Uint8Len
+	// Calculate over action/auth, assuming there is no warp message
+	bandwidth += consts.IntLen + consts.Uint8Len
 	for _, action := range actions {
 		bandwidth += consts.ByteLen + uint64(action.Size())
 		actionStateKeysMaxChunks := action.StateKeysMaxChunks()
@@ -208,6 +246,9 @@ func EstimateUnits(r Rules, actions []Action, authFactory AuthFactory) (fees.Dim
 	bandwidth += consts.ByteLen + authBandwidth
 	sponsorStateKeyMaxChunks := r.GetSponsorStateKeysMaxChunks()
 	stateKeysMaxChunks = append(stateKeysMaxChunks, sponsorStateKeyMaxChunks...)
+	if factory, ok := authFactory.(StatefulAuthFactory); ok {
+		stateKeysMaxChunks = append(stateKeysMaxChunks, factory.StateKeysMaxChunks()...)
+	}
 	computeOp.Add(authCompute)
 
 	// Estimate compute costs
@@ -257,6 +298,7 @@ func (t *Transaction) PreExecute(
 	if len(t.Actions) > int(r.GetMaxActionsPerTx()) {
 		return ErrTooManyActions
 	}
+	registry, hasRegistry := r.(RegistryRules)
 	for i, action := range t.Actions {
 		start, end := action.ValidRange(r)
 		if start >= 0 && timestamp < start {
@@ -265,6 +307,9 @@ func (t *Transaction) PreExecute(
 		if end >= 0 && timestamp > end {
 			return fmt.Errorf("%w: action type %d at index %d", ErrActionNotActivated, action.GetTypeID(), i)
 		}
+		if hasRegistry && !registry.ActionEnabled(action.GetTypeID()) {
+			return fmt.Errorf("%w: action type %d at index %d", ErrActionNotActivated, action.GetTypeID(), i)
+		}
 	}
 	start, end := t.Auth.ValidRange(r)
 	if start >= 0 && timestamp < start {
@@ -273,20 +318,51 @@ func (t *Transaction) PreExecute(
 	if end >= 0 && timestamp > end {
 		return ErrAuthNotActivated
 	}
+	if hasRegistry && !registry.AuthEnabled(t.Auth.GetTypeID()) {
+		return ErrAuthNotActivated
+	}
+	if t.WarpMessage != nil {
+		// The signature is verified by the caller, against the validator set
+		// of the P-Chain height of the block
+		if _, _, err := t.preVerifyWarpMessage(r); err != nil {
+			return err
+		}
+	}
 	units, err := t.Units(s, r)
 	if err != nil {
 		return err
 	}
-	fee, err := feeManager.Fee(units)
+	fee, err := t.fee(feeManager, units)
 	if err != nil {
 		return err
 	}
-	return s.CanDeduct(ctx, t.Auth.Sponsor(), im, fee)
+	if err := s.CanDeduct(ctx, t.Auth.Sponsor(), im, fee); err != nil {
+		return err
+	}
+	if auth, ok := t.Auth.(StatefulAuth); ok {
+		if err := auth.Authorize(ctx, r, im, timestamp); err != nil {
+			return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+		}
+	}
+	return nil
+}
+
+// fee is the fee charged for [units], including the priority fee.
+func (t *Transaction) fee(feeManager *fees.Manager, units fees.Dimensions) (uint64, error) {
+	fee, err := feeManager.Fee(units)
+	if err != nil {
+		return 0, err
+	}
+	return smath.Add64(fee, t.Base.PriorityFee)
 }
 
 // Execute after knowing a transaction can pay a fee. Attempt
 // to charge the fee in as many cases as possible.
 //
+// [warpVerified] is the result of [VerifyWarpMessage]. Transactions with
+// invalid warp messages are still executed, so they pay for the
+// verification.
+//
 // Invariant: [PreExecute] is called just before [Execute]
 func (t *Transaction) Execute(
 	ctx context.Context,
@@ -295,6 +371,7 @@ func (t *Transaction) Execute(
 	r Rules,
 	ts *tstate.TStateView,
 	timestamp int64,
+	warpVerified bool,
 ) (*Result, error) {
 	// Always charge fee first
 	units, err := t.Units(s, r)
@@ -302,7 +379,7 @@ func (t *Transaction) Execute(
 		// Should never happen
 		return nil, err
 	}
-	fee, err := feeManager.Fee(units)
+	fee, err := t.fee(feeManager, units)
 	if err != nil {
 		// Should never happen
 		return nil, err
@@ -320,12 +397,43 @@ func (t *Transaction) Execute(
 	var (
 		actionStart   = ts.OpIndex()
 		resultOutputs = [][][]byte{}
+
+		// computeRefund is the compute reserved by [MeteredAction]s that went unused
+		computeRefund uint64
+
+		// incoming is passed to [WarpAction]s and outgoing is sent by them
+		incoming *warp.Message
+		outgoing *warp.UnsignedMessage
 	)
+	if warpVerified {
+		incoming = t.WarpMessage
+	}
 	for i, action := range t.Actions {
-		outputs, err := action.Execute(ctx, r, ts, timestamp, t.Auth.Actor(), CreateActionID(t.ID(), uint8(i)))
+		var (
+			actor    = t.Auth.Actor()
+			actionID = CreateActionID(t.ID(), uint8(i))
+			outputs  [][]byte
+			err      error
+		)
+		switch a := action.(type) {
+		case WarpAction:
+			var payload []byte
+			outputs, payload, err = a.ExecuteWarp(ctx, r, ts, timestamp, actor, actionID, incoming)
+			if err == nil && payload != nil {
+				outgoing, err = outgoingWarpMessage(r, outgoing, payload)
+			}
+		case MeteredAction:
+			var computeUnits uint64
+			outputs, computeUnits, err = a.ExecuteMetered(ctx, r, ts, timestamp, actor, actionID)
+			if maxComputeUnits := action.ComputeUnits(r); computeUnits < maxComputeUnits {
+				computeRefund += maxComputeUnits - computeUnits
+			}
+		default:
+			outputs, err = action.Execute(ctx, r, ts, timestamp, actor, actionID)
+		}
 		if err != nil {
 			ts.Rollback(ctx, actionStart)
-			return &Result{false, utils.ErrBytes(err), resultOutputs, units, fee}, nil
+			return &Result{false, utils.ErrBytes(err), resultOutputs, units, fee, nil}, nil
 		}
 		if outputs == nil {
 			// Ensure output standardization (match form we will
@@ -336,10 +444,27 @@ func (t *Transaction) Execute(
 		// Wait to append outputs until after we check that there aren't too many
 		if len(outputs) > int(r.GetMaxOutputsPerAction()) {
 			ts.Rollback(ctx, actionStart)
-			return &Result{false, utils.ErrBytes(ErrTooManyOutputs), resultOutputs, units, fee}, nil
+			return &Result{false, utils.ErrBytes(ErrTooManyOutputs), resultOutputs, units, fee, nil}, nil
 		}
 		resultOutputs = append(resultOutputs, outputs)
 	}
+
+	// Refund unused compute, if supported by the [StateManager]
+	//
+	// Failed transactions are always charged the full amount.
+	if refunder, ok := s.(FeeRefunder); ok && computeRefund > 0 {
+		units[fees.Compute] -= computeRefund // can't underflow, refund is part of [units]
+		usedFee, err := t.fee(feeManager, units)
+		if err != nil {
+			// Should never happen
+			return nil, err
+		}
+		if err := refunder.Refund(ctx, t.Auth.Sponsor(), ts, fee-usedFee); err != nil {
+			// Should never happen
+			return nil, err
+		}
+		fee = usedFee
+	}
 	return &Result{
 		Success: true,
 		Error:   []byte{},
@@ -348,6 +473,8 @@ func (t *Transaction) Execute(
 
 		Units: units,
 		Fee:   fee,
+
+		WarpMessage: outgoing,
 	}, nil
 }
 
@@ -362,6 +489,7 @@ func (t *Transaction) Marshal(p *codec.Packer) error {
 
 func (t *Transaction) marshalActions(p *codec.Packer) error {
 	t.Base.Marshal(p)
+	packWarpMessage(p, t.WarpMessage)
 	p.PackByte(uint8(len(t.Actions)))
 	for _, action := range t.Actions {
 		actionID := action.GetTypeID()
@@ -424,6 +552,10 @@ func UnmarshalTx(
 	if err != nil {
 		return nil, fmt.Errorf("%w: could not unmarshal base", err)
 	}
+	warpMessage, err := unpackWarpMessage(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal warp message", err)
+	}
 	actions, err := unmarshalActions(p, actionRegistry)
 	if err != nil {
 		return nil, fmt.Errorf("%w: could not unmarshal actions", err)
@@ -447,6 +579,7 @@ func UnmarshalTx(
 
 	var tx Transaction
 	tx.Base = base
+	tx.WarpMessage = warpMessage
 	tx.Actions = actions
 	tx.Auth = auth
 	if err := p.Err(); err != nil {
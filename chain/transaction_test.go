@@ -0,0 +1,338 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+const (
+	testTimestamp = 1_700_000_000_000
+	testBalance   = 1_000_000
+)
+
+var (
+	testChainID = ids.GenerateTestID()
+	testSponsor = codec.Address{0x01}
+)
+
+// testRules charge a unit for every key, chunk and compute unit.
+type testRules struct{}
+
+func (*testRules) NetworkID() uint32             { return testNetworkID }
+func (*testRules) ChainID() ids.ID               { return testChainID }
+func (*testRules) GetMinBlockGap() int64         { return 250 }
+func (*testRules) GetMinEmptyBlockGap() int64    { return 2_500 }
+func (*testRules) GetValidityWindow() int64      { return 60_000 }
+func (*testRules) GetMaxActionsPerTx() uint8     { return 16 }
+func (*testRules) GetMaxOutputsPerAction() uint8 { return 1 }
+func (*testRules) GetBaseComputeUnits() uint64   { return 1 }
+func (*testRules) GetSponsorStateKeysMaxChunks() []uint16 {
+	return []uint16{1}
+}
+func (*testRules) GetStorageKeyReadUnits() uint64       { return 1 }
+func (*testRules) GetStorageValueReadUnits() uint64     { return 1 }
+func (*testRules) GetStorageKeyAllocateUnits() uint64   { return 1 }
+func (*testRules) GetStorageValueAllocateUnits() uint64 { return 1 }
+func (*testRules) GetStorageKeyWriteUnits() uint64      { return 1 }
+func (*testRules) GetStorageValueWriteUnits() uint64    { return 1 }
+func (*testRules) FetchCustom(string) (any, bool)       { return nil, false }
+
+func (*testRules) GetMinUnitPrice() fees.Dimensions {
+	return fees.Dimensions{1, 1, 1, 1, 1}
+}
+
+func (*testRules) GetUnitPriceChangeDenominator() fees.Dimensions {
+	return fees.Dimensions{48, 48, 48, 48, 48}
+}
+
+func (*testRules) GetWindowTargetUnits() fees.Dimensions {
+	return fees.Dimensions{1_000, 1_000, 1_000, 1_000, 1_000}
+}
+
+func (*testRules) GetMaxBlockUnits() fees.Dimensions {
+	return fees.Dimensions{1_000, 1_000, 1_000, 1_000, 1_000}
+}
+
+// testMemoryState is a [state.Immutable] kept in memory.
+type testMemoryState map[string][]byte
+
+func (m testMemoryState) GetValue(_ context.Context, key []byte) ([]byte, error) {
+	if v, ok := m[string(key)]; ok {
+		return v, nil
+	}
+	return nil, database.ErrNotFound
+}
+
+// testStateManager keeps balances in a single chunk key per address, and
+// refunds fees.
+type testStateManager struct{}
+
+func testBalanceKey(addr codec.Address) []byte {
+	return keys.EncodeChunks(append([]byte{0x0}, addr[:]...), 1)
+}
+
+func getTestBalance(ctx context.Context, im state.Immutable, addr codec.Address) (uint64, error) {
+	v, err := im.GetValue(ctx, testBalanceKey(addr))
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+func setTestBalance(ctx context.Context, mu state.Mutable, addr codec.Address, balance uint64) error {
+	return mu.Insert(ctx, testBalanceKey(addr), binary.BigEndian.AppendUint64(nil, balance))
+}
+
+func (*testStateManager) HeightKey() []byte    { return []byte{0x1} }
+func (*testStateManager) TimestampKey() []byte { return []byte{0x2} }
+func (*testStateManager) FeeKey() []byte       { return []byte{0x3} }
+
+func (*testStateManager) SponsorStateKeys(addr codec.Address) state.Keys {
+	return state.Keys{string(testBalanceKey(addr)): state.Read | state.Write}
+}
+
+func (*testStateManager) CanDeduct(ctx context.Context, addr codec.Address, im state.Immutable, amount uint64) error {
+	balance, err := getTestBalance(ctx, im, addr)
+	if err != nil {
+		return err
+	}
+	if balance < amount {
+		return ErrInvalidBalance
+	}
+	return nil
+}
+
+func (*testStateManager) Deduct(ctx context.Context, addr codec.Address, mu state.Mutable, amount uint64) error {
+	balance, err := getTestBalance(ctx, mu, addr)
+	if err != nil {
+		return err
+	}
+	if balance < amount {
+		return ErrInvalidBalance
+	}
+	return setTestBalance(ctx, mu, addr, balance-amount)
+}
+
+func (*testStateManager) Refund(ctx context.Context, addr codec.Address, mu state.Mutable, amount uint64) error {
+	balance, err := getTestBalance(ctx, mu, addr)
+	if err != nil {
+		return err
+	}
+	return setTestBalance(ctx, mu, addr, balance+amount)
+}
+
+// testAction writes [value] to its key, unless [untouched], and fails with
+// [err] if it is set.
+type testAction struct {
+	computeUnits uint64
+	value        byte
+	untouched    bool
+	err          error
+
+	// deadline and simulated are the [SimulationDeadline] of the last
+	// execution
+	deadline  time.Time
+	simulated bool
+}
+
+func (*testAction) GetTypeID() uint8                { return 0 }
+func (*testAction) ValidRange(Rules) (int64, int64) { return -1, -1 }
+func (*testAction) Marshal(*codec.Packer)           {}
+func (*testAction) Size() int                       { return 0 }
+func (a *testAction) ComputeUnits(Rules) uint64     { return a.computeUnits }
+func (*testAction) StateKeysMaxChunks() []uint16    { return []uint16{1} }
+func (*testAction) testKey(actionID ids.ID) []byte  { return keys.EncodeChunks(actionID[:], 1) }
+func (*testAction) Outputs() [][]byte               { return [][]byte{{0x1}} }
+
+func (a *testAction) StateKeys(_ codec.Address, actionID ids.ID) state.Keys {
+	return state.Keys{string(a.testKey(actionID)): state.Allocate | state.Write}
+}
+
+func (a *testAction) Execute(
+	ctx context.Context,
+	_ Rules,
+	mu state.Mutable,
+	_ int64,
+	_ codec.Address,
+	actionID ids.ID,
+) ([][]byte, error) {
+	a.deadline, a.simulated = SimulationDeadline(ctx)
+	if !a.untouched {
+		if err := mu.Insert(ctx, a.testKey(actionID), []byte{a.value}); err != nil {
+			return nil, err
+		}
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return a.Outputs(), nil
+}
+
+// testMeteredAction is a [testAction] that only uses [usedUnits].
+type testMeteredAction struct {
+	*testAction
+
+	usedUnits uint64
+}
+
+func (a *testMeteredAction) ExecuteMetered(
+	ctx context.Context,
+	r Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, uint64, error) {
+	outputs, err := a.Execute(ctx, r, mu, timestamp, actor, actionID)
+	return outputs, a.usedUnits, err
+}
+
+// testAuth is paid by [testSponsor].
+type testAuth struct{}
+
+func (*testAuth) GetTypeID() uint8                     { return 0 }
+func (*testAuth) ValidRange(Rules) (int64, int64)      { return -1, -1 }
+func (*testAuth) Marshal(*codec.Packer)                {}
+func (*testAuth) Size() int                            { return 0 }
+func (*testAuth) ComputeUnits(Rules) uint64            { return 1 }
+func (*testAuth) Verify(context.Context, []byte) error { return nil }
+func (*testAuth) Actor() codec.Address                 { return testSponsor }
+func (*testAuth) Sponsor() codec.Address               { return testSponsor }
+
+// newTestTx creates a transaction running [actions], paid by [testSponsor].
+func newTestTx(maxFee uint64, actions ...Action) *Transaction {
+	tx := NewTx(&Base{Timestamp: testTimestamp, ChainID: testChainID, MaxFee: maxFee}, actions)
+	tx.Auth = &testAuth{}
+	return tx
+}
+
+// newTestFeeManager charges [price] for every unit.
+func newTestFeeManager(price uint64) *fees.Manager {
+	feeManager := fees.NewManager(nil)
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		feeManager.SetUnitPrice(i, price)
+	}
+	return feeManager
+}
+
+// newTestView returns a view of the keys of [tx] over a state where
+// [testSponsor] holds [testBalance].
+func newTestView(t *testing.T, tx *Transaction) *tstate.TStateView {
+	t.Helper()
+
+	stateKeys, err := tx.StateKeys(&testStateManager{})
+	require.NoError(t, err)
+	storage := map[string][]byte{
+		string(testBalanceKey(testSponsor)): binary.BigEndian.AppendUint64(nil, testBalance),
+	}
+	return tstate.New(len(stateKeys)).NewView(stateKeys, storage)
+}
+
+func TestTransactionUnits(t *testing.T) {
+	require := require.New(t)
+
+	tx := newTestTx(consts.MaxUint64, &testAction{computeUnits: 5}, &testAction{computeUnits: 7})
+	units, err := tx.Units(&testStateManager{}, &testRules{})
+	require.NoError(err)
+
+	// One key and chunk for the sponsor and for each action
+	require.Equal(fees.Dimensions{0, 1 + 5 + 7 + 1, 6, 6, 6}, units)
+}
+
+func TestTransactionRefund(t *testing.T) {
+	errTestAction := errors.New("test action failed")
+	tests := []struct {
+		name        string
+		actions     []Action
+		priorityFee uint64
+		success     bool
+		refund      uint64 // compute units
+	}{
+		{
+			name:    "partial refund",
+			actions: []Action{&testMeteredAction{&testAction{computeUnits: 10}, 4}},
+			success: true,
+			refund:  6,
+		},
+		{
+			name: "refunds of several actions",
+			actions: []Action{
+				&testMeteredAction{&testAction{computeUnits: 10}, 4},
+				&testAction{computeUnits: 10},
+				&testMeteredAction{&testAction{computeUnits: 5}, 2},
+			},
+			success: true,
+			refund:  9,
+		},
+		{
+			name:    "zero refund",
+			actions: []Action{&testMeteredAction{&testAction{computeUnits: 10}, 10}},
+			success: true,
+		},
+		{
+			name:    "unmetered action",
+			actions: []Action{&testAction{computeUnits: 10}},
+			success: true,
+		},
+		{
+			name:        "priority fee is not refunded",
+			actions:     []Action{&testMeteredAction{&testAction{computeUnits: 10}, 4}},
+			priorityFee: 7,
+			success:     true,
+			refund:      6,
+		},
+		{
+			// Failed transactions are charged the full fee
+			name:    "failed action",
+			actions: []Action{&testMeteredAction{&testAction{computeUnits: 10, err: errTestAction}, 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			const price = 2
+			tx := newTestTx(consts.MaxUint64, tt.actions...)
+			tx.Base.PriorityFee = tt.priorityFee
+			feeManager := newTestFeeManager(price)
+			units, err := tx.Units(&testStateManager{}, &testRules{})
+			require.NoError(err)
+			maxFee, err := tx.fee(feeManager, units)
+			require.NoError(err)
+
+			tsv := newTestView(t, tx)
+			require.NoError(tx.PreExecute(ctx, feeManager, &testStateManager{}, &testRules{}, tsv, testTimestamp))
+			result, err := tx.Execute(ctx, feeManager, &testStateManager{}, &testRules{}, tsv, testTimestamp, false)
+			require.NoError(err)
+			require.Equal(tt.success, result.Success, string(result.Error))
+
+			units[fees.Compute] -= tt.refund
+			require.Equal(units, result.Units)
+			require.Equal(maxFee-tt.refund*price, result.Fee)
+			balance, err := getTestBalance(ctx, tsv, testSponsor)
+			require.NoError(err)
+			require.Equal(testBalance-result.Fee, balance)
+		})
+	}
+}
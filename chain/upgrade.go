@@ -0,0 +1,439 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+var (
+	_ RegistryRules = (*upgradedRules)(nil)
+	_ WarpRules     = (*upgradedWarpRules)(nil)
+)
+
+// Upgrade changes the [Rules] of a chain from [Timestamp] on, so that live
+// networks can be upgraded without swapping binaries. Fields that are not set
+// keep their previous value.
+type Upgrade struct {
+	Timestamp int64 `json:"timestamp"` // ms
+
+	MinBlockGap      *int64 `json:"minBlockGap,omitempty"`      // ms
+	MinEmptyBlockGap *int64 `json:"minEmptyBlockGap,omitempty"` // ms
+
+	MinUnitPrice               *fees.Dimensions `json:"minUnitPrice,omitempty"`
+	UnitPriceChangeDenominator *fees.Dimensions `json:"unitPriceChangeDenominator,omitempty"`
+	WindowTargetUnits          *fees.Dimensions `json:"windowTargetUnits,omitempty"`
+	MaxBlockUnits              *fees.Dimensions `json:"maxBlockUnits,omitempty"`
+
+	ValidityWindow      *int64 `json:"validityWindow,omitempty"` // ms
+	MaxActionsPerTx     *uint8 `json:"maxActionsPerTx,omitempty"`
+	MaxOutputsPerAction *uint8 `json:"maxOutputsPerAction,omitempty"`
+
+	BaseComputeUnits          *uint64 `json:"baseUnits,omitempty"`
+	StorageKeyReadUnits       *uint64 `json:"storageKeyReadUnits,omitempty"`
+	StorageValueReadUnits     *uint64 `json:"storageValueReadUnits,omitempty"` // per chunk
+	StorageKeyAllocateUnits   *uint64 `json:"storageKeyAllocateUnits,omitempty"`
+	StorageValueAllocateUnits *uint64 `json:"storageValueAllocateUnits,omitempty"` // per chunk
+	StorageKeyWriteUnits      *uint64 `json:"storageKeyWriteUnits,omitempty"`
+	StorageValueWriteUnits    *uint64 `json:"storageValueWriteUnits,omitempty"` // per chunk
+
+	// EnableActions and DisableActions change which registered action types
+	// transactions may use. A type whose first change enables it is disabled
+	// until then, which allows shipping new actions ahead of their activation.
+	EnableActions  []uint8 `json:"enableActions,omitempty"`
+	DisableActions []uint8 `json:"disableActions,omitempty"`
+
+	// EnableAuths and DisableAuths are the same for auth types
+	EnableAuths  []uint8 `json:"enableAuths,omitempty"`
+	DisableAuths []uint8 `json:"disableAuths,omitempty"`
+}
+
+// Upgrades are the upgrades of a chain, sorted by [Upgrade.Timestamp].
+type Upgrades []*Upgrade
+
+type upgradeConfig struct {
+	Upgrades Upgrades `json:"upgrades"`
+}
+
+// ParseUpgrades reads the upgrades in [upgradeBytes], which the VM receives
+// on startup. Other fields of [upgradeBytes] are ignored.
+func ParseUpgrades(upgradeBytes []byte) (Upgrades, error) {
+	if len(upgradeBytes) == 0 {
+		return nil, nil
+	}
+	var c upgradeConfig
+	if err := json.Unmarshal(upgradeBytes, &c); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidUpgrade, err)
+	}
+	return c.Upgrades, nil
+}
+
+// Verify checks that [u] is sorted, that its values are valid and that it only
+// changes registered types.
+func (u Upgrades) Verify(
+	actionRegistry *codec.TypeParser[Action, bool],
+	authRegistry *codec.TypeParser[Auth, bool],
+) error {
+	var last int64
+	for i, upgrade := range u {
+		if upgrade.Timestamp <= last {
+			return fmt.Errorf("%w: upgrade %d must activate after %d", ErrInvalidUpgrade, i, last)
+		}
+		last = upgrade.Timestamp
+		if err := upgrade.verify(actionRegistry, authRegistry); err != nil {
+			return fmt.Errorf("%w: upgrade %d: %w", ErrInvalidUpgrade, i, err)
+		}
+	}
+	return nil
+}
+
+func (u *Upgrade) verify(
+	actionRegistry *codec.TypeParser[Action, bool],
+	authRegistry *codec.TypeParser[Auth, bool],
+) error {
+	switch {
+	case u.MinBlockGap != nil && *u.MinBlockGap < 0:
+		return fmt.Errorf("minBlockGap=%d is negative", *u.MinBlockGap)
+	case u.MinEmptyBlockGap != nil && *u.MinEmptyBlockGap < 0:
+		return fmt.Errorf("minEmptyBlockGap=%d is negative", *u.MinEmptyBlockGap)
+	case u.ValidityWindow != nil && *u.ValidityWindow <= 0:
+		return fmt.Errorf("validityWindow=%d must be positive", *u.ValidityWindow)
+	case u.MaxActionsPerTx != nil && *u.MaxActionsPerTx == 0:
+		return fmt.Errorf("maxActionsPerTx must be positive")
+	case u.MaxOutputsPerAction != nil && *u.MaxOutputsPerAction == 0:
+		return fmt.Errorf("maxOutputsPerAction must be positive")
+	}
+	if u.UnitPriceChangeDenominator != nil {
+		for i, d := range u.UnitPriceChangeDenominator {
+			if d == 0 {
+				return fmt.Errorf("unitPriceChangeDenominator of dimension %d must be positive", i)
+			}
+		}
+	}
+	if err := verifyTypes(u.EnableActions, u.DisableActions, func(id uint8) bool {
+		_, ok := actionRegistry.LookupIndex(id)
+		return ok
+	}); err != nil {
+		return fmt.Errorf("action %w", err)
+	}
+	if err := verifyTypes(u.EnableAuths, u.DisableAuths, func(id uint8) bool {
+		_, ok := authRegistry.LookupIndex(id)
+		return ok
+	}); err != nil {
+		return fmt.Errorf("auth %w", err)
+	}
+	return nil
+}
+
+func verifyTypes(enable []uint8, disable []uint8, registered func(uint8) bool) error {
+	enabled := set.Of(enable...)
+	for _, id := range enable {
+		if !registered(id) {
+			return fmt.Errorf("type %d is not registered", id)
+		}
+	}
+	for _, id := range disable {
+		if !registered(id) {
+			return fmt.Errorf("type %d is not registered", id)
+		}
+		if enabled.Contains(id) {
+			return fmt.Errorf("type %d is both enabled and disabled", id)
+		}
+	}
+	return nil
+}
+
+// VerifyApplied checks that [rules] applies each of [u] when it activates, to
+// catch controllers that ignore the upgrades.
+func (u Upgrades) VerifyApplied(rules func(int64) Rules) error {
+	for i, upgrade := range u {
+		r := rules(upgrade.Timestamp)
+		expected := u.Rules(r, upgrade.Timestamp)
+		if !sameRules(r, expected) {
+			return fmt.Errorf("%w: upgrade %d", ErrUpgradeNotApplied, i)
+		}
+		if len(upgrade.EnableActions)+len(upgrade.DisableActions)+len(upgrade.EnableAuths)+len(upgrade.DisableAuths) == 0 {
+			continue
+		}
+		registry, ok := r.(RegistryRules)
+		if !ok {
+			return fmt.Errorf("%w: upgrade %d changes types", ErrUpgradeNotApplied, i)
+		}
+		expectedRegistry := expected.(RegistryRules)
+		for _, id := range append(upgrade.EnableActions, upgrade.DisableActions...) {
+			if registry.ActionEnabled(id) != expectedRegistry.ActionEnabled(id) {
+				return fmt.Errorf("%w: upgrade %d action type %d", ErrUpgradeNotApplied, i, id)
+			}
+		}
+		for _, id := range append(upgrade.EnableAuths, upgrade.DisableAuths...) {
+			if registry.AuthEnabled(id) != expectedRegistry.AuthEnabled(id) {
+				return fmt.Errorf("%w: upgrade %d auth type %d", ErrUpgradeNotApplied, i, id)
+			}
+		}
+	}
+	return nil
+}
+
+func sameRules(a Rules, b Rules) bool {
+	return a.GetMinBlockGap() == b.GetMinBlockGap() &&
+		a.GetMinEmptyBlockGap() == b.GetMinEmptyBlockGap() &&
+		a.GetValidityWindow() == b.GetValidityWindow() &&
+		a.GetMaxActionsPerTx() == b.GetMaxActionsPerTx() &&
+		a.GetMaxOutputsPerAction() == b.GetMaxOutputsPerAction() &&
+		a.GetMinUnitPrice() == b.GetMinUnitPrice() &&
+		a.GetUnitPriceChangeDenominator() == b.GetUnitPriceChangeDenominator() &&
+		a.GetWindowTargetUnits() == b.GetWindowTargetUnits() &&
+		a.GetMaxBlockUnits() == b.GetMaxBlockUnits() &&
+		a.GetBaseComputeUnits() == b.GetBaseComputeUnits() &&
+		a.GetStorageKeyReadUnits() == b.GetStorageKeyReadUnits() &&
+		a.GetStorageValueReadUnits() == b.GetStorageValueReadUnits() &&
+		a.GetStorageKeyAllocateUnits() == b.GetStorageKeyAllocateUnits() &&
+		a.GetStorageValueAllocateUnits() == b.GetStorageValueAllocateUnits() &&
+		a.GetStorageKeyWriteUnits() == b.GetStorageKeyWriteUnits() &&
+		a.GetStorageValueWriteUnits() == b.GetStorageValueWriteUnits()
+}
+
+// Rules returns [r] with the upgrades activated at [t] applied. [r] is
+// returned as is if there are no upgrades.
+func (u Upgrades) Rules(r Rules, t int64) Rules {
+	if len(u) == 0 {
+		return r
+	}
+	ur := &upgradedRules{
+		Rules:           r,
+		disabledActions: set.Set[uint8]{},
+		disabledAuths:   set.Set[uint8]{},
+	}
+
+	// Types enabled by their first change start disabled
+	var changedActions, changedAuths set.Set[uint8]
+	for _, upgrade := range u {
+		for _, id := range upgrade.EnableActions {
+			if !changedActions.Contains(id) {
+				ur.disabledActions.Add(id)
+			}
+		}
+		changedActions.Add(upgrade.EnableActions...)
+		changedActions.Add(upgrade.DisableActions...)
+		for _, id := range upgrade.EnableAuths {
+			if !changedAuths.Contains(id) {
+				ur.disabledAuths.Add(id)
+			}
+		}
+		changedAuths.Add(upgrade.EnableAuths...)
+		changedAuths.Add(upgrade.DisableAuths...)
+	}
+
+	for _, upgrade := range u {
+		if upgrade.Timestamp > t {
+			break
+		}
+		ur.apply(upgrade)
+	}
+	if warpRules, ok := r.(WarpRules); ok {
+		return &upgradedWarpRules{ur, warpRules}
+	}
+	return ur
+}
+
+// RulesCache holds the [Rules] in effect after each upgrade, so that
+// controllers looking up the rules of every block and transaction don't
+// resolve the upgrades each time.
+type RulesCache struct {
+	timestamps []int64
+	rules      []Rules // [rules][i] is in effect after i upgrades
+}
+
+// Cache resolves [r] once per upgrade in [u].
+func (u Upgrades) Cache(r Rules) *RulesCache {
+	c := &RulesCache{
+		timestamps: make([]int64, len(u)),
+		rules:      make([]Rules, len(u)+1),
+	}
+	c.rules[0] = u.Rules(r, math.MinInt64)
+	for i, upgrade := range u {
+		c.timestamps[i] = upgrade.Timestamp
+		c.rules[i+1] = u.Rules(r, upgrade.Timestamp)
+	}
+	return c
+}
+
+// Rules returns the rules at [t].
+func (c *RulesCache) Rules(t int64) Rules {
+	activated := sort.Search(len(c.timestamps), func(i int) bool {
+		return c.timestamps[i] > t
+	})
+	return c.rules[activated]
+}
+
+// upgradedRules overrides the [Rules] changed by upgrades.
+type upgradedRules struct {
+	Rules
+
+	minBlockGap      *int64
+	minEmptyBlockGap *int64
+
+	minUnitPrice               *fees.Dimensions
+	unitPriceChangeDenominator *fees.Dimensions
+	windowTargetUnits          *fees.Dimensions
+	maxBlockUnits              *fees.Dimensions
+
+	validityWindow      *int64
+	maxActionsPerTx     *uint8
+	maxOutputsPerAction *uint8
+
+	baseComputeUnits          *uint64
+	storageKeyReadUnits       *uint64
+	storageValueReadUnits     *uint64
+	storageKeyAllocateUnits   *uint64
+	storageValueAllocateUnits *uint64
+	storageKeyWriteUnits      *uint64
+	storageValueWriteUnits    *uint64
+
+	disabledActions set.Set[uint8]
+	disabledAuths   set.Set[uint8]
+}
+
+func (r *upgradedRules) apply(u *Upgrade) {
+	r.minBlockGap = override(r.minBlockGap, u.MinBlockGap)
+	r.minEmptyBlockGap = override(r.minEmptyBlockGap, u.MinEmptyBlockGap)
+	r.minUnitPrice = override(r.minUnitPrice, u.MinUnitPrice)
+	r.unitPriceChangeDenominator = override(r.unitPriceChangeDenominator, u.UnitPriceChangeDenominator)
+	r.windowTargetUnits = override(r.windowTargetUnits, u.WindowTargetUnits)
+	r.maxBlockUnits = override(r.maxBlockUnits, u.MaxBlockUnits)
+	r.validityWindow = override(r.validityWindow, u.ValidityWindow)
+	r.maxActionsPerTx = override(r.maxActionsPerTx, u.MaxActionsPerTx)
+	r.maxOutputsPerAction = override(r.maxOutputsPerAction, u.MaxOutputsPerAction)
+	r.baseComputeUnits = override(r.baseComputeUnits, u.BaseComputeUnits)
+	r.storageKeyReadUnits = override(r.storageKeyReadUnits, u.StorageKeyReadUnits)
+	r.storageValueReadUnits = override(r.storageValueReadUnits, u.StorageValueReadUnits)
+	r.storageKeyAllocateUnits = override(r.storageKeyAllocateUnits, u.StorageKeyAllocateUnits)
+	r.storageValueAllocateUnits = override(r.storageValueAllocateUnits, u.StorageValueAllocateUnits)
+	r.storageKeyWriteUnits = override(r.storageKeyWriteUnits, u.StorageKeyWriteUnits)
+	r.storageValueWriteUnits = override(r.storageValueWriteUnits, u.StorageValueWriteUnits)
+
+	for _, id := range u.EnableActions {
+		r.disabledActions.Remove(id)
+	}
+	r.disabledActions.Add(u.DisableActions...)
+	for _, id := range u.EnableAuths {
+		r.disabledAuths.Remove(id)
+	}
+	r.disabledAuths.Add(u.DisableAuths...)
+}
+
+func override[T any](current *T, upgraded *T) *T {
+	if upgraded != nil {
+		return upgraded
+	}
+	return current
+}
+
+func valueOr[T any](v *T, fallback func() T) T {
+	if v != nil {
+		return *v
+	}
+	return fallback()
+}
+
+func (r *upgradedRules) GetMinBlockGap() int64 {
+	return valueOr(r.minBlockGap, r.Rules.GetMinBlockGap)
+}
+
+func (r *upgradedRules) GetMinEmptyBlockGap() int64 {
+	return valueOr(r.minEmptyBlockGap, r.Rules.GetMinEmptyBlockGap)
+}
+
+func (r *upgradedRules) GetValidityWindow() int64 {
+	return valueOr(r.validityWindow, r.Rules.GetValidityWindow)
+}
+
+func (r *upgradedRules) GetMaxActionsPerTx() uint8 {
+	return valueOr(r.maxActionsPerTx, r.Rules.GetMaxActionsPerTx)
+}
+
+func (r *upgradedRules) GetMaxOutputsPerAction() uint8 {
+	return valueOr(r.maxOutputsPerAction, r.Rules.GetMaxOutputsPerAction)
+}
+
+func (r *upgradedRules) GetMinUnitPrice() fees.Dimensions {
+	return valueOr(r.minUnitPrice, r.Rules.GetMinUnitPrice)
+}
+
+func (r *upgradedRules) GetUnitPriceChangeDenominator() fees.Dimensions {
+	return valueOr(r.unitPriceChangeDenominator, r.Rules.GetUnitPriceChangeDenominator)
+}
+
+func (r *upgradedRules) GetWindowTargetUnits() fees.Dimensions {
+	return valueOr(r.windowTargetUnits, r.Rules.GetWindowTargetUnits)
+}
+
+func (r *upgradedRules) GetMaxBlockUnits() fees.Dimensions {
+	return valueOr(r.maxBlockUnits, r.Rules.GetMaxBlockUnits)
+}
+
+func (r *upgradedRules) GetBaseComputeUnits() uint64 {
+	return valueOr(r.baseComputeUnits, r.Rules.GetBaseComputeUnits)
+}
+
+func (r *upgradedRules) GetStorageKeyReadUnits() uint64 {
+	return valueOr(r.storageKeyReadUnits, r.Rules.GetStorageKeyReadUnits)
+}
+
+func (r *upgradedRules) GetStorageValueReadUnits() uint64 {
+	return valueOr(r.storageValueReadUnits, r.Rules.GetStorageValueReadUnits)
+}
+
+func (r *upgradedRules) GetStorageKeyAllocateUnits() uint64 {
+	return valueOr(r.storageKeyAllocateUnits, r.Rules.GetStorageKeyAllocateUnits)
+}
+
+func (r *upgradedRules) GetStorageValueAllocateUnits() uint64 {
+	return valueOr(r.storageValueAllocateUnits, r.Rules.GetStorageValueAllocateUnits)
+}
+
+func (r *upgradedRules) GetStorageKeyWriteUnits() uint64 {
+	return valueOr(r.storageKeyWriteUnits, r.Rules.GetStorageKeyWriteUnits)
+}
+
+func (r *upgradedRules) GetStorageValueWriteUnits() uint64 {
+	return valueOr(r.storageValueWriteUnits, r.Rules.GetStorageValueWriteUnits)
+}
+
+func (r *upgradedRules) ActionEnabled(typeID uint8) bool {
+	return !r.disabledActions.Contains(typeID)
+}
+
+func (r *upgradedRules) AuthEnabled(typeID uint8) bool {
+	return !r.disabledAuths.Contains(typeID)
+}
+
+// upgradedWarpRules keeps the [WarpRules] of the upgraded [Rules], which
+// upgrades don't change.
+type upgradedWarpRules struct {
+	*upgradedRules
+
+	warp WarpRules
+}
+
+func (r *upgradedWarpRules) GetWarpConfig(sourceChainID ids.ID) (bool, uint64, uint64) {
+	return r.warp.GetWarpConfig(sourceChainID)
+}
+
+func (r *upgradedWarpRules) GetWarpBaseComputeUnits() uint64 {
+	return r.warp.GetWarpBaseComputeUnits()
+}
+
+func (r *upgradedWarpRules) GetWarpComputeUnitsPerSigner() uint64 {
+	return r.warp.GetWarpComputeUnitsPerSigner()
+}
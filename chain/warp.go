@@ -0,0 +1,219 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// WarpRules is an optional extension of [Rules] that enables Avalanche Warp
+// Messaging. If it is not implemented, transactions carrying warp messages
+// are dropped.
+type WarpRules interface {
+	// GetWarpConfig returns whether warp messages from [sourceChainID] are
+	// accepted and the share of the stake of the source subnet, as
+	// [num]/[denom], that must sign them.
+	GetWarpConfig(sourceChainID ids.ID) (allowed bool, num uint64, denom uint64)
+
+	// Verifying a warp message costs [GetWarpBaseComputeUnits] plus
+	// [GetWarpComputeUnitsPerSigner] for each signer
+	GetWarpBaseComputeUnits() uint64
+	GetWarpComputeUnitsPerSigner() uint64
+}
+
+// WarpAction is an optional interface for [Action]s that receive or send
+// Avalanche Warp messages.
+//
+// Actions must protect themselves from replays of an incoming message, for
+// instance by storing its [warp.UnsignedMessage.ID] in state.
+type WarpAction interface {
+	Action
+
+	// ExecuteWarp is called instead of [Execute]. [message] is the warp message
+	// of the transaction if its signature was verified, and nil otherwise.
+	//
+	// If [payload] is not nil, it is sent in a warp message from this chain
+	// once the transaction is accepted. Only one action of a transaction may
+	// send a message.
+	ExecuteWarp(
+		ctx context.Context,
+		r Rules,
+		mu state.Mutable,
+		timestamp int64,
+		actor codec.Address,
+		actionID ids.ID,
+		message *warp.Message,
+	) (outputs [][]byte, payload []byte, err error)
+}
+
+// WarpSignature is the signature of a warp message by the validator with
+// [PublicKey] (compressed).
+type WarpSignature struct {
+	PublicKey []byte `json:"publicKey"`
+	Signature []byte `json:"signature"`
+}
+
+// preVerifyWarpMessage performs the checks of the warp message of [t] that do
+// not depend on the validator set, and returns the quorum it requires.
+func (t *Transaction) preVerifyWarpMessage(r Rules) (uint64, uint64, error) {
+	warpRules, ok := r.(WarpRules)
+	if !ok {
+		return 0, 0, ErrWarpMessageNotAllowed
+	}
+	msg := t.WarpMessage
+	if msg.NetworkID != r.NetworkID() {
+		return 0, 0, fmt.Errorf("%w: invalid network ID %d", ErrWarpMessageNotAllowed, msg.NetworkID)
+	}
+	if msg.SourceChainID == r.ChainID() {
+		return 0, 0, fmt.Errorf("%w: message sent by this chain", ErrWarpMessageNotAllowed)
+	}
+	allowed, num, denom := warpRules.GetWarpConfig(msg.SourceChainID)
+	if !allowed {
+		return 0, 0, fmt.Errorf("%w: source chain %s", ErrWarpMessageNotAllowed, msg.SourceChainID)
+	}
+	return num, denom, nil
+}
+
+// VerifyWarpMessage verifies that the warp message of [t] was signed by
+// enough of the validators of its source subnet at [pChainHeight]. It returns
+// nil if [t] does not carry a warp message.
+func (t *Transaction) VerifyWarpMessage(
+	ctx context.Context,
+	r Rules,
+	vs validators.State,
+	pChainHeight uint64,
+) error {
+	if t.WarpMessage == nil {
+		return nil
+	}
+	num, denom, err := t.preVerifyWarpMessage(r)
+	if err != nil {
+		return err
+	}
+	msg := t.WarpMessage
+	if err := msg.Signature.Verify(ctx, &msg.UnsignedMessage, r.NetworkID(), vs, pChainHeight, num, denom); err != nil {
+		return fmt.Errorf("%w: %w", ErrWarpMessageNotVerified, err)
+	}
+	return nil
+}
+
+// AggregateWarpSignatures aggregates [signatures] of [msg] by validators in
+// [vdrs] into a signed warp message. [vdrs] must be the canonical validator
+// set of the source subnet (see [warp.GetCanonicalValidatorSet]), and
+// signatures by other keys are ignored. It returns the weight of the signers.
+func AggregateWarpSignatures(
+	msg *warp.UnsignedMessage,
+	vdrs []*warp.Validator,
+	signatures []*WarpSignature,
+) (*warp.Message, uint64, error) {
+	indices := make(map[string]int, len(vdrs))
+	for i, vdr := range vdrs {
+		indices[string(bls.PublicKeyToCompressedBytes(vdr.PublicKey))] = i
+	}
+	var (
+		signers = set.NewBits()
+		sigs    = make([]*bls.Signature, 0, len(signatures))
+		weight  uint64
+	)
+	for _, signature := range signatures {
+		i, ok := indices[string(signature.PublicKey)]
+		if !ok || signers.Contains(i) {
+			continue
+		}
+		sig, err := bls.SignatureFromBytes(signature.Signature)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !bls.Verify(vdrs[i].PublicKey, sig, msg.Bytes()) {
+			return nil, 0, fmt.Errorf("%w: signer %d", ErrInvalidSignature, i)
+		}
+		signers.Add(i)
+		sigs = append(sigs, sig)
+		weight += vdrs[i].Weight // can't overflow, the total weight of [vdrs] doesn't
+	}
+	if len(sigs) == 0 {
+		return nil, 0, ErrNoWarpSignatures
+	}
+	aggSig, err := bls.AggregateSignatures(sigs)
+	if err != nil {
+		return nil, 0, err
+	}
+	signature := &warp.BitSetSignature{Signers: signers.Bytes()}
+	copy(signature.Signature[:], bls.SignatureToBytes(aggSig))
+	signed, err := warp.NewMessage(msg, signature)
+	if err != nil {
+		return nil, 0, err
+	}
+	return signed, weight, nil
+}
+
+// outgoingWarpMessage wraps [payload] in a warp message from this chain. Only
+// one message can be sent by a transaction, so it fails if [outgoing] is
+// already set.
+func outgoingWarpMessage(
+	r Rules,
+	outgoing *warp.UnsignedMessage,
+	payload []byte,
+) (*warp.UnsignedMessage, error) {
+	if outgoing != nil {
+		return nil, ErrTooManyWarpMessages
+	}
+	msg, err := warp.NewUnsignedMessage(r.NetworkID(), r.ChainID(), payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.Bytes()) > MaxWarpMessageSize {
+		return nil, ErrWarpMessageTooLarge
+	}
+	return msg, nil
+}
+
+func packWarpMessage(p *codec.Packer, msg *warp.Message) {
+	if msg == nil {
+		p.PackBytes(nil)
+		return
+	}
+	p.PackBytes(msg.Bytes())
+}
+
+func unpackWarpMessage(p *codec.Packer) (*warp.Message, error) {
+	var msgBytes []byte
+	p.UnpackBytes(MaxWarpMessageSize, false, &msgBytes)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if len(msgBytes) == 0 {
+		return nil, nil
+	}
+	return warp.ParseMessage(msgBytes)
+}
+
+func unsignedWarpMessageBytes(msg *warp.UnsignedMessage) []byte {
+	if msg == nil {
+		return nil
+	}
+	return msg.Bytes()
+}
+
+func unpackUnsignedWarpMessage(p *codec.Packer) (*warp.UnsignedMessage, error) {
+	var msgBytes []byte
+	p.UnpackBytes(MaxWarpMessageSize, false, &msgBytes)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if len(msgBytes) == 0 {
+		return nil, nil
+	}
+	return warp.ParseUnsignedMessage(msgBytes)
+}
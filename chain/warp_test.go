@@ -0,0 +1,227 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+const (
+	testNetworkID    = uint32(1337)
+	testPChainHeight = uint64(10)
+)
+
+type testWarpRules struct {
+	*MockRules
+
+	sourceChainID ids.ID
+}
+
+func (r *testWarpRules) GetWarpConfig(sourceChainID ids.ID) (bool, uint64, uint64) {
+	return sourceChainID == r.sourceChainID, 67, 100
+}
+
+func (*testWarpRules) GetWarpBaseComputeUnits() uint64 {
+	return 1024
+}
+
+func (*testWarpRules) GetWarpComputeUnitsPerSigner() uint64 {
+	return 128
+}
+
+type testSubnet struct {
+	sks   []*bls.SecretKey
+	state *validators.TestState
+}
+
+// newTestSubnet creates a source subnet of validators with equal weight.
+func newTestSubnet(t *testing.T, size int) *testSubnet {
+	require := require.New(t)
+
+	subnetID := ids.GenerateTestID()
+	sks := make([]*bls.SecretKey, size)
+	vdrs := make(map[ids.NodeID]*validators.GetValidatorOutput, size)
+	for i := range sks {
+		sk, err := bls.NewSecretKey()
+		require.NoError(err)
+		sks[i] = sk
+		nodeID := ids.GenerateTestNodeID()
+		vdrs[nodeID] = &validators.GetValidatorOutput{
+			NodeID:    nodeID,
+			PublicKey: bls.PublicFromSecretKey(sk),
+			Weight:    100,
+		}
+	}
+	return &testSubnet{
+		sks: sks,
+		state: &validators.TestState{
+			GetSubnetIDF: func(context.Context, ids.ID) (ids.ID, error) {
+				return subnetID, nil
+			},
+			GetValidatorSetF: func(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+				return vdrs, nil
+			},
+		},
+	}
+}
+
+// sign returns [msg] signed by the first [signers] validators of the subnet.
+func (s *testSubnet) sign(t *testing.T, msg *warp.UnsignedMessage, signers int) *warp.Message {
+	require := require.New(t)
+
+	vdrs, _, err := warp.GetCanonicalValidatorSet(context.Background(), s.state, testPChainHeight, ids.Empty)
+	require.NoError(err)
+	signatures := make([]*WarpSignature, 0, signers)
+	for _, sk := range s.sks[:signers] {
+		signatures = append(signatures, &WarpSignature{
+			PublicKey: bls.PublicKeyToCompressedBytes(bls.PublicFromSecretKey(sk)),
+			Signature: bls.SignatureToBytes(bls.Sign(sk, msg.Bytes())),
+		})
+	}
+	signed, weight, err := AggregateWarpSignatures(msg, vdrs, signatures)
+	require.NoError(err)
+	require.Equal(uint64(signers*100), weight)
+	return signed
+}
+
+func TestVerifyWarpMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	chainID := ids.GenerateTestID()
+	sourceChainID := ids.GenerateTestID()
+	rules := &testWarpRules{
+		MockRules:     NewMockRules(ctrl),
+		sourceChainID: sourceChainID,
+	}
+	rules.EXPECT().NetworkID().Return(testNetworkID).AnyTimes()
+	rules.EXPECT().ChainID().Return(chainID).AnyTimes()
+	subnet := newTestSubnet(t, 4)
+
+	newMessage := func(networkID uint32, sourceChainID ids.ID) *warp.UnsignedMessage {
+		msg, err := warp.NewUnsignedMessage(networkID, sourceChainID, []byte("payload"))
+		require.NoError(t, err)
+		return msg
+	}
+	tests := []struct {
+		name    string
+		msg     *warp.UnsignedMessage
+		signers int
+		err     error
+	}{
+		{
+			name:    "all signers",
+			msg:     newMessage(testNetworkID, sourceChainID),
+			signers: 4,
+		},
+		{
+			name:    "enough signers",
+			msg:     newMessage(testNetworkID, sourceChainID),
+			signers: 3,
+		},
+		{
+			name:    "insufficient weight",
+			msg:     newMessage(testNetworkID, sourceChainID),
+			signers: 2,
+			err:     ErrWarpMessageNotVerified,
+		},
+		{
+			name:    "wrong network",
+			msg:     newMessage(testNetworkID+1, sourceChainID),
+			signers: 4,
+			err:     ErrWarpMessageNotAllowed,
+		},
+		{
+			name:    "source not allowed",
+			msg:     newMessage(testNetworkID, ids.GenerateTestID()),
+			signers: 4,
+			err:     ErrWarpMessageNotAllowed,
+		},
+		{
+			name:    "sent by this chain",
+			msg:     newMessage(testNetworkID, chainID),
+			signers: 4,
+			err:     ErrWarpMessageNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &Transaction{WarpMessage: subnet.sign(t, tt.msg, tt.signers)}
+			err := tx.VerifyWarpMessage(context.Background(), rules, subnet.state, testPChainHeight)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	// Transactions without a message are always valid
+	tx := &Transaction{}
+	require.NoError(t, tx.VerifyWarpMessage(context.Background(), rules, subnet.state, testPChainHeight))
+}
+
+func TestPackWarpMessage(t *testing.T) {
+	require := require.New(t)
+
+	msg, err := warp.NewUnsignedMessage(testNetworkID, ids.GenerateTestID(), []byte("payload"))
+	require.NoError(err)
+	subnet := newTestSubnet(t, 3)
+	signed := subnet.sign(t, msg, 2)
+	numSigners, err := signed.Signature.NumSigners()
+	require.NoError(err)
+	require.Equal(2, numSigners)
+
+	// Transactions without a message pack empty bytes
+	p := codec.NewWriter(0, consts.MaxInt)
+	packWarpMessage(p, signed)
+	packWarpMessage(p, nil)
+	require.NoError(p.Err())
+	r := codec.NewReader(p.Bytes(), consts.MaxInt)
+	unpacked, err := unpackWarpMessage(r)
+	require.NoError(err)
+	require.Equal(signed.Bytes(), unpacked.Bytes())
+	unpacked, err = unpackWarpMessage(r)
+	require.NoError(err)
+	require.Nil(unpacked)
+	require.True(r.Empty())
+}
+
+func TestOutgoingWarpMessage(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	chainID := ids.GenerateTestID()
+	rules := NewMockRules(ctrl)
+	rules.EXPECT().NetworkID().Return(testNetworkID).AnyTimes()
+	rules.EXPECT().ChainID().Return(chainID).AnyTimes()
+
+	msg, err := outgoingWarpMessage(rules, nil, []byte("payload"))
+	require.NoError(err)
+	require.Equal(testNetworkID, msg.NetworkID)
+	require.Equal(chainID, msg.SourceChainID)
+
+	_, err = outgoingWarpMessage(rules, msg, []byte("payload"))
+	require.ErrorIs(err, ErrTooManyWarpMessages)
+
+	_, err = outgoingWarpMessage(rules, nil, make([]byte, MaxWarpMessageSize))
+	require.ErrorIs(err, ErrWarpMessageTooLarge)
+
+	// Results carry the message to the acceptor
+	result := &Result{Success: true, Outputs: [][][]byte{{}}, WarpMessage: msg}
+	p := codec.NewWriter(result.Size(), consts.MaxInt)
+	require.NoError(result.Marshal(p))
+	require.Len(p.Bytes(), result.Size())
+	unmarshaled, err := UnmarshalResult(codec.NewReader(p.Bytes(), consts.MaxInt))
+	require.NoError(err)
+	require.Equal(msg.Bytes(), unmarshaled.WarpMessage.Bytes())
+}
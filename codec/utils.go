@@ -25,6 +25,8 @@ func BytesLenSize(msgSize int) int {
 	return consts.IntLen + msgSize
 }
 
+// StringLen is the size of [msg] as packed by [Packer.PackString], which
+// prefixes it with a uint16 length.
 func StringLen(msg string) int {
-	return consts.IntLen + len(msg)
+	return consts.Uint16Len + len(msg)
 }
@@ -352,8 +352,8 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 	// read: 2 keys reads
 	// allocate: 1 key created with 1 chunk
 	// write: 2 keys modified
-	transferTxUnits := fees.Dimensions{224, 7, 14, 50, 26}
-	transferTxFee := uint64(321)
+	transferTxUnits := fees.Dimensions{236, 7, 14, 50, 26}
+	transferTxFee := uint64(333)
 
 	ginkgo.It("get currently accepted block ID", func() {
 		for _, inst := range instances {
@@ -467,7 +467,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 		ginkgo.By("ensure balance is updated", func() {
 			balance, err := instances[1].tcli.Balance(context.Background(), sender, ids.Empty)
 			require.NoError(err)
-			require.Equal(balance, uint64(9_899_679))
+			require.Equal(balance, uint64(9_899_667))
 			balance2, err := instances[1].tcli.Balance(context.Background(), sender2, ids.Empty)
 			require.NoError(err)
 			require.Equal(balance2, uint64(100_000))
@@ -0,0 +1,329 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package abi describes the functions a contract exposes and how their
+// payloads and results are encoded, so that clients can call contracts
+// without knowing their source.
+//
+// Values are packed one after the other, like actions are: integers are big
+// endian, bools take one byte, addresses are raw, strings are prefixed with
+// their uint16 length and bytes with their uint32 length.
+package abi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+)
+
+// Type of an argument or return value.
+type Type string
+
+const (
+	U8      Type = "u8"
+	U16     Type = "u16"
+	U32     Type = "u32"
+	U64     Type = "u64"
+	I64     Type = "i64"
+	Bool    Type = "bool"
+	Address Type = "address"
+	String  Type = "string"
+	Bytes   Type = "bytes"
+)
+
+// ExportFunction is the function contracts built with the js_sdk return their
+// ABI from, as JSON. It is reserved and never listed in the ABI.
+const ExportFunction = "__abi"
+
+// MaxNameLen bounds the length of function and parameter names.
+const MaxNameLen = 64
+
+type Param struct {
+	Name string `json:"name"`
+	Type Type   `json:"type"`
+}
+
+type Function struct {
+	Name    string  `json:"name"`
+	Args    []Param `json:"args,omitempty"`
+	Returns []Param `json:"returns,omitempty"`
+
+	// ReadOnly functions are views: the runtime fails their writes, transfers
+	// and events, so clients query them rather than send a transaction.
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// ABI lists the functions of a contract. It is stored as JSON next to the
+// bytecode of the contract.
+type ABI struct {
+	Functions []*Function `json:"functions"`
+}
+
+// Parse decodes and verifies the JSON encoding of an ABI.
+func Parse(b []byte) (*ABI, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var a ABI
+	if err := dec.Decode(&a); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidABI, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidABI, ErrTrailingBytes)
+	}
+	if err := a.Verify(); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Verify checks that functions are named uniquely and only use known types.
+func (a *ABI) Verify() error {
+	names := make(map[string]struct{}, len(a.Functions))
+	for _, f := range a.Functions {
+		if f == nil || len(f.Name) == 0 || len(f.Name) > MaxNameLen {
+			return fmt.Errorf("%w: function names must be between 1 and %d bytes", ErrInvalidABI, MaxNameLen)
+		}
+		if f.Name == ExportFunction {
+			return fmt.Errorf("%w: %s is reserved", ErrInvalidABI, ExportFunction)
+		}
+		if _, ok := names[f.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateFunction, f.Name)
+		}
+		names[f.Name] = struct{}{}
+		if err := verifyParams(f.Args); err != nil {
+			return fmt.Errorf("%w: arguments of %s: %w", ErrInvalidABI, f.Name, err)
+		}
+		if err := verifyParams(f.Returns); err != nil {
+			return fmt.Errorf("%w: returns of %s: %w", ErrInvalidABI, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func verifyParams(params []Param) error {
+	for _, param := range params {
+		if len(param.Name) > MaxNameLen {
+			return fmt.Errorf("name of %s is longer than %d bytes", param.Name, MaxNameLen)
+		}
+		if !param.Type.Valid() {
+			return fmt.Errorf("%w: %s", ErrUnknownType, param.Type)
+		}
+	}
+	return nil
+}
+
+// Function returns the function called [name].
+func (a *ABI) Function(name string) (*Function, error) {
+	for _, f := range a.Functions {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFunctionNotFound, name)
+}
+
+// EncodeArgs packs [values] into the payload of a call to [f].
+func (f *Function) EncodeArgs(values []any) ([]byte, error) {
+	return Encode(f.Args, values)
+}
+
+// ParseArgs packs the textual [args] into the payload of a call to [f], see
+// [ParseValue].
+func (f *Function) ParseArgs(args []string) ([]byte, error) {
+	if len(args) != len(f.Args) {
+		return nil, fmt.Errorf("%w: %s takes %d arguments, got %d", ErrWrongValueCount, f.Name, len(f.Args), len(args))
+	}
+	values := make([]any, len(args))
+	for i, arg := range args {
+		v, err := ParseValue(f.Args[i].Type, arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", f.Args[i].Name, err)
+		}
+		values[i] = v
+	}
+	return Encode(f.Args, values)
+}
+
+// DecodeReturns unpacks the result of a call to [f].
+func (f *Function) DecodeReturns(b []byte) ([]any, error) {
+	return Decode(f.Returns, b)
+}
+
+// Valid reports whether [t] is a known type.
+func (t Type) Valid() bool {
+	switch t {
+	case U8, U16, U32, U64, I64, Bool, Address, String, Bytes:
+		return true
+	default:
+		return false
+	}
+}
+
+// Encode packs [values], which must have the Go type matching their
+// parameter: uint8, uint16, uint32, uint64, int64, bool, [codec.Address],
+// string or []byte.
+func Encode(params []Param, values []any) ([]byte, error) {
+	if len(values) != len(params) {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrWrongValueCount, len(params), len(values))
+	}
+	p := codec.NewWriter(0, consts.MaxInt)
+	for i, param := range params {
+		ok := true
+		switch param.Type {
+		case U8:
+			var v uint8
+			v, ok = values[i].(uint8)
+			p.PackByte(v)
+		case U16:
+			var v uint16
+			v, ok = values[i].(uint16)
+			p.PackFixedBytes(binary.BigEndian.AppendUint16(nil, v))
+		case U32:
+			var v uint32
+			v, ok = values[i].(uint32)
+			p.PackFixedBytes(binary.BigEndian.AppendUint32(nil, v))
+		case U64:
+			var v uint64
+			v, ok = values[i].(uint64)
+			p.PackUint64(v)
+		case I64:
+			var v int64
+			v, ok = values[i].(int64)
+			p.PackInt64(v)
+		case Bool:
+			var v bool
+			v, ok = values[i].(bool)
+			p.PackBool(v)
+		case Address:
+			var v codec.Address
+			v, ok = values[i].(codec.Address)
+			p.PackAddress(v)
+		case String:
+			var v string
+			v, ok = values[i].(string)
+			p.PackString(v)
+		case Bytes:
+			var v []byte
+			v, ok = values[i].([]byte)
+			p.PackBytes(v)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownType, param.Type)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a %s, got %T", ErrInvalidValue, param.Name, param.Type, values[i])
+		}
+	}
+	return p.Bytes(), p.Err()
+}
+
+// Decode unpacks values encoded by [Encode].
+func Decode(params []Param, b []byte) ([]any, error) {
+	p := codec.NewReader(b, consts.MaxInt)
+	values := make([]any, len(params))
+	for i, param := range params {
+		switch param.Type {
+		case U8:
+			values[i] = p.UnpackByte()
+		case U16:
+			v := make([]byte, consts.Uint16Len)
+			p.UnpackFixedBytes(consts.Uint16Len, &v)
+			values[i] = binary.BigEndian.Uint16(v)
+		case U32:
+			v := make([]byte, consts.Uint32Len)
+			p.UnpackFixedBytes(consts.Uint32Len, &v)
+			values[i] = binary.BigEndian.Uint32(v)
+		case U64:
+			values[i] = p.UnpackUint64(false)
+		case I64:
+			values[i] = p.UnpackInt64(false)
+		case Bool:
+			values[i] = p.UnpackBool()
+		case Address:
+			// [codec.Packer.UnpackAddress] rejects the empty address
+			v := make([]byte, codec.AddressLen)
+			p.UnpackFixedBytes(codec.AddressLen, &v)
+			values[i] = codec.Address(v)
+		case String:
+			values[i] = p.UnpackString(false)
+		case Bytes:
+			var v []byte
+			p.UnpackBytes(-1, false, &v)
+			values[i] = v
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownType, param.Type)
+		}
+	}
+	if err := p.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidValue, err)
+	}
+	if !p.Empty() {
+		return nil, ErrTrailingBytes
+	}
+	return values, nil
+}
+
+// ParseValue reads a value of type [t] from its textual form: integers in
+// decimal, bools as true or false, addresses in bech32 and bytes in hex.
+// Strings are taken as is.
+func ParseValue(t Type, s string) (any, error) {
+	var (
+		v   any
+		err error
+	)
+	switch t {
+	case U8:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, 8)
+		v = uint8(n)
+	case U16:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, 16)
+		v = uint16(n)
+	case U32:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, 32)
+		v = uint32(n)
+	case U64:
+		v, err = strconv.ParseUint(s, 10, 64)
+	case I64:
+		v, err = strconv.ParseInt(s, 10, 64)
+	case Bool:
+		v, err = strconv.ParseBool(s)
+	case Address:
+		v, err = codec.ParseAddressBech32(mconsts.HRP, s)
+	case String:
+		v = s
+	case Bytes:
+		v, err = hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a %s: %w", ErrInvalidValue, s, t, err)
+	}
+	return v, nil
+}
+
+// FormatValue is the inverse of [ParseValue].
+func FormatValue(t Type, v any) string {
+	switch t {
+	case Address:
+		if addr, ok := v.(codec.Address); ok {
+			return codec.MustAddressBech32(mconsts.HRP, addr)
+		}
+	case Bytes:
+		if b, ok := v.([]byte); ok {
+			return "0x" + hex.EncodeToString(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package abi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		abi  string
+		err  error
+	}{
+		{
+			name: "valid",
+			abi:  `{"functions":[{"name":"transfer","args":[{"name":"to","type":"address"},{"name":"amount","type":"u64"}]},{"name":"balance","args":[{"name":"of","type":"address"}],"returns":[{"name":"amount","type":"u64"}],"readOnly":true}]}`,
+		},
+		{
+			name: "not json",
+			abi:  `transfer(address,u64)`,
+			err:  ErrInvalidABI,
+		},
+		{
+			name: "unknown field",
+			abi:  `{"functions":[{"name":"transfer","payable":true}]}`,
+			err:  ErrInvalidABI,
+		},
+		{
+			name: "trailing data",
+			abi:  `{"functions":[]}{}`,
+			err:  ErrInvalidABI,
+		},
+		{
+			name: "unknown type",
+			abi:  `{"functions":[{"name":"transfer","args":[{"name":"amount","type":"u128"}]}]}`,
+			err:  ErrUnknownType,
+		},
+		{
+			name: "unnamed function",
+			abi:  `{"functions":[{"args":[]}]}`,
+			err:  ErrInvalidABI,
+		},
+		{
+			name: "reserved function",
+			abi:  `{"functions":[{"name":"__abi"}]}`,
+			err:  ErrInvalidABI,
+		},
+		{
+			name: "duplicate function",
+			abi:  `{"functions":[{"name":"transfer"},{"name":"transfer"}]}`,
+			err:  ErrDuplicateFunction,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.abi))
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	params := []Param{
+		{Name: "a", Type: U8},
+		{Name: "b", Type: U16},
+		{Name: "c", Type: U32},
+		{Name: "d", Type: U64},
+		{Name: "e", Type: I64},
+		{Name: "f", Type: Bool},
+		{Name: "g", Type: Address},
+		{Name: "h", Type: String},
+		{Name: "i", Type: Bytes},
+	}
+	values := []any{
+		uint8(1),
+		uint16(2),
+		uint32(3),
+		uint64(4),
+		int64(-5),
+		true,
+		codec.Address{0x01, 0x02},
+		"six",
+		[]byte{7},
+	}
+	b, err := Encode(params, values)
+	require.NoError(err)
+	require.Equal([]byte{
+		1,
+		0, 2,
+		0, 0, 0, 3,
+		0, 0, 0, 0, 0, 0, 0, 4,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfb,
+		1,
+		0x01, 0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 3, 's', 'i', 'x',
+		0, 0, 0, 1, 7,
+	}, b)
+
+	decoded, err := Decode(params, b)
+	require.NoError(err)
+	require.Equal(values, decoded)
+
+	_, err = Decode(params, append(b, 0))
+	require.ErrorIs(err, ErrTrailingBytes)
+	_, err = Decode(params, b[:len(b)-1])
+	require.ErrorIs(err, ErrInvalidValue)
+	_, err = Encode(params[:1], []any{1})
+	require.ErrorIs(err, ErrInvalidValue)
+	_, err = Encode(params, values[:1])
+	require.ErrorIs(err, ErrWrongValueCount)
+}
+
+func TestParseArgs(t *testing.T) {
+	require := require.New(t)
+
+	a, err := Parse([]byte(`{"functions":[{"name":"transfer","args":[{"name":"to","type":"address"},{"name":"amount","type":"u64"},{"name":"memo","type":"bytes"}]}]}`))
+	require.NoError(err)
+	f, err := a.Function("transfer")
+	require.NoError(err)
+	_, err = a.Function("mint")
+	require.ErrorIs(err, ErrFunctionNotFound)
+
+	to := codec.Address{0x01, 0x02}
+	toString := codec.MustAddressBech32(mconsts.HRP, to)
+	payload, err := f.ParseArgs([]string{toString, "10", "0x0a0b"})
+	require.NoError(err)
+	expected, err := f.EncodeArgs([]any{to, uint64(10), []byte{0x0a, 0x0b}})
+	require.NoError(err)
+	require.Equal(expected, payload)
+
+	_, err = f.ParseArgs([]string{toString, "-1", ""})
+	require.ErrorIs(err, ErrInvalidValue)
+	_, err = f.ParseArgs([]string{toString})
+	require.ErrorIs(err, ErrWrongValueCount)
+
+	require.Equal(toString, FormatValue(Address, to))
+	require.Equal("0x0a0b", FormatValue(Bytes, []byte{0x0a, 0x0b}))
+	require.Equal("10", FormatValue(U64, uint64(10)))
+}
@@ -0,0 +1,16 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package abi
+
+import "errors"
+
+var (
+	ErrInvalidABI        = errors.New("invalid abi")
+	ErrUnknownType       = errors.New("unknown type")
+	ErrFunctionNotFound  = errors.New("function not found")
+	ErrWrongValueCount   = errors.New("wrong number of values")
+	ErrInvalidValue      = errors.New("invalid value")
+	ErrTrailingBytes     = errors.New("trailing bytes")
+	ErrDuplicateFunction = errors.New("duplicate function")
+)
@@ -5,4 +5,17 @@ package actions
 
 const TransferComputeUnits = 1
 const CreateContractComputeUnits = 1
+const UpgradeContractComputeUnits = 1
 const ExecuteContractMinComputeUnits = 10
+
+// ContractBytecodeBytesPerComputeUnit is the amount of deployed bytecode
+// covered by a single compute unit, on top of the units of the action.
+const ContractBytecodeBytesPerComputeUnit = 1024
+
+// ExecuteContractFuelPerComputeUnit is the amount of contract fuel covered by
+// a single compute unit.
+const ExecuteContractFuelPerComputeUnit = 1_000_000
+
+// ExecuteContractComputeUnitsMargin is the margin, in percent, added to the
+// compute units of a simulated call by [Simulation.ComputeUnits].
+const ExecuteContractComputeUnitsMargin = 10
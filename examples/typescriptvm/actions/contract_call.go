@@ -0,0 +1,627 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+
+	smath "github.com/ava-labs/avalanchego/utils/math"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/runtime"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+)
+
+const (
+	// maxCallDepth bounds the nesting of cross-contract calls.
+	maxCallDepth = 8
+
+	// maxCallees bounds the contracts an [ExecuteContract] may declare.
+	maxCallees = 32
+
+	// maxRecipients bounds the extra balances an [ExecuteContract] may declare.
+	maxRecipients = 32
+
+	// maxEvents bounds the events a call may emit, including its callees.
+	// Each event takes an output of the action, so on-chain the limit is
+	// also bounded by [chain.Rules.GetMaxOutputsPerAction].
+	maxEvents         = 32
+	maxEventTopicSize = 64
+	maxEventDataSize  = 1024
+)
+
+var (
+	ErrCallDepthExceeded = errors.New("max contract call depth exceeded")
+	ErrReentrantCall     = errors.New("reentrant contract call")
+	ErrContractNotListed = errors.New("contract keys not declared")
+	ErrKeyNotDeclared    = errors.New("contract key not declared")
+	ErrTooManyCallees    = errors.New("too many callees")
+	ErrBalanceNotListed  = errors.New("balance not declared")
+	ErrTooManyRecipients = errors.New("too many recipients")
+	ErrTooManyEvents     = errors.New("too many events")
+	ErrEventTooLarge     = errors.New("event too large")
+)
+
+// contractCall is a frame of a (possibly nested) contract execution.
+//
+// The writes of completed callees are kept in the frame of their caller and
+// only move up once the caller succeeds, so a failing call discards the
+// changes of all its callees as well.
+type contractCall struct {
+	parent  *contractCall
+	address codec.Address
+	depth   int
+
+	// writes are keyed by full state key
+	writes map[string][]byte
+
+	// balances hold the native balances changed by the call
+	balances map[codec.Address]uint64
+
+	// events are kept in the order they were emitted
+	events []*chain.Event
+}
+
+// contractExecutor runs a contract call and all the calls it makes.
+type contractExecutor struct {
+	ctx context.Context
+	im  state.Immutable
+
+	// keys declares the state each contract may access. If nil, any key may
+	// be accessed, which is only used to simulate calls.
+	keys map[codec.Address]StateKeysWithPermissions
+
+	// balances declares the native balances that may be accessed. If nil, any
+	// balance may be accessed.
+	balances set.Set[codec.Address]
+
+	// block is shared by every call of the execution
+	block runtime.BlockContext
+
+	maxMemory int64
+	maxTime   time.Duration
+	maxEvents int
+
+	// readOnly runs the top-level call, and so every nested call, read-only
+	readOnly bool
+
+	// accessed records the keys touched by each contract, including calls
+	// that failed
+	accessed map[codec.Address]StateKeysWithPermissions
+
+	// accessedBalances records the addresses whose balance was accessed
+	accessedBalances set.Set[codec.Address]
+}
+
+func newContractExecutor(
+	ctx context.Context,
+	im state.Immutable,
+	keys map[codec.Address]StateKeysWithPermissions,
+	balances set.Set[codec.Address],
+	block runtime.BlockContext,
+) *contractExecutor {
+	return &contractExecutor{
+		ctx:              ctx,
+		im:               im,
+		keys:             keys,
+		balances:         balances,
+		block:            block,
+		maxMemory:        1024 * 1024 * 10, // FIXME:move limits to config
+		maxEvents:        maxEvents,
+		accessed:         map[codec.Address]StateKeysWithPermissions{},
+		accessedBalances: set.Set[codec.Address]{},
+	}
+}
+
+// timeLimit is the wall-clock budget of a call: [maxTime], cut short by the
+// deadline of a simulated transaction.
+func (e *contractExecutor) timeLimit() (time.Duration, error) {
+	deadline, ok := chain.SimulationDeadline(e.ctx)
+	if !ok {
+		return e.maxTime, nil
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return 0, context.DeadlineExceeded
+	}
+	if e.maxTime > 0 && e.maxTime < remaining {
+		return e.maxTime, nil
+	}
+	return remaining, nil
+}
+
+// blockContext returns the context of a block built on top of [im].
+func blockContext(
+	ctx context.Context,
+	im state.Immutable,
+	timestamp int64,
+	chainID ids.ID,
+	actionID ids.ID,
+) (runtime.BlockContext, error) {
+	parentHeight, err := storage.GetParentHeight(ctx, im)
+	if err != nil {
+		return runtime.BlockContext{}, fmt.Errorf("failed to get parent height: %w", err)
+	}
+	return runtime.BlockContext{
+		Height:    parentHeight + 1,
+		Timestamp: timestamp,
+		ChainID:   chainID[:],
+		ActionID:  actionID[:],
+	}, nil
+}
+
+// balanceOf returns the balance of [addr] as seen by [frame].
+func (e *contractExecutor) balanceOf(frame *contractCall, addr codec.Address) (uint64, error) {
+	if e.balances != nil && !e.balances.Contains(addr) {
+		return 0, fmt.Errorf("%w: %s", ErrBalanceNotListed, codec.MustAddressBech32(mconsts.HRP, addr))
+	}
+	e.accessedBalances.Add(addr)
+	for f := frame; f != nil; f = f.parent {
+		if bal, ok := f.balances[addr]; ok {
+			return bal, nil
+		}
+	}
+	return storage.GetBalance(e.ctx, e.im, addr)
+}
+
+// transfer moves [amount] from [from] to [to] within [frame].
+func (e *contractExecutor) transfer(frame *contractCall, from codec.Address, to codec.Address, amount uint64) error {
+	if amount == 0 {
+		return ErrOutputValueZero
+	}
+	fromBal, err := e.balanceOf(frame, from)
+	if err != nil {
+		return err
+	}
+	toBal, err := e.balanceOf(frame, to)
+	if err != nil {
+		return err
+	}
+	if fromBal < amount {
+		return fmt.Errorf("%w: could not subtract balance (bal=%d, amount=%d)", storage.ErrInvalidBalance, fromBal, amount)
+	}
+	if from == to {
+		return nil
+	}
+	if _, err := smath.Add64(toBal, amount); err != nil {
+		return fmt.Errorf("%w: could not add balance (bal=%d, amount=%d)", storage.ErrInvalidBalance, toBal, amount)
+	}
+	frame.balances[from] = fromBal - amount
+	frame.balances[to] = toBal + amount
+	return nil
+}
+
+// emit records an event of the contract running in [frame].
+func (e *contractExecutor) emit(frame *contractCall, topic string, data []byte) error {
+	if len(topic) > maxEventTopicSize || len(data) > maxEventDataSize {
+		return fmt.Errorf("%w: topic=%d data=%d (max topic=%d data=%d)", ErrEventTooLarge, len(topic), len(data), maxEventTopicSize, maxEventDataSize)
+	}
+	emitted := 0
+	for f := frame; f != nil; f = f.parent {
+		emitted += len(f.events)
+	}
+	if emitted >= e.maxEvents {
+		return fmt.Errorf("%w: max %d", ErrTooManyEvents, e.maxEvents)
+	}
+	frame.events = append(frame.events, &chain.Event{
+		Emitter: frame.address,
+		Topic:   topic,
+		Data:    bytes.Clone(data),
+	})
+	return nil
+}
+
+// prepare checks that [address] may be called from [parent] and loads its
+// bytecode. Nothing is executed, so a rejected call consumes no fuel.
+func (e *contractExecutor) prepare(parent *contractCall, address codec.Address) (*contractCall, []byte, error) {
+	frame := &contractCall{
+		parent:   parent,
+		address:  address,
+		writes:   map[string][]byte{},
+		balances: map[codec.Address]uint64{},
+	}
+	if parent != nil {
+		frame.depth = parent.depth + 1
+	}
+	if frame.depth > maxCallDepth {
+		return nil, nil, ErrCallDepthExceeded
+	}
+	for f := parent; f != nil; f = f.parent {
+		if f.address == address {
+			return nil, nil, ErrReentrantCall
+		}
+	}
+	if _, ok := e.keys[address]; e.keys != nil && !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrContractNotListed, codec.MustAddressBech32(mconsts.HRP, address))
+	}
+
+	bytecode, err := storage.GetContractBytecode(e.ctx, e.im, address)
+	if err != nil {
+		return nil, nil, err
+	}
+	return frame, bytecode, nil
+}
+
+// execute runs [functionName] of [address]. The returned frame holds all the
+// writes of the call and its callees, which are only meaningful if the call
+// succeeded.
+func (e *contractExecutor) execute(
+	parent *contractCall,
+	address codec.Address,
+	actor codec.Address,
+	functionName string,
+	payload []byte,
+	value uint64,
+	maxFuel uint64,
+) (*runtime.JavyExecResult, *contractCall, error) {
+	frame, bytecode, err := e.prepare(parent, address)
+	if err != nil {
+		return nil, nil, err
+	}
+	if value > 0 {
+		if err := e.transfer(frame, actor, address, value); err != nil {
+			return nil, nil, err
+		}
+	}
+	return e.run(frame, bytecode, actor, functionName, payload, value, maxFuel, e.readOnly)
+}
+
+func (e *contractExecutor) run(
+	frame *contractCall,
+	bytecode []byte,
+	actor codec.Address,
+	functionName string,
+	payload []byte,
+	value uint64,
+	maxFuel uint64,
+	readOnly bool,
+) (*runtime.JavyExecResult, *contractCall, error) {
+	address := frame.address
+	keys := e.keys[address]
+
+	// Values are only fetched when the contract asks for them
+	var stateProvider runtime.StateProvider = func(key string) ([]byte, error) {
+		if e.keys != nil && !keys[key].Has(state.Read) {
+			return nil, fmt.Errorf("%w: %x", ErrKeyNotDeclared, key)
+		}
+		k := string(storage.ContractStateKey(address, []byte(key)))
+		for f := frame; f != nil; f = f.parent {
+			if val, ok := f.writes[k]; ok {
+				return val, nil
+			}
+		}
+		val, err := storage.GetContractStateValue(e.ctx, e.im, address, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get contract state value: %w", err)
+		}
+		return val, nil
+	}
+
+	var contractCaller runtime.ContractCaller = func(callee []byte, functionName string, payload []byte, maxFuel uint64, readOnly bool) (*runtime.JavyExecResult, error) {
+		if len(callee) != codec.AddressLen {
+			return &runtime.JavyExecResult{
+				Result: runtime.ResultJSON{Error: fmt.Sprintf("invalid contract address length %d", len(callee))},
+			}, nil
+		}
+		// Rejected calls are reported to the caller
+		calleeFrame, calleeBytecode, err := e.prepare(frame, codec.Address(callee))
+		if err != nil {
+			return &runtime.JavyExecResult{
+				Result: runtime.ResultJSON{Error: err.Error()},
+			}, nil
+		}
+		res, calleeFrame, err := e.run(calleeFrame, calleeBytecode, address, functionName, payload, 0, maxFuel, readOnly)
+		if err != nil {
+			return nil, err
+		}
+		if res.Result.Success {
+			for k, v := range calleeFrame.writes {
+				frame.writes[k] = v
+			}
+			for addr, bal := range calleeFrame.balances {
+				frame.balances[addr] = bal
+			}
+			frame.events = append(frame.events, calleeFrame.events...)
+		}
+		return res, nil
+	}
+
+	var balanceOf runtime.BalanceProvider = func(addr []byte) (uint64, error) {
+		if len(addr) != codec.AddressLen {
+			return 0, fmt.Errorf("invalid address length %d", len(addr))
+		}
+		return e.balanceOf(frame, codec.Address(addr))
+	}
+
+	var transfer runtime.TransferHandler = func(to []byte, amount uint64) error {
+		if len(to) != codec.AddressLen {
+			return fmt.Errorf("invalid address length %d", len(to))
+		}
+		return e.transfer(frame, address, codec.Address(to), amount)
+	}
+
+	var emit runtime.EventHandler = func(topic string, data []byte) error {
+		return e.emit(frame, topic, data)
+	}
+
+	maxTime, err := e.timeLimit()
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := contractRuntime.Execute(runtime.JavyExecParams{
+		MaxFuel:        maxFuel,
+		MaxTime:        maxTime,
+		MaxMemory:      e.maxMemory,
+		Bytecode:       &bytecode,
+		StateProvider:  stateProvider,
+		ContractCaller: contractCaller,
+		BalanceOf:      balanceOf,
+		Transfer:       transfer,
+		Emit:           emit,
+		Payload:        payload,
+		Actor:          actor[:],
+		Contract:       address[:],
+		Value:          value,
+		FunctionName:   functionName,
+		Block:          e.block,
+		ReadOnly:       readOnly,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute contract: %w", err)
+	}
+
+	accessed, ok := e.accessed[address]
+	if !ok {
+		accessed = StateKeysWithPermissions{}
+		e.accessed[address] = accessed
+	}
+	for _, key := range res.Result.ReadKeys {
+		accessed[string(key)] |= state.Read
+	}
+	for key, val := range res.Result.UpdatedKeys {
+		accessed[key] |= state.Write | state.Allocate
+		frame.writes[string(storage.ContractStateKey(address, []byte(key)))] = val
+	}
+	return res, frame, nil
+}
+
+// commit writes the changes of a successful top-level call to [mu]. Keys are
+// written in order so that execution is identical on every node, and keys
+// set to an empty value are removed.
+func (c *contractCall) commit(ctx context.Context, mu state.Mutable) error {
+	keys := make([]string, 0, len(c.writes))
+	for k := range c.writes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := storage.WriteContractStateValue(ctx, mu, []byte(k), c.writes[k]); err != nil {
+			return err
+		}
+	}
+
+	addrs := make([]codec.Address, 0, len(c.balances))
+	for addr := range c.balances {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	for _, addr := range addrs {
+		var err error
+		if bal := c.balances[addr]; bal == 0 {
+			// Empty balances are removed, as done by [storage.SubBalance]
+			err = mu.Remove(ctx, storage.BalanceKey(addr))
+		} else {
+			err = storage.SetBalance(ctx, mu, addr, bal)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Simulation is the outcome of [SimulateContract].
+type Simulation struct {
+	*runtime.JavyExecResult
+
+	// Keys are the keys accessed by the called contract
+	Keys StateKeysWithPermissions
+
+	// Callees are the keys accessed by every called contract, which must be
+	// declared to execute the same call on-chain.
+	Callees []ContractStateKeys
+
+	// Recipients are the addresses whose balance was accessed, other than the
+	// actor and the contracts.
+	Recipients []codec.Address
+
+	// Events are the events that the call would emit
+	Events []*chain.Event
+}
+
+// SimulateContract runs a contract call against [im] without any declared
+// keys, for example to serve RPC queries. The call sees the block that would
+// follow [im], with the given [timestamp].
+func SimulateContract(
+	ctx context.Context,
+	im state.Immutable,
+	chainID ids.ID,
+	timestamp int64,
+	address codec.Address,
+	actor codec.Address,
+	functionName string,
+	payload []byte,
+	value uint64,
+	maxFuel uint64,
+	maxTime time.Duration,
+) (*Simulation, error) {
+	block, err := blockContext(ctx, im, timestamp, chainID, ids.Empty)
+	if err != nil {
+		return nil, err
+	}
+	e := newContractExecutor(ctx, im, nil, nil, block)
+	e.maxTime = maxTime
+	res, call, err := e.execute(nil, address, actor, functionName, payload, value, maxFuel)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &Simulation{
+		JavyExecResult: res,
+		Keys:           StateKeysWithPermissions{},
+		Callees:        make([]ContractStateKeys, 0, len(e.accessed)),
+		Recipients:     []codec.Address{},
+		Events:         []*chain.Event{},
+	}
+	if res.Result.Success {
+		sim.Events = call.events
+	}
+	for addr, keys := range e.accessed {
+		if addr == address {
+			sim.Keys = keys
+			continue
+		}
+		sim.Callees = append(sim.Callees, ContractStateKeys{ContractAddress: addr, Keys: keys})
+	}
+	sort.Slice(sim.Callees, func(i, j int) bool {
+		return bytes.Compare(sim.Callees[i].ContractAddress[:], sim.Callees[j].ContractAddress[:]) < 0
+	})
+	for addr := range e.accessedBalances {
+		if _, ok := e.accessed[addr]; ok || addr == address || addr == actor {
+			continue
+		}
+		sim.Recipients = append(sim.Recipients, addr)
+	}
+	sort.Slice(sim.Recipients, func(i, j int) bool {
+		return bytes.Compare(sim.Recipients[i][:], sim.Recipients[j][:]) < 0
+	})
+	return sim, nil
+}
+
+// ViewResult is the outcome of [ViewContract].
+type ViewResult struct {
+	*runtime.JavyExecResult
+
+	// Height is the height of the last block applied to the state the view
+	// ran against
+	Height uint64
+}
+
+// ViewContract runs the view [functionName] of [address] read-only against
+// [im], for example to serve RPC queries. Unlike [SimulateContract], the call
+// sees the last block applied to [im] rather than the next one, so that its
+// result only depends on that state and may be cached.
+func ViewContract(
+	ctx context.Context,
+	im state.Immutable,
+	chainID ids.ID,
+	address codec.Address,
+	actor codec.Address,
+	functionName string,
+	payload []byte,
+	maxFuel uint64,
+	maxTime time.Duration,
+) (*ViewResult, error) {
+	height, err := storage.GetParentHeight(ctx, im)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent height: %w", err)
+	}
+	timestamp, err := storage.GetParentTimestamp(ctx, im)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent timestamp: %w", err)
+	}
+	e := newContractExecutor(ctx, im, nil, nil, runtime.BlockContext{
+		Height:    height,
+		Timestamp: timestamp,
+		ChainID:   chainID[:],
+		ActionID:  ids.Empty[:],
+	})
+	e.maxTime = maxTime
+	e.readOnly = true
+	res, _, err := e.execute(nil, address, actor, functionName, payload, 0, maxFuel)
+	if err != nil {
+		return nil, err
+	}
+	return &ViewResult{JavyExecResult: res, Height: height}, nil
+}
+
+// AuthorizeContract runs [functionName] of [address] read-only against [im],
+// to let the contract decide whether a transaction may act on its behalf. The
+// contract may only read its own [keys] and balance, and authorizes the
+// transaction by returning successfully.
+func AuthorizeContract(
+	ctx context.Context,
+	im state.Immutable,
+	chainID ids.ID,
+	timestamp int64,
+	address codec.Address,
+	keys StateKeysWithPermissions,
+	functionName string,
+	payload []byte,
+	maxFuel uint64,
+) error {
+	block, err := blockContext(ctx, im, timestamp, chainID, ids.Empty)
+	if err != nil {
+		return err
+	}
+	e := newContractExecutor(
+		ctx,
+		im,
+		map[codec.Address]StateKeysWithPermissions{address: keys},
+		set.Of(address),
+		block,
+	)
+	e.readOnly = true
+	res, _, err := e.execute(nil, address, codec.EmptyAddress, functionName, payload, 0, maxFuel)
+	if err != nil {
+		return err
+	}
+	if !res.Result.Success {
+		return fmt.Errorf("%w: %s", ErrNotAuthorized, res.Result.Error)
+	}
+	return nil
+}
+
+// ComputeUnits returns the compute units to spend to repeat the simulated
+// call on-chain. A margin of [ExecuteContractComputeUnitsMargin] percent
+// covers state changes in between, unused units are refunded.
+func (s *Simulation) ComputeUnits() uint64 {
+	units := s.FuelConsumed / ExecuteContractFuelPerComputeUnit
+	if s.FuelConsumed%ExecuteContractFuelPerComputeUnit != 0 {
+		units++
+	}
+	units += units * ExecuteContractComputeUnitsMargin / 100
+	return max(ExecuteContractMinComputeUnits, units)
+}
+
+// Action returns an [ExecuteContract] repeating the simulated call, which
+// declares the keys and balances it accessed.
+func (s *Simulation) Action(
+	address codec.Address,
+	functionName string,
+	payload []byte,
+	value uint64,
+) *ExecuteContract {
+	return &ExecuteContract{
+		ContractAddress:     address,
+		Payload:             payload,
+		FunctionName:        functionName,
+		Keys:                s.Keys,
+		ComputeUnitsToSpend: s.ComputeUnits(),
+		Callees:             s.Callees,
+		Value:               value,
+		Recipients:          s.Recipients,
+	}
+}
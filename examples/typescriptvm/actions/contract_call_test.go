@@ -0,0 +1,458 @@
+package actions
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/bytecodealliance/wasmtime-go/v21"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/runtime"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+)
+
+var (
+	callerAddress = codec.Address{0x05, 0x01}
+	calleeAddress = codec.Address{0x05, 0x02}
+	calleeKey     = []byte{0x01, 0x00, 0x01}
+)
+
+type memoryState map[string][]byte
+
+func (m memoryState) GetValue(_ context.Context, key []byte) ([]byte, error) {
+	if v, ok := m[string(key)]; ok {
+		return v, nil
+	}
+	return nil, database.ErrNotFound
+}
+
+func watBytes(b []byte) string {
+	var escaped strings.Builder
+	for _, c := range b {
+		fmt.Fprintf(&escaped, "\\%02x", c)
+	}
+	return escaped.String()
+}
+
+func hostRequest(op byte, key []byte, value []byte) []byte {
+	req := []byte{0x00, 't', 's', 'v', op}
+	req = binary.BigEndian.AppendUint16(req, uint16(len(key)))
+	req = append(req, key...)
+	return append(req, value...)
+}
+
+func callRequest(address codec.Address, functionName string) []byte {
+	value := binary.BigEndian.AppendUint64(nil, 0)
+	value = binary.BigEndian.AppendUint16(value, uint16(len(functionName)))
+	value = append(value, functionName...)
+	return hostRequest(2, address[:], value)
+}
+
+// contractWasm builds a contract that issues [requests] as host calls and then
+// writes [output] to stdout.
+func contractWasm(t *testing.T, output string, requests ...[]byte) []byte {
+	t.Helper()
+
+	var body, data strings.Builder
+	offset := 1024
+	for _, req := range requests {
+		fmt.Fprintf(&data, "  (data (i32.const %d) \"%s\")\n", offset, watBytes(req))
+		fmt.Fprintf(&body, `
+    (i32.store (i32.const 0) (i32.const %d))
+    (i32.store (i32.const 4) (i32.const %d))
+    (drop (call $fd_write (i32.const 2) (i32.const 0) (i32.const 1) (i32.const 8)))`, offset, len(req))
+		offset += len(req)
+	}
+	fmt.Fprintf(&data, "  (data (i32.const %d) \"%s\")\n", offset, watBytes([]byte(output)))
+	fmt.Fprintf(&body, `
+    (i32.store (i32.const 0) (i32.const %d))
+    (i32.store (i32.const 4) (i32.const %d))
+    (drop (call $fd_write (i32.const 1) (i32.const 0) (i32.const 1) (i32.const 8)))`, offset, len(output))
+
+	wasm, err := wasmtime.Wat2Wasm(fmt.Sprintf(`(module
+  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+%s  (func (export "_start")%s))`, data.String(), body.String()))
+	require.NoError(t, err)
+	return wasm
+}
+
+func TestContractCallRollback(t *testing.T) {
+	const success = `{"success":true,"result":""}`
+
+	tests := []struct {
+		name          string
+		calleeOutput  string
+		expectedWrite bool
+	}{
+		{
+			name:          "callee succeeds",
+			calleeOutput:  success,
+			expectedWrite: true,
+		},
+		{
+			name:         "callee fails",
+			calleeOutput: `{"success":false,"error":"boom"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			im := memoryState{
+				string(storage.ContractBytecodeKey(callerAddress)): contractWasm(t, success, callRequest(calleeAddress, "set")),
+				string(storage.ContractBytecodeKey(calleeAddress)): contractWasm(t, tt.calleeOutput, hostRequest(1, calleeKey, []byte{42})),
+			}
+			keys := map[codec.Address]StateKeysWithPermissions{
+				callerAddress: {},
+				calleeAddress: {string(calleeKey): state.Read | state.Write},
+			}
+
+			res, call, err := newContractExecutor(context.Background(), im, keys, nil, runtime.BlockContext{}).
+				execute(nil, callerAddress, codec.EmptyAddress, "call", nil, 0, 100_000_000)
+			require.NoError(err)
+			require.True(res.Result.Success, res.Result.Error)
+
+			calleeStateKey := string(storage.ContractStateKey(calleeAddress, calleeKey))
+			if tt.expectedWrite {
+				require.Equal(map[string][]byte{calleeStateKey: {42}}, call.writes)
+			} else {
+				require.Empty(call.writes)
+			}
+		})
+	}
+}
+
+func TestContractUndeclaredRead(t *testing.T) {
+	const success = `{"success":true,"result":""}`
+	require := require.New(t)
+
+	im := memoryState{
+		string(storage.ContractBytecodeKey(callerAddress)):         contractWasm(t, success, hostRequest(0, calleeKey, nil)),
+		string(storage.ContractStateKey(callerAddress, calleeKey)): {42},
+	}
+
+	// Reads of keys that were not declared trap, like writes
+	keys := map[codec.Address]StateKeysWithPermissions{callerAddress: {}}
+	_, _, err := newContractExecutor(context.Background(), im, keys, nil, runtime.BlockContext{}).
+		execute(nil, callerAddress, codec.EmptyAddress, "get", nil, 0, 100_000_000)
+	require.ErrorContains(err, ErrKeyNotDeclared.Error())
+
+	keys = map[codec.Address]StateKeysWithPermissions{callerAddress: {string(calleeKey): state.Read}}
+	res, _, err := newContractExecutor(context.Background(), im, keys, nil, runtime.BlockContext{}).
+		execute(nil, callerAddress, codec.EmptyAddress, "get", nil, 0, 100_000_000)
+	require.NoError(err)
+	require.True(res.Result.Success, res.Result.Error)
+}
+
+func TestContractCallErrors(t *testing.T) {
+	const success = `{"success":true,"result":""}`
+
+	tests := []struct {
+		name string
+		keys map[codec.Address]StateKeysWithPermissions
+		call []byte
+	}{
+		{
+			name: "reentrant call",
+			keys: map[codec.Address]StateKeysWithPermissions{callerAddress: {}},
+			call: callRequest(callerAddress, "call"),
+		},
+		{
+			name: "undeclared callee",
+			keys: map[codec.Address]StateKeysWithPermissions{callerAddress: {}},
+			call: callRequest(calleeAddress, "set"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			// The callee would write a key if it ever ran
+			im := memoryState{
+				string(storage.ContractBytecodeKey(callerAddress)): contractWasm(t, success, tt.call),
+				string(storage.ContractBytecodeKey(calleeAddress)): contractWasm(t, success, hostRequest(1, calleeKey, []byte{42})),
+			}
+
+			// Failed calls are reported to the caller, which decides whether to fail
+			res, call, err := newContractExecutor(context.Background(), im, tt.keys, nil, runtime.BlockContext{}).
+				execute(nil, callerAddress, codec.EmptyAddress, "call", nil, 0, 100_000_000)
+			require.NoError(err)
+			require.True(res.Result.Success, res.Result.Error)
+			require.Empty(call.writes)
+		})
+	}
+}
+
+func TestContractTransfer(t *testing.T) {
+	const success = `{"success":true,"result":""}`
+	var (
+		actor     = codec.Address{0x00, 0x01}
+		recipient = codec.Address{0x00, 0x02}
+	)
+
+	tests := []struct {
+		name             string
+		recipients       []codec.Address
+		expectedBalances map[codec.Address]uint64
+	}{
+		{
+			name:       "declared recipient",
+			recipients: []codec.Address{recipient},
+			expectedBalances: map[codec.Address]uint64{
+				actor:         5,
+				callerAddress: 75,
+				recipient:     30,
+			},
+		},
+		{
+			// The transfer is rejected, only the value is moved
+			name: "undeclared recipient",
+			expectedBalances: map[codec.Address]uint64{
+				actor:         5,
+				callerAddress: 105,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			transfer := hostRequest(4, recipient[:], binary.BigEndian.AppendUint64(nil, 30))
+			im := memoryState{
+				string(storage.ContractBytecodeKey(callerAddress)): contractWasm(t, success, transfer),
+				string(storage.BalanceKey(actor)):                  binary.BigEndian.AppendUint64(nil, 10),
+				string(storage.BalanceKey(callerAddress)):          binary.BigEndian.AppendUint64(nil, 100),
+			}
+			balances := set.Of(actor, callerAddress)
+			balances.Add(tt.recipients...)
+
+			res, call, err := newContractExecutor(context.Background(), im, map[codec.Address]StateKeysWithPermissions{callerAddress: {}}, balances, runtime.BlockContext{}).
+				execute(nil, callerAddress, actor, "transfer", nil, 5, 100_000_000)
+			require.NoError(err)
+			require.True(res.Result.Success, res.Result.Error)
+			require.Equal(tt.expectedBalances, call.balances)
+		})
+	}
+}
+
+func TestContractEvents(t *testing.T) {
+	const success = `{"success":true,"result":""}`
+
+	tests := []struct {
+		name           string
+		calleeOutput   string
+		expectedEvents []*chain.Event
+	}{
+		{
+			name:         "callee succeeds",
+			calleeOutput: success,
+			expectedEvents: []*chain.Event{
+				{Emitter: callerAddress, Topic: "before", Data: []byte{1}},
+				{Emitter: calleeAddress, Topic: "callee", Data: []byte{2}},
+				{Emitter: callerAddress, Topic: "after", Data: []byte{3}},
+			},
+		},
+		{
+			name:         "callee fails",
+			calleeOutput: `{"success":false,"error":"boom"}`,
+			expectedEvents: []*chain.Event{
+				{Emitter: callerAddress, Topic: "before", Data: []byte{1}},
+				{Emitter: callerAddress, Topic: "after", Data: []byte{3}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			im := memoryState{
+				string(storage.ContractBytecodeKey(callerAddress)): contractWasm(t, success,
+					hostRequest(5, []byte("before"), []byte{1}),
+					callRequest(calleeAddress, "emit"),
+					hostRequest(5, []byte("after"), []byte{3}),
+				),
+				string(storage.ContractBytecodeKey(calleeAddress)): contractWasm(t, tt.calleeOutput,
+					hostRequest(5, []byte("callee"), []byte{2}),
+				),
+			}
+			keys := map[codec.Address]StateKeysWithPermissions{
+				callerAddress: {},
+				calleeAddress: {},
+			}
+
+			res, call, err := newContractExecutor(context.Background(), im, keys, nil, runtime.BlockContext{}).
+				execute(nil, callerAddress, codec.EmptyAddress, "call", nil, 0, 100_000_000)
+			require.NoError(err)
+			require.True(res.Result.Success, res.Result.Error)
+			require.Equal(tt.expectedEvents, call.events)
+
+			// Events are recovered from the action outputs
+			outputs := [][]byte{res.Result.Result}
+			for _, event := range call.events {
+				outputs = append(outputs, event.Bytes())
+			}
+			events, err := (&ExecuteContract{}).Events(outputs)
+			require.NoError(err)
+			require.Equal(tt.expectedEvents, events)
+		})
+	}
+}
+
+func TestSimulationAction(t *testing.T) {
+	require := require.New(t)
+
+	const success = `{"success":true,"result":""}`
+	var (
+		readKey  = []byte{0x01, 0x00, 0x01}
+		writeKey = []byte{0x02, 0x00, 0x01}
+	)
+	im := memoryState{
+		string(storage.ChainHeightKey()): binary.BigEndian.AppendUint64(nil, 1),
+		string(storage.ContractBytecodeKey(callerAddress)): contractWasm(t, success,
+			hostRequest(0, readKey, nil),
+			hostRequest(1, writeKey, []byte{1}),
+			callRequest(calleeAddress, "set"),
+		),
+		string(storage.ContractBytecodeKey(calleeAddress)): contractWasm(t, success, hostRequest(1, calleeKey, []byte{42})),
+	}
+
+	sim, err := SimulateContract(context.Background(), im, ids.Empty, 0, callerAddress, codec.EmptyAddress, "call", []byte{7}, 0, 100_000_000, 0)
+	require.NoError(err)
+	require.True(sim.Result.Success, sim.Result.Error)
+
+	action := sim.Action(callerAddress, "call", []byte{7}, 0)
+	require.Equal(StateKeysWithPermissions{
+		string(readKey):  state.Read,
+		string(writeKey): state.Write | state.Allocate,
+	}, action.Keys)
+	require.Equal([]ContractStateKeys{{
+		ContractAddress: calleeAddress,
+		Keys:            StateKeysWithPermissions{string(calleeKey): state.Write | state.Allocate},
+	}}, action.Callees)
+
+	// The declared compute units cover the simulated fuel with a margin
+	require.GreaterOrEqual(action.ComputeUnitsToSpend*ExecuteContractFuelPerComputeUnit, sim.FuelConsumed)
+	for fuel, expected := range map[uint64]uint64{
+		1:                                        ExecuteContractMinComputeUnits,
+		20 * ExecuteContractFuelPerComputeUnit:   22,
+		20*ExecuteContractFuelPerComputeUnit + 1: 23,
+		1000 * ExecuteContractFuelPerComputeUnit: 1100,
+	} {
+		sim.FuelConsumed = fuel
+		require.Equal(expected, sim.ComputeUnits(), "fuel %d", fuel)
+	}
+}
+
+func TestContractStateDelete(t *testing.T) {
+	const success = `{"success":true,"result":""}`
+	require := require.New(t)
+	ctx := context.Background()
+
+	var (
+		deletedKey  = calleeKey
+		oversizeKey = []byte{0x02, 0x00, 0x01}
+		writtenKey  = []byte{0x03, 0x00, 0x01}
+
+		deletedStateKey = string(storage.ContractStateKey(callerAddress, deletedKey))
+		writtenStateKey = string(storage.ContractStateKey(callerAddress, writtenKey))
+	)
+	im := memoryState{
+		string(storage.ContractBytecodeKey(callerAddress)): contractWasm(t, success,
+			hostRequest(6, deletedKey, nil),
+			// 64 bytes need a second chunk, so the host rejects the write
+			hostRequest(1, oversizeKey, make([]byte, 64)),
+			hostRequest(1, writtenKey, []byte{7}),
+		),
+		deletedStateKey: {1, 2},
+	}
+	keys := map[codec.Address]StateKeysWithPermissions{
+		callerAddress: {
+			string(deletedKey):  state.Read | state.Write,
+			string(oversizeKey): state.Read | state.Write | state.Allocate,
+			string(writtenKey):  state.Read | state.Write | state.Allocate,
+		},
+	}
+
+	res, call, err := newContractExecutor(ctx, im, keys, nil, runtime.BlockContext{}).
+		execute(nil, callerAddress, codec.EmptyAddress, "delete", nil, 0, 100_000_000)
+	require.NoError(err)
+	require.True(res.Result.Success, res.Result.Error)
+	require.Equal(map[string][]byte{
+		deletedStateKey: nil,
+		writtenStateKey: {7},
+	}, call.writes)
+
+	view := tstate.New(10).NewView(state.Keys{
+		deletedStateKey: state.Read | state.Write,
+		writtenStateKey: state.Read | state.Write | state.Allocate,
+	}, map[string][]byte{deletedStateKey: {1, 2}})
+	require.NoError(call.commit(ctx, view))
+
+	_, err = view.GetValue(ctx, []byte(deletedStateKey))
+	require.ErrorIs(err, database.ErrNotFound)
+	val, err := view.GetValue(ctx, []byte(writtenStateKey))
+	require.NoError(err)
+	require.Equal([]byte{7}, val)
+}
+
+func TestViewContract(t *testing.T) {
+	const success = `{"success":true,"result":""}`
+	require := require.New(t)
+	ctx := context.Background()
+
+	set := hostRequest(1, calleeKey, []byte{1})
+	im := memoryState{
+		string(storage.ContractBytecodeKey(callerAddress)): contractWasm(t, success, set, callRequest(calleeAddress, "get")),
+		string(storage.ContractBytecodeKey(calleeAddress)): contractWasm(t, success, set),
+		string(storage.ChainHeightKey()):                   binary.BigEndian.AppendUint64(nil, 7),
+		string(storage.ChainTimestampKey()):                binary.BigEndian.AppendUint64(nil, 1_000),
+	}
+
+	// Writes are rejected in the view and in the calls it makes
+	res, err := ViewContract(ctx, im, ids.Empty, callerAddress, codec.EmptyAddress, "get", nil, 100_000_000, 0)
+	require.NoError(err)
+	require.True(res.Result.Success, res.Result.Error)
+	require.Equal(uint64(7), res.Height)
+	require.Empty(res.Result.UpdatedKeys)
+
+	// The same call writes both contracts otherwise
+	res2, call, err := newContractExecutor(ctx, im, nil, nil, runtime.BlockContext{}).
+		execute(nil, callerAddress, codec.EmptyAddress, "get", nil, 0, 100_000_000)
+	require.NoError(err)
+	require.True(res2.Result.Success, res2.Result.Error)
+	require.Len(call.writes, 2)
+}
+
+func TestAuthorizeContract(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	readKey := []byte{2, 0, 1}
+	im := memoryState{
+		string(storage.ContractBytecodeKey(callerAddress)): contractWasm(t, `{"success":true,"result":""}`, hostRequest(0, readKey, nil)),
+		string(storage.ContractBytecodeKey(calleeAddress)): contractWasm(t, `{"success":false,"error":"unknown signer"}`),
+		string(storage.ChainHeightKey()):                   binary.BigEndian.AppendUint64(nil, 7),
+	}
+	keys := StateKeysWithPermissions{string(readKey): state.Read}
+
+	require.NoError(AuthorizeContract(ctx, im, ids.Empty, 1_000, callerAddress, keys, "authorize", nil, 100_000_000))
+	err := AuthorizeContract(ctx, im, ids.Empty, 1_000, calleeAddress, nil, "authorize", nil, 100_000_000)
+	require.ErrorIs(err, ErrNotAuthorized)
+	require.ErrorContains(err, "unknown signer")
+
+	// Contracts without bytecode cannot authorize anything
+	err = AuthorizeContract(ctx, im, ids.Empty, 1_000, codec.CreateAddress(0, ids.GenerateTestID()), nil, "authorize", nil, 100_000_000)
+	require.Error(err)
+}
@@ -3,23 +3,49 @@ package actions
 import (
 	"context"
 	"encoding/binary"
+	"fmt"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
 
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/abi"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+	"github.com/ava-labs/hypersdk/keys"
 	"github.com/ava-labs/hypersdk/state"
 
+	smath "github.com/ava-labs/avalanchego/utils/math"
+
 	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
 )
 
-var _ chain.Action = (*CreateContract)(nil)
+var (
+	_ chain.MeteredAction = (*CreateContract)(nil)
+	_ chain.EventAction   = (*CreateContract)(nil)
+)
 
 type CreateContract struct {
 	Bytecode      []byte
 	Discriminator uint16
+
+	// Immutable contracts can never be upgraded. Otherwise, the deployer is
+	// recorded as admin and may upgrade the contract with [UpgradeContract].
+	Immutable bool
+
+	// InitFunction, if set, is run in the same action right after the
+	// contract is stored, with the deployer as actor. It may only access
+	// [InitKeys] of the new contract, no balances nor other contracts, and
+	// use up to [InitComputeUnits]. If it fails, the deployment reverts.
+	InitFunction     string
+	InitPayload      []byte
+	InitKeys         StateKeysWithPermissions
+	InitComputeUnits uint64
+
+	// ABI optionally describes the functions of the contract, as JSON
+	// encoded [abi.ABI]. It is stored next to the bytecode for clients.
+	ABI []byte
 }
 
 func (*CreateContract) GetTypeID() uint8 {
@@ -29,41 +55,131 @@ func (*CreateContract) GetTypeID() uint8 {
 func (cc *CreateContract) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
 	contractAddress := storage.GenerateContractAddress(actor, cc.Discriminator)
 
-	return state.Keys{
+	keys := state.Keys{
 		string(storage.ContractBytecodeKey(contractAddress)): state.All,
+		string(storage.ContractMetadataKey(contractAddress)): state.All,
+	}
+	if cc.InitFunction != "" {
+		for k, v := range cc.InitKeys {
+			keys[string(storage.ContractStateKey(contractAddress, []byte(k)))] = v
+		}
+		// See [ExecuteContract.StateKeys]
+		keys[string(storage.ChainHeightKey())] = state.Read
 	}
+	if len(cc.ABI) > 0 {
+		keys[string(storage.ContractABIKey(contractAddress))] = state.All
+	}
+	return keys
 }
 
-func (*CreateContract) StateKeysMaxChunks() []uint16 {
-	return []uint16{storage.ContractBytecodeChunks}
+func (cc *CreateContract) StateKeysMaxChunks() []uint16 {
+	output := []uint16{storage.ContractBytecodeChunks, storage.ContractMetadataChunks}
+	if cc.InitFunction != "" {
+		for key := range cc.InitKeys {
+			maxChunks, _ := keys.MaxChunks([]byte(key))
+			output = append(output, maxChunks)
+		}
+		output = append(output, chain.HeightKeyChunks)
+	}
+	if len(cc.ABI) > 0 {
+		output = append(output, storage.ContractABIChunks)
+	}
+	return output
 }
 
 func (cc *CreateContract) Execute(
 	ctx context.Context,
-	_ chain.Rules,
+	r chain.Rules,
 	mu state.Mutable,
-	_ int64,
+	timestamp int64,
 	actor codec.Address,
-	_ ids.ID,
+	actionID ids.ID,
 ) ([][]byte, error) {
-	addr, err := storage.CreateContract(ctx, mu, actor, cc.Bytecode, cc.Discriminator)
+	outputs, _, err := cc.ExecuteMetered(ctx, r, mu, timestamp, actor, actionID)
+	return outputs, err
+}
+
+// ExecuteMetered stores the contract and runs its init function, if any. The
+// compute units reserved for the init function but not used are refunded.
+func (cc *CreateContract) ExecuteMetered(
+	ctx context.Context,
+	r chain.Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, uint64, error) {
+	computeUnits := cc.ComputeUnits(r)
+	if err := validateBytecode(r, cc.Bytecode); err != nil {
+		return nil, computeUnits, err
+	}
+	if err := validateABI(cc.ABI); err != nil {
+		return nil, computeUnits, err
+	}
+	addr, err := storage.CreateContract(ctx, mu, actor, cc.Bytecode, cc.Discriminator, cc.Immutable)
 	if err != nil {
-		return nil, err // FIXME: Consider defining distinct errors in outputs.go for better clarity
+		return nil, computeUnits, err // FIXME: Consider defining distinct errors in outputs.go for better clarity
+	}
+	if len(cc.ABI) > 0 {
+		if err := storage.SetContractABI(ctx, mu, addr, cc.ABI); err != nil {
+			return nil, computeUnits, err
+		}
 	}
 
 	addrString := codec.MustAddressBech32(mconsts.HRP, addr)
+	if cc.InitFunction == "" {
+		return [][]byte{[]byte(addrString)}, computeUnits, nil
+	}
 
-	return [][]byte{
-		[]byte(addrString),
-	}, nil
+	maxFuel, err := smath.Mul64(cc.InitComputeUnits, ExecuteContractFuelPerComputeUnit)
+	if err != nil {
+		return nil, computeUnits, fmt.Errorf("init compute units (%d) too large: %w", cc.InitComputeUnits, err)
+	}
+	block, err := blockContext(ctx, mu, timestamp, r.ChainID(), actionID)
+	if err != nil {
+		return nil, computeUnits, err
+	}
+
+	// No balance may be accessed during init
+	e := newContractExecutor(ctx, mu, map[codec.Address]StateKeysWithPermissions{addr: cc.InitKeys}, set.Set[codec.Address]{}, block)
+	// The first output holds the address
+	e.maxEvents = min(e.maxEvents, int(r.GetMaxOutputsPerAction())-1)
+	res, call, err := e.execute(nil, addr, actor, cc.InitFunction, cc.InitPayload, 0, maxFuel)
+	if err != nil {
+		return nil, computeUnits, err
+	}
+
+	computeUnits -= cc.InitComputeUnits - min(ComputeUnitsForFuel(res.FuelConsumed), cc.InitComputeUnits)
+	if !res.Result.Success {
+		return nil, computeUnits, fmt.Errorf("%w: %s", ErrContractInitFailed, res.Result.Error)
+	}
+	if err := call.commit(ctx, mu); err != nil {
+		return nil, computeUnits, fmt.Errorf("failed to update contract state: %w", err)
+	}
+
+	outputs := make([][]byte, 0, 1+len(call.events))
+	outputs = append(outputs, []byte(addrString))
+	for _, event := range call.events {
+		outputs = append(outputs, event.Bytes())
+	}
+	return outputs, computeUnits, nil
 }
 
-func (*CreateContract) ComputeUnits(chain.Rules) uint64 {
-	return CreateContractComputeUnits
+// Events decodes the events emitted by the init function, which follow the
+// address of the contract in [outputs].
+func (*CreateContract) Events(outputs [][]byte) ([]*chain.Event, error) {
+	return unmarshalEvents(outputs)
+}
+
+func (cc *CreateContract) ComputeUnits(chain.Rules) uint64 {
+	return CreateContractComputeUnits + bytecodeComputeUnits(cc.Bytecode) + bytecodeComputeUnits(cc.ABI) + cc.InitComputeUnits
 }
 
 func (cc *CreateContract) Size() int {
-	return len(cc.Bytecode) + consts.Uint8Len
+	// The discriminator is packed as bytes
+	return codec.BytesLen(cc.Bytecode) + consts.IntLen + consts.Uint16Len + consts.BoolLen +
+		codec.StringLen(cc.InitFunction) + codec.BytesLen(cc.InitPayload) + keysSize(cc.InitKeys) + consts.Uint64Len +
+		codec.BytesLen(cc.ABI)
 }
 
 func (cc *CreateContract) Marshal(p *codec.Packer) {
@@ -72,6 +188,13 @@ func (cc *CreateContract) Marshal(p *codec.Packer) {
 	discriminatorBytes := make([]byte, 2)
 	binary.BigEndian.PutUint16(discriminatorBytes, cc.Discriminator)
 	p.PackBytes(discriminatorBytes)
+	p.PackBool(cc.Immutable)
+
+	p.PackString(cc.InitFunction)
+	p.PackBytes(cc.InitPayload)
+	marshalKeys(cc.InitKeys, p)
+	p.PackUint64(cc.InitComputeUnits)
+	p.PackBytes(cc.ABI)
 }
 
 func UnmarshalCreateContract(p *codec.Packer) (chain.Action, error) {
@@ -81,11 +204,59 @@ func UnmarshalCreateContract(p *codec.Packer) (chain.Action, error) {
 	var discriminatorBytes []byte = make([]byte, 2)
 	p.UnpackBytes(2, false, &discriminatorBytes)
 	action.Discriminator = binary.BigEndian.Uint16(discriminatorBytes)
+	action.Immutable = p.UnpackBool()
 
-	return &action, nil
+	action.InitFunction = p.UnpackString(false)
+	p.UnpackBytes(-1, false, &action.InitPayload)
+	keys, err := unmarshalKeys(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		action.InitKeys = keys
+	}
+	action.InitComputeUnits = p.UnpackUint64(false)
+	p.UnpackBytes(storage.MaxContractABISize, false, &action.ABI)
+
+	return &action, p.Err()
 }
 
 func (*CreateContract) ValidRange(chain.Rules) (int64, int64) {
 	// Returning -1, -1 means that the action is always valid.
 	return -1, -1
 }
+
+// validateBytecode rejects bytecode larger than the maximum contract size of
+// [r] or that is not a Javy module, before it is written to state. The module
+// is compiled ahead of its first call as a side effect.
+func validateBytecode(r chain.Rules, bytecode []byte) error {
+	maxSize := storage.MaxContractBytecodeSize
+	if v, ok := r.FetchCustom(mconsts.MaxContractSizeRule); ok {
+		if size, ok := v.(int); ok {
+			maxSize = size
+		}
+	}
+	if len(bytecode) > maxSize {
+		return ErrContractTooLarge
+	}
+	return contractRuntime.Validate(bytecode)
+}
+
+// validateABI rejects an ABI that does not fit in its key or is invalid. An
+// empty ABI is valid: contracts do not have to publish one.
+func validateABI(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if len(b) > storage.MaxContractABISize {
+		return ErrABITooLarge
+	}
+	_, err := abi.Parse(b)
+	return err
+}
+
+// bytecodeComputeUnits charges validating and compiling [bytecode] in
+// proportion to its size. ABIs are charged the same for being parsed.
+func bytecodeComputeUnits(bytecode []byte) uint64 {
+	return (uint64(len(bytecode)) + ContractBytecodeBytesPerComputeUnit - 1) / ContractBytecodeBytesPerComputeUnit
+}
@@ -0,0 +1,246 @@
+package actions
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/bytecodealliance/wasmtime-go/v21"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/abi"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/genesis"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/runtime"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+)
+
+// javyModule builds a minimal module passing deploy-time validation. Modules
+// of different versions have different bytecode.
+func javyModule(t *testing.T, version int) []byte {
+	t.Helper()
+
+	wasm, err := wasmtime.Wat2Wasm(fmt.Sprintf(`(module
+  (import "javy_quickjs_provider_v2" "memory" (memory 0))
+  (func (export "_start"))
+  (func (export "v%d")))`, version))
+	require.NoError(t, err)
+	return wasm
+}
+
+func TestCreateContractValidation(t *testing.T) {
+	deployer := codec.Address{0x00, 0x01}
+	module := javyModule(t, 1)
+	wasi, err := wasmtime.Wat2Wasm(`(module
+  (import "wasi_snapshot_preview1" "proc_exit" (func (param i32)))
+  (func (export "_start")))`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		bytecode        []byte
+		maxContractSize int
+		err             error
+	}{
+		{
+			name:            "javy module",
+			bytecode:        module,
+			maxContractSize: len(module),
+		},
+		{
+			name:            "too large",
+			bytecode:        module,
+			maxContractSize: len(module) - 1,
+			err:             ErrContractTooLarge,
+		},
+		{
+			name:            "not wasm",
+			bytecode:        []byte{1},
+			maxContractSize: storage.MaxContractBytecodeSize,
+			err:             runtime.ErrInvalidModule,
+		},
+		{
+			name:            "not a javy module",
+			bytecode:        wasi,
+			maxContractSize: storage.MaxContractBytecodeSize,
+			err:             runtime.ErrInvalidModule,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			g := genesis.Default()
+			g.MaxContractSize = tt.maxContractSize
+			action := &CreateContract{Bytecode: tt.bytecode}
+			view := tstate.New(10).NewView(action.StateKeys(deployer, ids.Empty), map[string][]byte{})
+			_, err := action.Execute(ctx, g.Rules(0, 0, ids.Empty), view, 0, deployer, ids.Empty)
+			require.ErrorIs(err, tt.err)
+
+			// Rejected bytecode is never written
+			_, err = storage.GetContractBytecode(ctx, view, storage.GenerateContractAddress(deployer, 0))
+			if tt.err == nil {
+				require.NoError(err)
+			} else {
+				require.ErrorIs(err, database.ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestCreateContractComputeUnits(t *testing.T) {
+	require := require.New(t)
+
+	for size, units := range map[int]uint64{
+		0:    CreateContractComputeUnits,
+		1:    CreateContractComputeUnits + 1,
+		1024: CreateContractComputeUnits + 1,
+		1025: CreateContractComputeUnits + 2,
+	} {
+		require.Equal(units, (&CreateContract{Bytecode: make([]byte, size)}).ComputeUnits(nil), size)
+	}
+}
+
+func TestCreateContractInit(t *testing.T) {
+	const setKey = `Javy.IO.writeSync(2, new Uint8Array([0x00, 0x74, 0x73, 0x76, 1, 0, 3, 1, 0, 1, 42]));`
+
+	tests := []struct {
+		name   string
+		output string
+		err    error
+	}{
+		{
+			name:   "init succeeds",
+			output: `{"success":true,"result":""}`,
+		},
+		{
+			name:   "init fails",
+			output: `{"success":false,"error":"boom"}`,
+			err:    ErrContractInitFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			bytecode, err := runtime.CompileJS(fmt.Sprintf("%s\nJavy.IO.writeSync(1, new TextEncoder().encode(%q));", setKey, tt.output))
+			require.NoError(err)
+
+			deployer := codec.Address{0x00, 0x01}
+			key := []byte{0x01, 0x00, 0x01}
+			action := &CreateContract{
+				Bytecode:         bytecode,
+				InitFunction:     "init",
+				InitKeys:         StateKeysWithPermissions{string(key): state.Read | state.Write | state.Allocate},
+				InitComputeUnits: 1_000,
+			}
+			rules := genesis.Default().Rules(0, 0, ids.Empty)
+			view := tstate.New(10).NewView(action.StateKeys(deployer, ids.Empty), map[string][]byte{
+				string(storage.ChainHeightKey()): binary.BigEndian.AppendUint64(nil, 1),
+			})
+			outputs, computeUnits, err := action.ExecuteMetered(ctx, rules, view, 0, deployer, ids.Empty)
+			require.ErrorIs(err, tt.err)
+			require.Less(computeUnits, action.ComputeUnits(rules))
+			if tt.err != nil {
+				return
+			}
+
+			contract := storage.GenerateContractAddress(deployer, 0)
+			require.Equal([][]byte{[]byte(codec.MustAddressBech32(mconsts.HRP, contract))}, outputs)
+			val, err := storage.GetContractStateValue(ctx, view, contract, string(key))
+			require.NoError(err)
+			require.Equal([]byte{42}, val)
+		})
+	}
+}
+
+func TestCreateContractABI(t *testing.T) {
+	const counterABI = `{"functions":[{"name":"increment","args":[{"name":"by","type":"u64"}]},{"name":"get","returns":[{"name":"count","type":"u64"}],"readOnly":true}]}`
+
+	tests := []struct {
+		name string
+		abi  []byte
+		err  error
+	}{
+		{
+			name: "valid abi",
+			abi:  []byte(counterABI),
+		},
+		{
+			name: "no abi",
+		},
+		{
+			name: "not json",
+			abi:  []byte("increment(u64)"),
+			err:  abi.ErrInvalidABI,
+		},
+		{
+			name: "unknown type",
+			abi:  []byte(`{"functions":[{"name":"increment","args":[{"name":"by","type":"u256"}]}]}`),
+			err:  abi.ErrUnknownType,
+		},
+		{
+			name: "too large",
+			abi:  make([]byte, storage.MaxContractABISize+1),
+			err:  ErrABITooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			deployer := codec.Address{0x00, 0x01}
+			action := &CreateContract{Bytecode: javyModule(t, 1), ABI: tt.abi}
+			view := tstate.New(10).NewView(action.StateKeys(deployer, ids.Empty), map[string][]byte{})
+			_, err := action.Execute(ctx, genesis.Default().Rules(0, 0, ids.Empty), view, 0, deployer, ids.Empty)
+			require.ErrorIs(err, tt.err)
+			if tt.err != nil {
+				return
+			}
+
+			abiKey := storage.ContractABIKey(storage.GenerateContractAddress(deployer, 0))
+			if len(tt.abi) == 0 {
+				// Deploying without an ABI does not pay for its key
+				require.NotContains(action.StateKeys(deployer, ids.Empty), string(abiKey))
+				return
+			}
+			stored, err := view.GetValue(ctx, abiKey)
+			require.NoError(err)
+			require.Equal(tt.abi, stored)
+		})
+	}
+}
+
+func TestCreateContractMarshal(t *testing.T) {
+	require := require.New(t)
+
+	action := &CreateContract{
+		Bytecode:         []byte{1, 2, 3},
+		Discriminator:    7,
+		Immutable:        true,
+		InitFunction:     "init",
+		InitPayload:      []byte{4},
+		InitKeys:         StateKeysWithPermissions{"\x01\x00\x01": state.Read},
+		InitComputeUnits: 10,
+		ABI:              []byte(`{"functions":[]}`),
+	}
+	p := codec.NewWriter(action.Size(), action.Size())
+	action.Marshal(p)
+	require.NoError(p.Err())
+	require.Len(p.Bytes(), action.Size())
+
+	unmarshaled, err := UnmarshalCreateContract(codec.NewReader(p.Bytes(), len(p.Bytes())))
+	require.NoError(err)
+	require.Equal(action, unmarshaled)
+}
@@ -3,15 +3,18 @@ package actions
 import (
 	"bytes"
 	"context"
-	"encoding/binary"
 	"fmt"
 	"sort"
-	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	smath "github.com/ava-labs/avalanchego/utils/math"
 
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/keys"
 	"github.com/ava-labs/hypersdk/state"
 
 	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
@@ -19,7 +22,19 @@ import (
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
 )
 
-var _ chain.Action = (*ExecuteContract)(nil)
+var (
+	_ chain.MeteredAction = (*ExecuteContract)(nil)
+	_ chain.EventAction   = (*ExecuteContract)(nil)
+)
+
+// contractRuntime is shared by every contract call, so that compiled contracts
+// are reused across transactions and blocks.
+var contractRuntime = runtime.NewJavyExec()
+
+// ContractRuntime returns the runtime used to execute contracts.
+func ContractRuntime() *runtime.JavyExec {
+	return contractRuntime
+}
 
 type ExecuteContract struct {
 	ContractAddress     codec.Address            `json:"contractAddress"`
@@ -27,6 +42,44 @@ type ExecuteContract struct {
 	FunctionName        string                   `json:"functionName"`
 	Keys                StateKeysWithPermissions `json:"stateKeys"`
 	ComputeUnitsToSpend uint64                   `json:"computeUnitsToSpend"`
+
+	// Callees declares the contracts that may be called, directly or not,
+	// together with the state keys they access.
+	Callees []ContractStateKeys `json:"callees"`
+
+	// Value is transferred from the actor to the contract before it runs.
+	Value uint64 `json:"value"`
+
+	// Recipients declares the addresses whose native balance the contracts
+	// may read or credit, besides the actor and the contracts themselves.
+	Recipients []codec.Address `json:"recipients"`
+}
+
+// ContractStateKeys are the state keys a contract accesses during a call.
+type ContractStateKeys struct {
+	ContractAddress codec.Address            `json:"contractAddress"`
+	Keys            StateKeysWithPermissions `json:"stateKeys"`
+}
+
+// contractKeys returns the keys declared for every contract of the call.
+func (ec *ExecuteContract) contractKeys() map[codec.Address]StateKeysWithPermissions {
+	keys := make(map[codec.Address]StateKeysWithPermissions, 1+len(ec.Callees))
+	for _, callee := range ec.Callees {
+		keys[callee.ContractAddress] = callee.Keys
+	}
+	keys[ec.ContractAddress] = ec.Keys
+	return keys
+}
+
+// balances returns the addresses whose native balance may be accessed.
+func (ec *ExecuteContract) balances(actor codec.Address) set.Set[codec.Address] {
+	balances := set.NewSet[codec.Address](2 + len(ec.Callees) + len(ec.Recipients))
+	balances.Add(actor, ec.ContractAddress)
+	for _, callee := range ec.Callees {
+		balances.Add(callee.ContractAddress)
+	}
+	balances.Add(ec.Recipients...)
+	return balances
 }
 
 func (*ExecuteContract) GetTypeID() uint8 {
@@ -35,95 +88,129 @@ func (*ExecuteContract) GetTypeID() uint8 {
 
 func (ec *ExecuteContract) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
 	keys := make(state.Keys, len(ec.Keys))
-	for k, v := range ec.Keys {
-		keys[string(storage.ContractStateKey(ec.ContractAddress, []byte(k)))] = v
+	for addr, contractKeys := range ec.contractKeys() {
+		for k, v := range contractKeys {
+			keys[string(storage.ContractStateKey(addr, []byte(k)))] = v
+		}
+		keys[string(storage.ContractBytecodeKey(addr))] = state.Read
 	}
-	keys[string(storage.ContractBytecodeKey(ec.ContractAddress))] = state.Read
-
-	//debug
-	for k, v := range keys {
-		fmt.Printf("StateKeys: key: %x, val: %d\n", []byte(k), v)
+	for addr := range ec.balances(actor) {
+		keys[string(storage.BalanceKey(addr))] = state.All
 	}
+	// The parent height gives contracts the height of the block
+	keys[string(storage.ChainHeightKey())] = state.Read
 
 	return keys
 }
 
-// FIXME: This code was copied from hypersdk/examples/typescriptvm/actions/evm_call.go in the evm-call-experiment branch. It needs testing.
 func (ec *ExecuteContract) StateKeysMaxChunks() []uint16 {
 	output := make([]uint16, 0, len(ec.Keys))
 	for key := range ec.Keys {
-		keyBytes := []byte(key)
-		maxChunks := binary.BigEndian.Uint16(keyBytes[len(keyBytes)-2:])
+		maxChunks, _ := keys.MaxChunks([]byte(key)) // checked by [unmarshalKeys]
 		output = append(output, maxChunks)
 	}
-	return output
+	for _, callee := range ec.Callees {
+		for key := range callee.Keys {
+			maxChunks, _ := keys.MaxChunks([]byte(key))
+			output = append(output, maxChunks)
+		}
+	}
+	// Balances of the actor, the contracts and the recipients
+	for i := 0; i < 2+len(ec.Callees)+len(ec.Recipients); i++ {
+		output = append(output, storage.BalanceChunks)
+	}
+	return append(output, chain.HeightKeyChunks)
 }
 
 func (ec *ExecuteContract) Execute(
 	ctx context.Context,
-	_ chain.Rules,
+	r chain.Rules,
 	mu state.Mutable,
-	_ int64,
+	timestamp int64,
 	actor codec.Address,
-	_ ids.ID,
+	actionID ids.ID,
 ) ([][]byte, error) {
-	bytecode, err := storage.GetContractBytecode(ctx, mu, ec.ContractAddress)
-	if err != nil {
-		return nil, err
-	}
-
-	precachedValues := make(map[string][]byte)
-
-	for keyPostfix, permissions := range ec.Keys {
-		if permissions&state.Read == 0 {
-			continue
-		}
-
-		val, err := storage.GetContractStateValue(ctx, mu, ec.ContractAddress, keyPostfix)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get contract state value: %w", err)
-		}
-		precachedValues[keyPostfix] = val
-	}
+	outputs, _, err := ec.ExecuteMetered(ctx, r, mu, timestamp, actor, actionID)
+	return outputs, err
+}
 
-	var fixedStateProvider runtime.StateProvider = func(key string) ([]byte, error) {
-		return precachedValues[string(key)], nil
+// ExecuteMetered runs the contract with a fuel budget of [ComputeUnitsToSpend]
+// and reports the compute units actually used, so the rest can be refunded.
+func (ec *ExecuteContract) ExecuteMetered(
+	ctx context.Context,
+	r chain.Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, uint64, error) {
+	maxFuel, err := smath.Mul64(ec.ComputeUnitsToSpend, ExecuteContractFuelPerComputeUnit)
+	if err != nil {
+		return nil, ec.ComputeUnitsToSpend, fmt.Errorf("compute units to spend (%d) too large: %w", ec.ComputeUnitsToSpend, err)
 	}
 
-	params := runtime.JavyExecParams{ // FIXME:move limits to config
-		MaxFuel:       10 * 1000 * 1000,
-		MaxTime:       time.Millisecond * 10,
-		MaxMemory:     1024 * 1024 * 10,
-		Bytecode:      &bytecode,
-		StateProvider: fixedStateProvider,
-		Payload:       ec.Payload,
-		Actor:         actor[:],
-		FunctionName:  ec.FunctionName,
+	block, err := blockContext(ctx, mu, timestamp, r.ChainID(), actionID)
+	if err != nil {
+		return nil, ec.ComputeUnitsToSpend, err
 	}
 
-	res, err := runtime.NewJavyExec().Execute(params)
+	e := newContractExecutor(ctx, mu, ec.contractKeys(), ec.balances(actor), block)
+	// The first output holds the result
+	e.maxEvents = min(e.maxEvents, int(r.GetMaxOutputsPerAction())-1)
+	res, call, err := e.execute(nil, ec.ContractAddress, actor, ec.FunctionName, ec.Payload, ec.Value, maxFuel)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute contract: %w", err)
+		return nil, ec.ComputeUnitsToSpend, err
 	}
 
+	computeUnitsSpent := min(ComputeUnitsForFuel(res.FuelConsumed), ec.ComputeUnitsToSpend)
+
 	if res.Result.Success != true {
-		return nil, fmt.Errorf("contract execution failed: %s", res.Result.Error)
+		return nil, computeUnitsSpent, fmt.Errorf("contract execution failed: %s", res.Result.Error)
 	}
 
-	err = storage.UpdateContractStateFields(ctx, mu, ec.ContractAddress, res.Result.UpdatedKeys)
+	err = call.commit(ctx, mu)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update contract state: %w", err)
+		return nil, computeUnitsSpent, fmt.Errorf("failed to update contract state: %w", err)
 	}
 
-	computeUnitsSpent := res.FuelConsumed / 1_000_000 // TODO: move to consts
-	computeUnitsSpent = max(ExecuteContractMinComputeUnits, computeUnitsSpent)
-
-	if computeUnitsSpent != ec.ComputeUnitsToSpend {
-		return nil, fmt.Errorf("compute units spent (%d) does not equal the compute units to spend (%d)", computeUnitsSpent, ec.ComputeUnitsToSpend)
+	outputs := make([][]byte, 0, 1+len(call.events))
+	outputs = append(outputs, res.Result.Result)
+	for _, event := range call.events {
+		outputs = append(outputs, event.Bytes())
 	}
+	return outputs, computeUnitsSpent, nil
+}
+
+// Events decodes the events that follow the result in [outputs].
+func (*ExecuteContract) Events(outputs [][]byte) ([]*chain.Event, error) {
+	return unmarshalEvents(outputs)
+}
 
-	return [][]byte{res.Result.Result}, nil
+// unmarshalEvents decodes the events that follow the first output of a
+// contract action.
+func unmarshalEvents(outputs [][]byte) ([]*chain.Event, error) {
+	if len(outputs) == 0 {
+		return nil, nil
+	}
+	events := make([]*chain.Event, 0, len(outputs)-1)
+	for _, output := range outputs[1:] {
+		p := codec.NewReader(output, consts.MaxInt)
+		event, err := chain.UnmarshalEvent(p)
+		if err != nil {
+			return nil, err
+		}
+		if !p.Empty() {
+			return nil, chain.ErrInvalidObject
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
 
+// ComputeUnitsForFuel converts the fuel consumed by a contract call into
+// compute units.
+func ComputeUnitsForFuel(fuel uint64) uint64 {
+	return max(ExecuteContractMinComputeUnits, fuel/ExecuteContractFuelPerComputeUnit)
 }
 
 func (ec *ExecuteContract) ComputeUnits(chain.Rules) uint64 {
@@ -131,7 +218,15 @@ func (ec *ExecuteContract) ComputeUnits(chain.Rules) uint64 {
 }
 
 func (ec *ExecuteContract) Size() int {
-	return len(ec.ContractAddress) + len(ec.Payload) + len(ec.Keys)*(4+1)
+	size := codec.AddressLen + consts.BoolLen + codec.StringLen(ec.FunctionName) + keysSize(ec.Keys) +
+		consts.Uint64Len + consts.IntLen
+	if ec.Payload != nil {
+		size += codec.BytesLen(ec.Payload)
+	}
+	for _, callee := range ec.Callees {
+		size += codec.AddressLen + keysSize(callee.Keys)
+	}
+	return size + consts.Uint64Len + consts.IntLen + len(ec.Recipients)*codec.AddressLen
 }
 
 func (ec *ExecuteContract) Marshal(p *codec.Packer) {
@@ -140,6 +235,16 @@ func (ec *ExecuteContract) Marshal(p *codec.Packer) {
 	p.PackString(ec.FunctionName)
 	marshalKeys(ec.Keys, p)
 	p.PackUint64(ec.ComputeUnitsToSpend)
+	p.PackInt(len(ec.Callees))
+	for _, callee := range ec.Callees {
+		p.PackAddress(callee.ContractAddress)
+		marshalKeys(callee.Keys, p)
+	}
+	p.PackUint64(ec.Value)
+	p.PackInt(len(ec.Recipients))
+	for _, recipient := range ec.Recipients {
+		p.PackAddress(recipient)
+	}
 }
 
 func UnmarshalExecuteContract(p *codec.Packer) (chain.Action, error) {
@@ -162,7 +267,35 @@ func UnmarshalExecuteContract(p *codec.Packer) (chain.Action, error) {
 
 	executeContract.ComputeUnitsToSpend = p.UnpackUint64(false)
 
-	return &executeContract, nil
+	numCallees := p.UnpackInt(false)
+	if numCallees > maxCallees {
+		return nil, ErrTooManyCallees
+	}
+	if numCallees > 0 {
+		executeContract.Callees = make([]ContractStateKeys, numCallees)
+	}
+	for i := 0; i < numCallees; i++ {
+		p.UnpackAddress(&executeContract.Callees[i].ContractAddress)
+		executeContract.Callees[i].Keys, err = unmarshalKeys(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	executeContract.Value = p.UnpackUint64(false)
+
+	numRecipients := p.UnpackInt(false)
+	if numRecipients > maxRecipients {
+		return nil, ErrTooManyRecipients
+	}
+	if numRecipients > 0 {
+		executeContract.Recipients = make([]codec.Address, numRecipients)
+	}
+	for i := 0; i < numRecipients; i++ {
+		p.UnpackAddress(&executeContract.Recipients[i])
+	}
+
+	return &executeContract, p.Err()
 }
 
 func (*ExecuteContract) ValidRange(chain.Rules) (int64, int64) {
@@ -185,16 +318,32 @@ func marshalKeys(keys map[string]state.Permissions, p *codec.Packer) {
 	}
 }
 
+// keysSize is the size of [keys] as packed by [marshalKeys].
+func keysSize(keys StateKeysWithPermissions) int {
+	size := consts.IntLen
+	for k := range keys {
+		size += codec.BytesLen([]byte(k)) + consts.ByteLen
+	}
+	return size
+}
+
 func unmarshalKeys(p *codec.Packer) (StateKeysWithPermissions, error) {
 	numKeys := p.UnpackInt(false)
-	keys := make(StateKeysWithPermissions, numKeys)
+	stateKeys := make(StateKeysWithPermissions, numKeys)
 	for i := 0; i < numKeys; i++ {
 		var keyPostfix []byte
 		p.UnpackBytes(10000, false, &keyPostfix) // Deserialize the 4-byte KeyPostfix
+		if p.Err() != nil {
+			return nil, p.Err()
+		}
+		// Keys end with their max chunks, which [StateKeysMaxChunks] reads
+		if _, ok := keys.MaxChunks(keyPostfix); !ok {
+			return nil, fmt.Errorf("%w: %x", chain.ErrInvalidKeyValue, keyPostfix)
+		}
 		perm := state.Permissions(p.UnpackByte())
-		keys[string(keyPostfix)] = perm
+		stateKeys[string(keyPostfix)] = perm
 	}
-	return keys, p.Err()
+	return stateKeys, p.Err()
 }
 
 func packBytesOrNull(p *codec.Packer, b []byte) {
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/state"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
 )
 
 func TestExecuteContractSerialization(t *testing.T) {
@@ -90,6 +93,15 @@ func TestExecuteContractSerialization(t *testing.T) {
 				FunctionName:        "",
 			},
 		},
+		{
+			name: "Value and recipients",
+			action: ExecuteContract{
+				ContractAddress:     testAddress,
+				ComputeUnitsToSpend: 0,
+				Value:               1000,
+				Recipients:          []codec.Address{{0x01}, {0x02}},
+			},
+		},
 		{
 			name: "All fields filled",
 			action: ExecuteContract{
@@ -103,6 +115,12 @@ func TestExecuteContractSerialization(t *testing.T) {
 				},
 				ComputeUnitsToSpend: 999888777,
 				FunctionName:        "functionName2",
+				Callees: []ContractStateKeys{
+					{ContractAddress: codec.Address{0x02}, Keys: StateKeysWithPermissions{"\x01\x00\x01": state.Read}},
+					{ContractAddress: codec.Address{0x03}},
+				},
+				Value:      1,
+				Recipients: []codec.Address{{0x04}},
 			},
 		},
 	}
@@ -117,6 +135,8 @@ func TestExecuteContractSerialization(t *testing.T) {
 				t.Fatalf("Marshal failed: %v", packer.Err())
 			}
 
+			require.Equal(t, tt.action.Size(), len(packer.Bytes()), "Size mismatch")
+
 			n, err := buf.Write(packer.Bytes())
 			require.NoError(t, err)
 			require.Equal(t, len(packer.Bytes()), n)
@@ -138,6 +158,9 @@ func TestExecuteContractSerialization(t *testing.T) {
 			require.Equal(t, len(tt.action.Keys), len(unmarshalledEC.Keys), "Keys length mismatch")
 			require.Equal(t, tt.action.ComputeUnitsToSpend, unmarshalledEC.ComputeUnitsToSpend, "ComputeUnitsToSpend mismatch")
 			require.Equal(t, tt.action.FunctionName, unmarshalledEC.FunctionName, "FunctionName mismatch")
+			require.Equal(t, tt.action.Value, unmarshalledEC.Value, "Value mismatch")
+			require.Equal(t, tt.action.Recipients, unmarshalledEC.Recipients, "Recipients mismatch")
+			require.Equal(t, len(tt.action.Callees), len(unmarshalledEC.Callees), "Callees length mismatch")
 
 			for k, v := range tt.action.Keys {
 				require.Equal(t, v, unmarshalledEC.Keys[k], "Permissions mismatch for key %v", k)
@@ -145,3 +168,30 @@ func TestExecuteContractSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteContractStateKeysMaxChunks(t *testing.T) {
+	require := require.New(t)
+
+	action := &ExecuteContract{
+		ContractAddress: codec.Address{0x01},
+		Keys:            StateKeysWithPermissions{"\x01\x00\x03": state.Read},
+		Callees: []ContractStateKeys{
+			{ContractAddress: codec.Address{0x02}, Keys: StateKeysWithPermissions{"\x02\x00\x05": state.All}},
+		},
+		Recipients: []codec.Address{{0x03}},
+	}
+	// The declared keys, then the balances of the actor, the contracts and
+	// the recipient, then the chain height
+	require.Equal(
+		[]uint16{3, 5, storage.BalanceChunks, storage.BalanceChunks, storage.BalanceChunks, storage.BalanceChunks, chain.HeightKeyChunks},
+		action.StateKeysMaxChunks(),
+	)
+
+	// Keys too short to hold their max chunks are rejected when unmarshaling
+	action.Keys = StateKeysWithPermissions{"\x01": state.Read}
+	p := codec.NewWriter(action.Size(), action.Size())
+	action.Marshal(p)
+	require.NoError(p.Err())
+	_, err := UnmarshalExecuteContract(codec.NewReader(p.Bytes(), len(p.Bytes())))
+	require.ErrorIs(err, chain.ErrInvalidKeyValue)
+}
@@ -5,4 +5,14 @@ package actions
 
 import "errors"
 
-var ErrOutputValueZero = errors.New("value is zero")
+var (
+	ErrOutputValueZero    = errors.New("value is zero")
+	ErrNotContractAdmin   = errors.New("actor is not the contract admin")
+	ErrContractImmutable  = errors.New("contract is immutable")
+	ErrEmptyUpgrade       = errors.New("upgrade changes nothing")
+	ErrContractTooLarge   = errors.New("contract bytecode is too large")
+	ErrContractInitFailed = errors.New("contract init failed")
+	ErrABITooLarge        = errors.New("contract abi is too large")
+	ErrABIWithoutBytecode = errors.New("abi can only be upgraded with the bytecode")
+	ErrNotAuthorized      = errors.New("contract did not authorize the transaction")
+)
@@ -0,0 +1,137 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+)
+
+var _ chain.Action = (*UpgradeContract)(nil)
+
+// UpgradeContract lets the admin of a contract replace its bytecode, keeping
+// its address and state, and hand over or give up its administration.
+type UpgradeContract struct {
+	ContractAddress codec.Address `json:"contractAddress"`
+
+	// Bytecode replaces the code of the contract. If empty, the code is kept.
+	Bytecode []byte `json:"bytecode"`
+
+	// Admin becomes the new admin of the contract. If empty, the admin is kept.
+	Admin codec.Address `json:"admin"`
+
+	// Immutable permanently prevents further upgrades, after applying this one.
+	Immutable bool `json:"immutable"`
+
+	// ABI replaces the ABI of the contract together with [Bytecode], which it
+	// describes. If empty, upgrading the bytecode removes the ABI.
+	ABI []byte `json:"abi"`
+}
+
+func (*UpgradeContract) GetTypeID() uint8 {
+	return mconsts.UpgradeContractID
+}
+
+func (uc *UpgradeContract) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.ContractBytecodeKey(uc.ContractAddress)): state.Read | state.Write,
+		string(storage.ContractMetadataKey(uc.ContractAddress)): state.Read | state.Write,
+		string(storage.ContractABIKey(uc.ContractAddress)):      state.All,
+	}
+}
+
+func (*UpgradeContract) StateKeysMaxChunks() []uint16 {
+	return []uint16{storage.ContractBytecodeChunks, storage.ContractMetadataChunks, storage.ContractABIChunks}
+}
+
+func (uc *UpgradeContract) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	mu state.Mutable,
+	_ int64,
+	actor codec.Address,
+	_ ids.ID,
+) ([][]byte, error) {
+	if len(uc.Bytecode) == 0 && uc.Admin == codec.EmptyAddress && !uc.Immutable {
+		return nil, ErrEmptyUpgrade
+	}
+	if len(uc.Bytecode) == 0 && len(uc.ABI) > 0 {
+		return nil, ErrABIWithoutBytecode
+	}
+
+	md, err := storage.GetContractMetadata(ctx, mu, uc.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if md.Admin != actor {
+		return nil, ErrNotContractAdmin
+	}
+	if md.Immutable {
+		return nil, ErrContractImmutable
+	}
+
+	if uc.Admin != codec.EmptyAddress {
+		md.Admin = uc.Admin
+	}
+	md.Immutable = uc.Immutable
+	if len(uc.Bytecode) > 0 {
+		if err := validateBytecode(r, uc.Bytecode); err != nil {
+			return nil, err
+		}
+		if err := validateABI(uc.ABI); err != nil {
+			return nil, err
+		}
+		if err := storage.SetContractABI(ctx, mu, uc.ContractAddress, uc.ABI); err != nil {
+			return nil, err
+		}
+		if err := storage.UpgradeContract(ctx, mu, uc.ContractAddress, md, uc.Bytecode); err != nil {
+			return nil, err
+		}
+	} else if err := storage.SetContractMetadata(ctx, mu, uc.ContractAddress, md); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (uc *UpgradeContract) ComputeUnits(chain.Rules) uint64 {
+	return UpgradeContractComputeUnits + bytecodeComputeUnits(uc.Bytecode) + bytecodeComputeUnits(uc.ABI)
+}
+
+func (uc *UpgradeContract) Size() int {
+	return codec.AddressLen + codec.BytesLen(uc.Bytecode) + codec.AddressLen + consts.BoolLen + codec.BytesLen(uc.ABI)
+}
+
+func (uc *UpgradeContract) Marshal(p *codec.Packer) {
+	p.PackAddress(uc.ContractAddress)
+	p.PackBytes(uc.Bytecode)
+	p.PackFixedBytes(uc.Admin[:])
+	p.PackBool(uc.Immutable)
+	p.PackBytes(uc.ABI)
+}
+
+func UnmarshalUpgradeContract(p *codec.Packer) (chain.Action, error) {
+	var action UpgradeContract
+	p.UnpackAddress(&action.ContractAddress)
+	p.UnpackBytes(-1, false, &action.Bytecode)
+
+	// The admin is optional, [codec.Packer.UnpackAddress] requires it
+	admin := make([]byte, codec.AddressLen)
+	p.UnpackFixedBytes(codec.AddressLen, &admin)
+	copy(action.Admin[:], admin)
+
+	action.Immutable = p.UnpackBool()
+	p.UnpackBytes(storage.MaxContractABISize, false, &action.ABI)
+	return &action, p.Err()
+}
+
+func (*UpgradeContract) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
@@ -0,0 +1,91 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/tstate"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/genesis"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+)
+
+func TestUpgradeContract(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	var (
+		deployer = codec.Address{0x00, 0x01}
+		admin    = codec.Address{0x00, 0x02}
+		ts       = tstate.New(10)
+		rules    = genesis.Default().Rules(0, 0, ids.Empty)
+	)
+	run := func(action chain.Action, actor codec.Address) error {
+		view := ts.NewView(action.StateKeys(actor, ids.Empty), map[string][]byte{})
+		if _, err := action.Execute(ctx, rules, view, 0, actor, ids.Empty); err != nil {
+			return err
+		}
+		view.Commit()
+		return nil
+	}
+	metadata := func(addr codec.Address) *storage.ContractMetadata {
+		view := ts.NewView((&UpgradeContract{ContractAddress: addr}).StateKeys(codec.EmptyAddress, ids.Empty), map[string][]byte{})
+		md, err := storage.GetContractMetadata(ctx, view, addr)
+		require.NoError(err)
+		return md
+	}
+	storedABI := func(addr codec.Address) []byte {
+		view := ts.NewView((&UpgradeContract{ContractAddress: addr}).StateKeys(codec.EmptyAddress, ids.Empty), map[string][]byte{})
+		v, err := view.GetValue(ctx, storage.ContractABIKey(addr))
+		if errors.Is(err, database.ErrNotFound) {
+			return nil
+		}
+		require.NoError(err)
+		return v
+	}
+
+	require.NoError(run(&CreateContract{Bytecode: javyModule(t, 1)}, deployer))
+	contract := storage.GenerateContractAddress(deployer, 0)
+	require.Equal(&storage.ContractMetadata{
+		Admin:    deployer,
+		Version:  1,
+		CodeHash: storage.ContractCodeHash(javyModule(t, 1)),
+	}, metadata(contract))
+
+	require.ErrorIs(run(&UpgradeContract{ContractAddress: contract, Bytecode: javyModule(t, 2)}, admin), ErrNotContractAdmin)
+	require.ErrorIs(run(&UpgradeContract{ContractAddress: contract}, deployer), ErrEmptyUpgrade)
+
+	// Hand over the administration together with an upgrade
+	require.NoError(run(&UpgradeContract{ContractAddress: contract, Bytecode: javyModule(t, 2), Admin: admin}, deployer))
+	require.Equal(&storage.ContractMetadata{
+		Admin:    admin,
+		Version:  2,
+		CodeHash: storage.ContractCodeHash(javyModule(t, 2)),
+	}, metadata(contract))
+	require.ErrorIs(run(&UpgradeContract{ContractAddress: contract, Bytecode: javyModule(t, 3)}, deployer), ErrNotContractAdmin)
+
+	// The ABI describes the bytecode, it is only replaced together with it
+	contractABI := []byte(`{"functions":[{"name":"v3","readOnly":true}]}`)
+	require.ErrorIs(run(&UpgradeContract{ContractAddress: contract, Admin: admin, ABI: contractABI}, admin), ErrABIWithoutBytecode)
+	require.NoError(run(&UpgradeContract{ContractAddress: contract, Bytecode: javyModule(t, 3), ABI: contractABI}, admin))
+	require.Equal(contractABI, storedABI(contract))
+	require.NoError(run(&UpgradeContract{ContractAddress: contract, Bytecode: javyModule(t, 4)}, admin))
+	require.Nil(storedABI(contract))
+
+	// Renounce further upgrades
+	require.NoError(run(&UpgradeContract{ContractAddress: contract, Immutable: true}, admin))
+	require.True(metadata(contract).Immutable)
+	require.ErrorIs(run(&UpgradeContract{ContractAddress: contract, Bytecode: javyModule(t, 3)}, admin), ErrContractImmutable)
+
+	// Contracts may be immutable from the start
+	require.NoError(run(&CreateContract{Bytecode: javyModule(t, 1), Discriminator: 1, Immutable: true}, deployer))
+	immutable := storage.GenerateContractAddress(deployer, 1)
+	require.ErrorIs(run(&UpgradeContract{ContractAddress: immutable, Bytecode: javyModule(t, 2)}, deployer), ErrContractImmutable)
+}
@@ -94,7 +94,7 @@ func UnmarshalBLS(p *codec.Packer) (chain.Auth, error) {
 	return &b, p.Err()
 }
 
-var _ chain.AuthFactory = (*BLSFactory)(nil)
+var _ chain.UnsignedAuthFactory = (*BLSFactory)(nil)
 
 type BLSFactory struct {
 	priv *bls.PrivateKey
@@ -108,6 +108,17 @@ func (b *BLSFactory) Sign(msg []byte) (chain.Auth, error) {
 	return &BLS{Signer: bls.PublicFromPrivateKey(b.priv), Signature: bls.Sign(msg, b.priv)}, nil
 }
 
+// Unsigned uses the point at infinity, which parses like any signature.
+func (b *BLSFactory) Unsigned() (chain.Auth, error) {
+	infinity := make([]byte, bls.SignatureLen)
+	infinity[0] = 0xc0
+	sig, err := bls.SignatureFromBytes(infinity)
+	if err != nil {
+		return nil, err
+	}
+	return &BLS{Signer: bls.PublicFromPrivateKey(b.priv), Signature: sig}, nil
+}
+
 func (*BLSFactory) MaxUnits() (uint64, uint64) {
 	return BLSSize, BLSComputeUnits
 }
@@ -0,0 +1,373 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+
+	hconsts "github.com/ava-labs/hypersdk/consts"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/abi"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+)
+
+var (
+	_ chain.StatefulAuth        = (*SmartContract)(nil)
+	_ chain.StatefulAuthFactory = (*SmartContractFactory)(nil)
+	_ chain.UnsignedAuthFactory = (*SmartContractFactory)(nil)
+)
+
+const (
+	SmartContractComputeUnits = 1
+
+	// AuthorizeFunction is the function of a contract deciding whether a
+	// transaction may act on its behalf.
+	AuthorizeFunction = "authorize"
+
+	// MaxAuthorizeComputeUnits bounds the compute units [AuthorizeFunction]
+	// may use. Transactions it rejects are dropped before paying any fee, so
+	// this bounds the unpaid work of nodes. Accepted transactions are charged
+	// for them.
+	MaxAuthorizeComputeUnits = 20
+
+	MaxSmartContractSigners = 16
+	MaxSmartContractKeys    = 16
+	MaxSmartContractKeySize = 64
+	MaxSmartContractData    = 1024
+)
+
+var (
+	ErrNotContract     = errors.New("actor is not a contract")
+	ErrDuplicateSigner = errors.New("duplicate signer")
+	ErrUnsupportedAuth = errors.New("unsupported signer auth")
+	ErrTooManySigners  = errors.New("too many signers")
+	ErrTooManyKeys     = errors.New("too many keys")
+	ErrTooMuchFuel     = errors.New("too many compute units to authorize")
+	ErrNoAuthorize     = errors.New("contract does not export " + AuthorizeFunction)
+)
+
+// AuthorizeArgs are the arguments of [AuthorizeFunction]: the concatenated
+// addresses of the signers that signed the transaction and the data of the
+// auth, for instance to select a session key.
+var AuthorizeArgs = []abi.Param{
+	{Name: "signers", Type: abi.Bytes},
+	{Name: "data", Type: abi.Bytes},
+}
+
+// SmartContract lets a contract act and pay fees. Its [Signers] are verified
+// like any other auth, and the contract then decides, by running
+// [AuthorizeFunction] read-only, whether they may act on its behalf. This
+// allows multisigs, session keys or social recovery to be written as
+// contracts.
+type SmartContract struct {
+	Contract codec.Address `json:"contract"`
+
+	// Keys are the slots [AuthorizeFunction] may read, suffixed with their
+	// chunks like the keys of [actions.ExecuteContract]
+	Keys [][]byte `json:"stateKeys"`
+
+	// ComputeUnitsToSpend is the fuel budget of [AuthorizeFunction]
+	ComputeUnitsToSpend uint64 `json:"computeUnitsToSpend"`
+
+	Data    []byte       `json:"data"`
+	Signers []chain.Auth `json:"signers"`
+}
+
+func (*SmartContract) GetTypeID() uint8 {
+	return consts.SMARTCONTRACTID
+}
+
+func (s *SmartContract) ComputeUnits(r chain.Rules) uint64 {
+	units := SmartContractComputeUnits + s.ComputeUnitsToSpend
+	for _, signer := range s.Signers {
+		units += signer.ComputeUnits(r)
+	}
+	return units
+}
+
+func (*SmartContract) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+// Verify checks the signatures of every signer. Whether they may act for the
+// contract is only known from its state, see [Authorize].
+func (s *SmartContract) Verify(ctx context.Context, msg []byte) error {
+	signers := set.NewSet[codec.Address](len(s.Signers))
+	for _, signer := range s.Signers {
+		if signers.Contains(signer.Actor()) {
+			return fmt.Errorf("%w: %s", ErrDuplicateSigner, codec.MustAddressBech32(consts.HRP, signer.Actor()))
+		}
+		signers.Add(signer.Actor())
+		if err := signer.Verify(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SmartContract) StateKeys() state.Keys {
+	// The bytecode and the ABI of the contract are declared with its balance, see
+	// [storage.StateManager.SponsorStateKeys]
+	stateKeys := make(state.Keys, len(s.Keys))
+	for _, k := range s.Keys {
+		stateKeys[string(storage.ContractStateKey(s.Contract, k))] = state.Read
+	}
+	return stateKeys
+}
+
+// Authorize runs [AuthorizeFunction] of the contract, which authorizes the
+// transaction by returning successfully. Contracts that are not deployed or
+// don't export [AuthorizeFunction] are rejected without running.
+func (s *SmartContract) Authorize(ctx context.Context, r chain.Rules, im state.Immutable, timestamp int64) error {
+	if _, err := storage.GetContractBytecode(ctx, im, s.Contract); err != nil {
+		return err
+	}
+	b, err := storage.GetContractABI(ctx, im, s.Contract)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return ErrNoAuthorize
+	}
+	contractABI, err := abi.Parse(b)
+	if err != nil {
+		return err
+	}
+	if _, err := contractABI.Function(AuthorizeFunction); err != nil {
+		return fmt.Errorf("%w: %w", ErrNoAuthorize, err)
+	}
+
+	signers := make([]byte, 0, len(s.Signers)*codec.AddressLen)
+	for _, signer := range s.Signers {
+		actor := signer.Actor()
+		signers = append(signers, actor[:]...)
+	}
+	payload, err := abi.Encode(AuthorizeArgs, []any{signers, s.Data})
+	if err != nil {
+		return err
+	}
+	contractKeys := make(actions.StateKeysWithPermissions, len(s.Keys))
+	for _, k := range s.Keys {
+		contractKeys[string(k)] = state.Read
+	}
+	return actions.AuthorizeContract(
+		ctx,
+		im,
+		r.ChainID(),
+		timestamp,
+		s.Contract,
+		contractKeys,
+		AuthorizeFunction,
+		payload,
+		s.ComputeUnitsToSpend*actions.ExecuteContractFuelPerComputeUnit,
+	)
+}
+
+func (s *SmartContract) Actor() codec.Address {
+	return s.Contract
+}
+
+func (s *SmartContract) Sponsor() codec.Address {
+	return s.Contract
+}
+
+func (s *SmartContract) Size() int {
+	size := codec.AddressLen + hconsts.IntLen + hconsts.Uint64Len + codec.BytesLen(s.Data) + hconsts.ByteLen
+	for _, k := range s.Keys {
+		size += codec.BytesLen(k)
+	}
+	for _, signer := range s.Signers {
+		size += hconsts.ByteLen + signer.Size()
+	}
+	return size
+}
+
+func (s *SmartContract) Marshal(p *codec.Packer) {
+	p.PackAddress(s.Contract)
+	p.PackInt(len(s.Keys))
+	for _, k := range s.Keys {
+		p.PackBytes(k)
+	}
+	p.PackUint64(s.ComputeUnitsToSpend)
+	p.PackBytes(s.Data)
+	p.PackByte(uint8(len(s.Signers)))
+	for _, signer := range s.Signers {
+		p.PackByte(signer.GetTypeID())
+		signer.Marshal(p)
+	}
+}
+
+func UnmarshalSmartContract(p *codec.Packer) (chain.Auth, error) {
+	var s SmartContract
+	p.UnpackAddress(&s.Contract)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if s.Contract[0] != consts.SMARTCONTRACTID {
+		return nil, ErrNotContract
+	}
+	numKeys := p.UnpackInt(false)
+	if numKeys > MaxSmartContractKeys {
+		return nil, ErrTooManyKeys
+	}
+	if numKeys > 0 {
+		s.Keys = make([][]byte, numKeys)
+	}
+	for i := range s.Keys {
+		p.UnpackBytes(MaxSmartContractKeySize, true, &s.Keys[i])
+		if !keys.Valid(string(s.Keys[i])) {
+			return nil, chain.ErrInvalidKeyValue
+		}
+	}
+	s.ComputeUnitsToSpend = p.UnpackUint64(false)
+	if s.ComputeUnitsToSpend > MaxAuthorizeComputeUnits {
+		return nil, fmt.Errorf("%w: %d (max %d)", ErrTooMuchFuel, s.ComputeUnitsToSpend, MaxAuthorizeComputeUnits)
+	}
+	p.UnpackBytes(MaxSmartContractData, false, &s.Data)
+	numSigners := int(p.UnpackByte())
+	if numSigners > MaxSmartContractSigners {
+		return nil, ErrTooManySigners
+	}
+	if numSigners > 0 {
+		s.Signers = make([]chain.Auth, numSigners)
+	}
+	for i := range s.Signers {
+		signer, err := unmarshalSigner(p)
+		if err != nil {
+			return nil, err
+		}
+		s.Signers[i] = signer
+	}
+	return &s, p.Err()
+}
+
+// unmarshalSigner decodes a signature auth. Contracts cannot sign for other
+// contracts.
+func unmarshalSigner(p *codec.Packer) (chain.Auth, error) {
+	typeID := p.UnpackByte()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	switch typeID {
+	case consts.ED25519ID:
+		return UnmarshalED25519(p)
+	case consts.SECP256R1ID:
+		return UnmarshalSECP256R1(p)
+	case consts.BLSID:
+		return UnmarshalBLS(p)
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedAuth, typeID)
+	}
+}
+
+type SmartContractFactory struct {
+	contract     codec.Address
+	keys         [][]byte
+	computeUnits uint64
+	data         []byte
+	signers      []chain.AuthFactory
+}
+
+// NewSmartContractFactory signs transactions for [contract] with [signers],
+// which must use signature auths. [stateKeys] and [computeUnits] are the slots
+// and the fuel budget of [AuthorizeFunction], which are checked like nodes
+// would.
+func NewSmartContractFactory(
+	contract codec.Address,
+	stateKeys [][]byte,
+	computeUnits uint64,
+	data []byte,
+	signers ...chain.AuthFactory,
+) (*SmartContractFactory, error) {
+	if len(stateKeys) > MaxSmartContractKeys {
+		return nil, ErrTooManyKeys
+	}
+	for _, k := range stateKeys {
+		if len(k) > MaxSmartContractKeySize || !keys.Valid(string(k)) {
+			return nil, fmt.Errorf("%w: %x", chain.ErrInvalidKeyValue, k)
+		}
+	}
+	if computeUnits > MaxAuthorizeComputeUnits {
+		return nil, fmt.Errorf("%w: %d (max %d)", ErrTooMuchFuel, computeUnits, MaxAuthorizeComputeUnits)
+	}
+	if len(signers) > MaxSmartContractSigners {
+		return nil, ErrTooManySigners
+	}
+	return &SmartContractFactory{contract, stateKeys, computeUnits, data, signers}, nil
+}
+
+func (s *SmartContractFactory) Sign(msg []byte) (chain.Auth, error) {
+	signers := make([]chain.Auth, len(s.signers))
+	for i, factory := range s.signers {
+		signer, err := factory.Sign(msg)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = signer
+	}
+	return &SmartContract{
+		Contract:            s.contract,
+		Keys:                s.keys,
+		ComputeUnitsToSpend: s.computeUnits,
+		Data:                s.data,
+		Signers:             signers,
+	}, nil
+}
+
+// Unsigned requires every signer to be a [chain.UnsignedAuthFactory].
+func (s *SmartContractFactory) Unsigned() (chain.Auth, error) {
+	signers := make([]chain.Auth, len(s.signers))
+	for i, factory := range s.signers {
+		unsigned, ok := factory.(chain.UnsignedAuthFactory)
+		if !ok {
+			return nil, fmt.Errorf("%w: signer %d cannot be simulated", ErrUnsupportedAuth, i)
+		}
+		signer, err := unsigned.Unsigned()
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = signer
+	}
+	return &SmartContract{
+		Contract:            s.contract,
+		Keys:                s.keys,
+		ComputeUnitsToSpend: s.computeUnits,
+		Data:                s.data,
+		Signers:             signers,
+	}, nil
+}
+
+func (s *SmartContractFactory) MaxUnits() (uint64, uint64) {
+	bandwidth := (&SmartContract{Keys: s.keys, Data: s.data}).Size()
+	compute := SmartContractComputeUnits + s.computeUnits
+	for _, factory := range s.signers {
+		signerBandwidth, signerCompute := factory.MaxUnits()
+		bandwidth += hconsts.ByteLen + int(signerBandwidth)
+		compute += signerCompute
+	}
+	return uint64(bandwidth), compute
+}
+
+// StateKeysMaxChunks covers the bytecode and the ABI read to authorize
+// transactions as well as the declared slots.
+func (s *SmartContractFactory) StateKeysMaxChunks() []uint16 {
+	output := make([]uint16, 0, 3+len(s.keys))
+	output = append(output, storage.ContractBytecodeChunks, storage.ContractABIChunks, chain.HeightKeyChunks)
+	for _, k := range s.keys {
+		maxChunks, _ := keys.MaxChunks(k) // checked by [NewSmartContractFactory]
+		output = append(output, maxChunks)
+	}
+	return output
+}
@@ -0,0 +1,330 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/bytecodealliance/wasmtime-go/v21"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/crypto/bls"
+	"github.com/ava-labs/hypersdk/crypto/ed25519"
+	"github.com/ava-labs/hypersdk/crypto/secp256r1"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/tstate"
+
+	hconsts "github.com/ava-labs/hypersdk/consts"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/genesis"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+)
+
+const (
+	testTimestamp = 1_700_000_000_000
+	testBalance   = 10_000_000
+	testTransfer  = 1_000
+)
+
+var (
+	testChainID   = ids.GenerateTestID()
+	testRecipient = codec.Address{consts.ED25519ID, 0x01}
+
+	authorizeABI = fmt.Sprintf(`{"functions":[{"name":%q}]}`, AuthorizeFunction)
+)
+
+type memoryState map[string][]byte
+
+func (m memoryState) GetValue(_ context.Context, key []byte) ([]byte, error) {
+	if v, ok := m[string(key)]; ok {
+		return v, nil
+	}
+	return nil, database.ErrNotFound
+}
+
+func (m memoryState) Insert(_ context.Context, key []byte, value []byte) error {
+	m[string(key)] = bytes.Clone(value)
+	return nil
+}
+
+func (m memoryState) Remove(_ context.Context, key []byte) error {
+	delete(m, string(key))
+	return nil
+}
+
+// contractWasm builds a contract running [body] and then writing [output] to
+// stdout.
+func contractWasm(t *testing.T, body string, output string) []byte {
+	t.Helper()
+
+	var escaped strings.Builder
+	for _, c := range []byte(output) {
+		fmt.Fprintf(&escaped, "\\%02x", c)
+	}
+	wasm, err := wasmtime.Wat2Wasm(fmt.Sprintf(`(module
+  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 1024) "%s")
+  (func (export "_start")%s
+    (i32.store (i32.const 0) (i32.const 1024))
+    (i32.store (i32.const 4) (i32.const %d))
+    (drop (call $fd_write (i32.const 1) (i32.const 0) (i32.const 1) (i32.const 8)))))`,
+		escaped.String(), body, len(output)))
+	require.NoError(t, err)
+	return wasm
+}
+
+// newContract deploys [bytecode] with [contractABI] and funds it.
+func newContract(t *testing.T, mu memoryState, bytecode []byte, contractABI string) codec.Address {
+	t.Helper()
+	require := require.New(t)
+	ctx := context.Background()
+
+	contract := codec.CreateAddress(consts.SMARTCONTRACTID, ids.GenerateTestID())
+	if bytecode != nil {
+		require.NoError(mu.Insert(ctx, storage.ContractBytecodeKey(contract), bytecode))
+	}
+	if contractABI != "" {
+		require.NoError(storage.SetContractABI(ctx, mu, contract, []byte(contractABI)))
+	}
+	require.NoError(storage.SetBalance(ctx, mu, contract, testBalance))
+	return contract
+}
+
+// executeTx runs a transfer paid by [contract] and signed by [signers] like a
+// block would, and returns its result and the balance of [contract]
+// afterwards. Transactions dropped by [chain.Transaction.PreExecute] return
+// its error.
+func executeTx(t *testing.T, mu memoryState, contract codec.Address, signers ...chain.AuthFactory) (*chain.Result, uint64, error) {
+	t.Helper()
+	require := require.New(t)
+	ctx := context.Background()
+
+	tx := chain.NewTx(
+		&chain.Base{Timestamp: testTimestamp, ChainID: testChainID, MaxFee: testBalance},
+		[]chain.Action{&actions.Transfer{To: testRecipient, Value: testTransfer}},
+	)
+	msg, err := tx.Digest()
+	require.NoError(err)
+	factory, err := NewSmartContractFactory(contract, nil, MaxAuthorizeComputeUnits, nil, signers...)
+	require.NoError(err)
+	tx.Auth, err = factory.Sign(msg)
+	require.NoError(err)
+	require.NoError(tx.Auth.Verify(ctx, msg))
+
+	feeManager := fees.NewManager(nil)
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		feeManager.SetUnitPrice(i, 1)
+	}
+	sm := &storage.StateManager{}
+	r := genesis.Default().Rules(testTimestamp, 1337, testChainID)
+	stateKeys, err := tx.StateKeys(sm)
+	require.NoError(err)
+	tsv := tstate.New(len(stateKeys)).NewView(stateKeys, mu)
+	if err := tx.PreExecute(ctx, feeManager, sm, r, tsv, testTimestamp); err != nil {
+		return nil, 0, err
+	}
+	result, err := tx.Execute(ctx, feeManager, sm, r, tsv, testTimestamp, false)
+	require.NoError(err)
+	balance, err := storage.GetBalance(ctx, tsv, contract)
+	require.NoError(err)
+	return result, balance, nil
+}
+
+func TestSmartContractSponsor(t *testing.T) {
+	require := require.New(t)
+
+	mu := memoryState{}
+	require.NoError(mu.Insert(context.Background(), storage.ChainHeightKey(), binary.BigEndian.AppendUint64(nil, 7)))
+	contract := newContract(t, mu, contractWasm(t, "", `{"success":true,"result":""}`), authorizeABI)
+
+	result, balance, err := executeTx(t, mu, contract)
+	require.NoError(err)
+	require.True(result.Success, string(result.Error))
+	require.GreaterOrEqual(result.Units[fees.Compute], uint64(MaxAuthorizeComputeUnits))
+	require.NotZero(result.Fee)
+	require.Equal(testBalance-testTransfer-result.Fee, balance)
+}
+
+func TestSmartContractAuthorizeRejected(t *testing.T) {
+	bytecode := contractWasm(t, "", `{"success":true,"result":""}`)
+	tests := []struct {
+		name     string
+		bytecode []byte
+		abi      string
+		err      error
+		error    string
+	}{
+		{
+			name:     "rejected",
+			bytecode: contractWasm(t, "", `{"success":false,"error":"unknown signer"}`),
+			abi:      authorizeABI,
+			err:      actions.ErrNotAuthorized,
+			error:    "unknown signer",
+		},
+		{
+			name: "out of fuel",
+			bytecode: contractWasm(t, `
+    (loop $spin (br $spin))`, `{"success":true,"result":""}`),
+			abi:   authorizeABI,
+			error: "all fuel consumed",
+		},
+		{
+			name: "not deployed",
+			abi:  authorizeABI,
+			err:  database.ErrNotFound,
+		},
+		{
+			name:     "no abi",
+			bytecode: bytecode,
+			err:      ErrNoAuthorize,
+		},
+		{
+			name:     "authorize not exported",
+			bytecode: bytecode,
+			abi:      `{"functions":[{"name":"transfer"}]}`,
+			err:      ErrNoAuthorize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			// Transactions are dropped without paying any fee
+			mu := memoryState{}
+			require.NoError(mu.Insert(ctx, storage.ChainHeightKey(), binary.BigEndian.AppendUint64(nil, 7)))
+			contract := newContract(t, mu, tt.bytecode, tt.abi)
+			_, _, err := executeTx(t, mu, contract)
+			require.ErrorIs(err, chain.ErrAuthFailed)
+			if tt.err != nil {
+				require.ErrorIs(err, tt.err)
+			}
+			require.ErrorContains(err, tt.error)
+			balance, err := storage.GetBalance(ctx, mu, contract)
+			require.NoError(err)
+			require.Equal(uint64(testBalance), balance)
+		})
+	}
+
+	// Sponsors that cannot pay are dropped first
+	require := require.New(t)
+	mu := memoryState{}
+	contract := newContract(t, mu, bytecode, authorizeABI)
+	require.NoError(storage.SetBalance(context.Background(), mu, contract, 1))
+	_, _, err := executeTx(t, mu, contract)
+	require.ErrorIs(err, storage.ErrInvalidBalance)
+}
+
+func TestSmartContractUnrelatedSigner(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	mu := memoryState{}
+	require.NoError(mu.Insert(ctx, storage.ChainHeightKey(), binary.BigEndian.AppendUint64(nil, 7)))
+	contract := newContract(t, mu, contractWasm(t, "", `{"success":false,"error":"unknown signer"}`), authorizeABI)
+
+	// Anyone can sign for any contract, which must not let them spend its
+	// balance on transactions it rejects
+	priv, err := ed25519.GeneratePrivateKey()
+	require.NoError(err)
+	for i := 0; i < 3; i++ {
+		_, _, err := executeTx(t, mu, contract, NewED25519Factory(priv))
+		require.ErrorIs(err, chain.ErrAuthFailed)
+		require.ErrorIs(err, actions.ErrNotAuthorized)
+	}
+	balance, err := storage.GetBalance(ctx, mu, contract)
+	require.NoError(err)
+	require.Equal(uint64(testBalance), balance)
+}
+
+func TestSmartContractFactoryUnsigned(t *testing.T) {
+	require := require.New(t)
+
+	edPriv, err := ed25519.GeneratePrivateKey()
+	require.NoError(err)
+	secpPriv, err := secp256r1.GeneratePrivateKey()
+	require.NoError(err)
+	blsPriv, err := bls.GeneratePrivateKey()
+	require.NoError(err)
+	factory, err := NewSmartContractFactory(
+		codec.CreateAddress(consts.SMARTCONTRACTID, ids.GenerateTestID()),
+		[][]byte{{1, 0, 1}},
+		MaxAuthorizeComputeUnits,
+		[]byte("data"),
+		NewED25519Factory(edPriv),
+		NewSECP256R1Factory(secpPriv),
+		NewBLSFactory(blsPriv),
+	)
+	require.NoError(err)
+
+	// Unsigned auths are charged like signed ones, and are parsed by the
+	// nodes simulating them
+	signed, err := factory.Sign([]byte("msg"))
+	require.NoError(err)
+	unsigned, err := factory.Unsigned()
+	require.NoError(err)
+	require.Equal(signed.Size(), unsigned.Size())
+	require.Equal(signed.Actor(), unsigned.Actor())
+	for i, signer := range unsigned.(*SmartContract).Signers {
+		require.Equal(signed.(*SmartContract).Signers[i].Actor(), signer.Actor())
+	}
+
+	p := codec.NewWriter(unsigned.Size(), hconsts.MaxInt)
+	unsigned.Marshal(p)
+	require.NoError(p.Err())
+	parsed, err := UnmarshalSmartContract(codec.NewReader(p.Bytes(), hconsts.MaxInt))
+	require.NoError(err)
+	require.Equal(unsigned.Actor(), parsed.Actor())
+	require.ErrorIs(parsed.Verify(context.Background(), []byte("msg")), crypto.ErrInvalidSignature)
+}
+
+func TestSmartContractFactoryInvalid(t *testing.T) {
+	contract := codec.CreateAddress(consts.SMARTCONTRACTID, ids.GenerateTestID())
+	tests := []struct {
+		name         string
+		keys         [][]byte
+		computeUnits uint64
+		err          error
+	}{
+		{
+			name: "key without chunks",
+			keys: [][]byte{{1}},
+			err:  chain.ErrInvalidKeyValue,
+		},
+		{
+			name: "key too long",
+			keys: [][]byte{make([]byte, MaxSmartContractKeySize+1)},
+			err:  chain.ErrInvalidKeyValue,
+		},
+		{
+			name: "too many keys",
+			keys: make([][]byte, MaxSmartContractKeys+1),
+			err:  ErrTooManyKeys,
+		},
+		{
+			name:         "too much fuel",
+			computeUnits: MaxAuthorizeComputeUnits + 1,
+			err:          ErrTooMuchFuel,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSmartContractFactory(contract, tt.keys, tt.computeUnits, nil)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -80,7 +80,7 @@ func UnmarshalED25519(p *codec.Packer) (chain.Auth, error) {
 	return &d, p.Err()
 }
 
-var _ chain.AuthFactory = (*ED25519Factory)(nil)
+var _ chain.UnsignedAuthFactory = (*ED25519Factory)(nil)
 
 func NewED25519Factory(priv ed25519.PrivateKey) *ED25519Factory {
 	return &ED25519Factory{priv}
@@ -95,6 +95,10 @@ func (d *ED25519Factory) Sign(msg []byte) (chain.Auth, error) {
 	return &ED25519{Signer: d.priv.PublicKey(), Signature: sig}, nil
 }
 
+func (d *ED25519Factory) Unsigned() (chain.Auth, error) {
+	return &ED25519{Signer: d.priv.PublicKey()}, nil
+}
+
 func (*ED25519Factory) MaxUnits() (uint64, uint64) {
 	return ED25519Size, ED25519ComputeUnits
 }
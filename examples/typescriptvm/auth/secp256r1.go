@@ -80,7 +80,7 @@ func UnmarshalSECP256R1(p *codec.Packer) (chain.Auth, error) {
 	return &d, p.Err()
 }
 
-var _ chain.AuthFactory = (*SECP256R1Factory)(nil)
+var _ chain.UnsignedAuthFactory = (*SECP256R1Factory)(nil)
 
 type SECP256R1Factory struct {
 	priv secp256r1.PrivateKey
@@ -98,6 +98,10 @@ func (d *SECP256R1Factory) Sign(msg []byte) (chain.Auth, error) {
 	return &SECP256R1{Signer: d.priv.PublicKey(), Signature: sig}, nil
 }
 
+func (d *SECP256R1Factory) Unsigned() (chain.Auth, error) {
+	return &SECP256R1{Signer: d.priv.PublicKey()}, nil
+}
+
 func (*SECP256R1Factory) MaxUnits() (uint64, uint64) {
 	return SECP256R1Size, SECP256R1ComputeUnits
 }
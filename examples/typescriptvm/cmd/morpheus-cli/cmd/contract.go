@@ -0,0 +1,519 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/cli"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/abi"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/runtime"
+	"github.com/ava-labs/hypersdk/rpc"
+	"github.com/ava-labs/hypersdk/utils"
+
+	brpc "github.com/ava-labs/hypersdk/examples/typescriptvm/rpc"
+)
+
+var (
+	contractDiscriminator uint16
+	contractImmutable     bool
+	contractHexPayload    bool
+	contractValue         string
+	contractChunks        uint16
+	contractCodeOutput    string
+	contractShowDebugLog  bool
+	contractABIFile       string
+	contractHeight        uint64
+)
+
+// Limits of the local run exporting the ABI of a contract before deploying it
+const (
+	abiExportFuel   = 1_000_000_000
+	abiExportTime   = 10 * time.Second
+	abiExportMemory = 10 * 1024 * 1024
+)
+
+var contractCmd = &cobra.Command{
+	Use: "contract",
+	RunE: func(*cobra.Command, []string) error {
+		return ErrMissingSubcommand
+	},
+}
+
+var deployContractCmd = &cobra.Command{
+	Use:   "deploy <file.wasm|file.js|file.ts>",
+	Short: "Deploy a contract, compiling JS and TypeScript sources first",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+		bytecode, err := runtime.CompileFile(args[0])
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}bytecode size:{{/}} %d bytes\n", len(bytecode))
+		contractABI, err := loadABI(bytecode)
+		if err != nil {
+			return err
+		}
+		if contractABI != nil {
+			utils.Outf("{{yellow}}abi size:{{/}} %d bytes\n", len(contractABI))
+		}
+
+		_, priv, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		balance, err := handler.GetBalance(ctx, bcli, priv.Address)
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		result, _, err := sendAndWait(ctx, []chain.Action{&actions.CreateContract{
+			Bytecode:      bytecode,
+			Discriminator: contractDiscriminator,
+			Immutable:     contractImmutable,
+			ABI:           contractABI,
+		}}, cli, bcli, ws, factory, true)
+		if err != nil {
+			return err
+		}
+		if !result.Success {
+			utils.Outf("{{red}}error:{{/}} %s\n", result.Error)
+			return nil
+		}
+		utils.Outf("{{yellow}}contract:{{/}} %s\n", result.Outputs[0][0])
+		return nil
+	},
+}
+
+var callContractCmd = &cobra.Command{
+	Use:   "call <address> <function> [payload|args...]",
+	Short: "Simulate a contract call to fill in its keys and compute units, then send it",
+	Long: "Simulate a contract call to fill in its keys and compute units, then send it. " +
+		"If the contract has an ABI, arguments are encoded and results decoded with it, " +
+		"and read-only functions are queried as views rather than sent.",
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+		_, priv, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		f, payload, value, err := parseCallArgs(ctx, bcli, args)
+		if err != nil {
+			return err
+		}
+		if f != nil && f.ReadOnly {
+			utils.Outf("{{yellow}}%s is read-only, querying it rather than sending a transaction{{/}}\n", f.Name)
+			return viewContract(ctx, bcli, f, args[0], args[1], payload, codec.MustAddressBech32(consts.HRP, priv.Address))
+		}
+		balance, err := handler.GetBalance(ctx, bcli, priv.Address)
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		action, reply, err := bcli.PrepareExecuteContract(ctx, args[0], args[1], payload, codec.MustAddressBech32(consts.HRP, priv.Address), value)
+		printSimulation(f, reply)
+		if err != nil {
+			return err
+		}
+
+		result, _, err := sendAndWait(ctx, []chain.Action{action}, cli, bcli, ws, factory, true)
+		if err != nil {
+			return err
+		}
+		if !result.Success {
+			utils.Outf("{{red}}error:{{/}} %s\n", result.Error)
+			return nil
+		}
+		utils.Outf("{{yellow}}result:{{/}} %s\n", formatResult(f, result.Outputs[0][0]))
+		events, err := action.Events(result.Outputs[0])
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			utils.Outf(
+				"{{yellow}}event:{{/}} %s {{yellow}}emitter:{{/}} %s {{yellow}}data:{{/}} %s\n",
+				event.Topic,
+				codec.MustAddressBech32(consts.HRP, event.Emitter),
+				formatBytes(event.Data),
+			)
+		}
+		return nil
+	},
+}
+
+var simulateContractCmd = &cobra.Command{
+	Use:   "simulate <address> <function> [payload|args...]",
+	Short: "Simulate a contract call against the latest state without sending it",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+		addr, _, err := handler.Root().GetDefaultKey(true)
+		if err != nil {
+			return err
+		}
+		bcli, err := defaultClient(ctx)
+		if err != nil {
+			return err
+		}
+		f, payload, value, err := parseCallArgs(ctx, bcli, args)
+		if err != nil {
+			return err
+		}
+
+		action, reply, err := bcli.PrepareExecuteContract(ctx, args[0], args[1], payload, codec.MustAddressBech32(consts.HRP, addr), value)
+		printSimulation(f, reply)
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}compute units:{{/}} %d\n", action.ComputeUnitsToSpend)
+		for k, perm := range action.Keys {
+			utils.Outf("{{yellow}}key:{{/}} %x {{yellow}}permissions:{{/}} %d\n", k, perm)
+		}
+		for _, callee := range action.Callees {
+			utils.Outf("{{yellow}}callee:{{/}} %s {{yellow}}keys:{{/}} %d\n", codec.MustAddressBech32(consts.HRP, callee.ContractAddress), len(callee.Keys))
+		}
+		return nil
+	},
+}
+
+var viewContractCmd = &cobra.Command{
+	Use:   "view <address> <function> [payload|args...]",
+	Short: "Query a view of a contract, at the last accepted block or a recent height",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+		// Views do not need a key, the default one is only used as the actor
+		addr, _, err := handler.Root().GetDefaultKey(false)
+		if err != nil && !errors.Is(err, cli.ErrNoKeys) {
+			return err
+		}
+		bcli, err := defaultClient(ctx)
+		if err != nil {
+			return err
+		}
+		if contractValue != "" {
+			return fmt.Errorf("%w: views cannot receive a value", ErrInvalidArgs)
+		}
+		f, payload, _, err := parseCallArgs(ctx, bcli, args)
+		if err != nil {
+			return err
+		}
+		actor := ""
+		if addr != codec.EmptyAddress {
+			actor = codec.MustAddressBech32(consts.HRP, addr)
+		}
+		return viewContract(ctx, bcli, f, args[0], args[1], payload, actor)
+	},
+}
+
+var stateContractCmd = &cobra.Command{
+	Use:   "state <address> <slot>",
+	Short: "Read a slot of a contract, given in hex without its chunks suffix",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+		slot, err := hex.DecodeString(strings.TrimPrefix(args[1], "0x"))
+		if err != nil {
+			return err
+		}
+		bcli, err := defaultClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Slots are addressed together with their chunks, as in the js_sdk
+		key := binary.BigEndian.AppendUint16(slot, contractChunks)
+		values, err := bcli.ContractState(ctx, args[0], [][]byte{key})
+		if err != nil {
+			return err
+		}
+		if values[0] == nil {
+			utils.Outf("{{yellow}}%x:{{/}} not set\n", key)
+			return nil
+		}
+		utils.Outf("{{yellow}}%x:{{/}} %s\n", key, formatBytes(values[0]))
+		return nil
+	},
+}
+
+var storageContractCmd = &cobra.Command{
+	Use:   "storage <address>",
+	Short: "List every slot of a contract",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+		bcli, err := defaultClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		var (
+			cursor []byte
+			slots  int
+		)
+		for {
+			entries, next, err := bcli.ContractStorageDump(ctx, args[0], cursor, 0)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				utils.Outf("{{yellow}}%x:{{/}} %s\n", entry.Slot, formatBytes(entry.Value))
+			}
+			slots += len(entries)
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		utils.Outf("{{yellow}}slots:{{/}} %d\n", slots)
+		return nil
+	},
+}
+
+var codeContractCmd = &cobra.Command{
+	Use:   "code <address>",
+	Short: "Show the metadata of a contract and optionally save its bytecode",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+		bcli, err := defaultClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		bytecode, err := bcli.ContractBytecode(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if len(bytecode) == 0 {
+			utils.Outf("{{red}}no contract at %s{{/}}\n", args[0])
+			return nil
+		}
+		md, err := bcli.ContractMetadata(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		utils.Outf(
+			"{{yellow}}size:{{/}} %d bytes {{yellow}}code hash:{{/}} %s {{yellow}}version:{{/}} %d {{yellow}}admin:{{/}} %s {{yellow}}immutable:{{/}} %t\n",
+			len(bytecode),
+			md.CodeHash,
+			md.Version,
+			md.Admin,
+			md.Immutable,
+		)
+		contractABI, err := bcli.ContractABI(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if contractABI != nil {
+			for _, f := range contractABI.Functions {
+				utils.Outf("{{yellow}}function:{{/}} %s\n", formatSignature(f))
+			}
+		}
+		if contractCodeOutput == "" {
+			return nil
+		}
+		if err := os.WriteFile(contractCodeOutput, bytecode, fsModeWrite); err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}bytecode saved to:{{/}} %s\n", contractCodeOutput)
+		return nil
+	},
+}
+
+// defaultClient connects to the default chain, for commands that do not need
+// to sign transactions.
+func defaultClient(ctx context.Context) (*brpc.JSONRPCClient, error) {
+	chainID, uris, err := handler.Root().GetDefaultChain(true)
+	if err != nil {
+		return nil, err
+	}
+	networkID, _, _, err := rpc.NewJSONRPCClient(uris[0]).Network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return brpc.NewJSONRPCClient(uris[0], networkID, chainID), nil
+}
+
+// loadABI reads the ABI given with --abi or, if there is none, exports it by
+// running [bytecode] locally. Contracts built with an older js_sdk, or that
+// register no function with an ABI, are deployed without one.
+func loadABI(bytecode []byte) ([]byte, error) {
+	if contractABIFile != "" {
+		b, err := os.ReadFile(contractABIFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := abi.Parse(b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+
+	res, err := actions.ContractRuntime().Execute(runtime.JavyExecParams{
+		MaxFuel:       abiExportFuel,
+		MaxTime:       abiExportTime,
+		MaxMemory:     abiExportMemory,
+		Bytecode:      &bytecode,
+		StateProvider: runtime.NewDummyStateProvider().StateProvider,
+		FunctionName:  abi.ExportFunction,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Result.Success {
+		return nil, nil
+	}
+	exported, err := abi.Parse(res.Result.Result)
+	if err != nil {
+		return nil, fmt.Errorf("exported abi: %w", err)
+	}
+	if len(exported.Functions) == 0 {
+		return nil, nil
+	}
+	return res.Result.Result, nil
+}
+
+// parseCallArgs decodes the payload and the value of a call. If the contract
+// has an ABI describing the function, the arguments are encoded with it and
+// the function is returned. Otherwise, a single raw payload is accepted.
+func parseCallArgs(ctx context.Context, bcli *brpc.JSONRPCClient, args []string) (*abi.Function, []byte, uint64, error) {
+	var value uint64
+	if contractValue != "" {
+		var err error
+		value, err = utils.ParseBalance(contractValue, consts.Decimals)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	contractABI, err := bcli.ContractABI(ctx, args[0])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if contractABI != nil {
+		f, err := contractABI.Function(args[1])
+		if err == nil {
+			payload, err := f.ParseArgs(args[2:])
+			return f, payload, value, err
+		}
+	}
+
+	var payload []byte
+	switch {
+	case len(args) > 3:
+		return nil, nil, 0, fmt.Errorf("%w: %s is not in the abi of the contract, expected a single payload", ErrInvalidArgs, args[1])
+	case len(args) == 3 && contractHexPayload:
+		payload, err = hex.DecodeString(strings.TrimPrefix(args[2], "0x"))
+		if err != nil {
+			return nil, nil, 0, err
+		}
+	case len(args) == 3:
+		payload = []byte(args[2])
+	}
+	return nil, payload, value, nil
+}
+
+// viewContract queries the view [name] at [contractHeight] and prints its
+// result, decoded with [f] if known.
+func viewContract(ctx context.Context, bcli *brpc.JSONRPCClient, f *abi.Function, addr string, name string, payload []byte, actor string) error {
+	reply, err := bcli.View(ctx, addr, name, payload, actor, contractHeight)
+	if err != nil {
+		return err
+	}
+	utils.Outf("{{yellow}}height:{{/}} %d {{yellow}}fuel consumed:{{/}} %d\n", reply.Height, reply.FuelConsumed)
+	if !reply.Success {
+		utils.Outf("{{red}}error:{{/}} %s\n", reply.Error)
+		return nil
+	}
+	utils.Outf("{{yellow}}result:{{/}} %s\n", formatResult(f, reply.Result))
+	return nil
+}
+
+// formatResult decodes [result] with the ABI of [f], if known.
+func formatResult(f *abi.Function, result []byte) string {
+	if f == nil {
+		return formatBytes(result)
+	}
+	values, err := f.DecodeReturns(result)
+	if err != nil {
+		return fmt.Sprintf("%s (%v)", formatBytes(result), err)
+	}
+	formatted := make([]string, len(values))
+	for i, v := range values {
+		formatted[i] = fmt.Sprintf("%s=%s", f.Returns[i].Name, abi.FormatValue(f.Returns[i].Type, v))
+	}
+	return strings.Join(formatted, " ")
+}
+
+// formatSignature prints [f] like "transfer(to address, amount u64) -> (u64) [read-only]".
+func formatSignature(f *abi.Function) string {
+	formatParams := func(params []abi.Param) string {
+		formatted := make([]string, len(params))
+		for i, param := range params {
+			formatted[i] = strings.TrimSpace(fmt.Sprintf("%s %s", param.Name, param.Type))
+		}
+		return strings.Join(formatted, ", ")
+	}
+	s := fmt.Sprintf("%s(%s)", f.Name, formatParams(f.Args))
+	if len(f.Returns) > 0 {
+		s += fmt.Sprintf(" -> (%s)", formatParams(f.Returns))
+	}
+	if f.ReadOnly {
+		s += " [read-only]"
+	}
+	return s
+}
+
+func printSimulation(f *abi.Function, reply brpc.ExecuteContractClientReply) {
+	if contractShowDebugLog && reply.DebugLog != "" {
+		utils.Outf("{{yellow}}debug log:{{/}}\n%s\n", reply.DebugLog)
+	}
+	if !reply.Success {
+		utils.Outf("{{red}}simulation failed:{{/}} %s\n", reply.Error)
+		return
+	}
+	utils.Outf(
+		"{{yellow}}simulated result:{{/}} %s {{yellow}}compute units spent:{{/}} %d\n",
+		formatResult(f, reply.Result),
+		reply.ComputeUnitsSpent,
+	)
+	for _, event := range reply.Events {
+		utils.Outf("{{yellow}}simulated event:{{/}} %s {{yellow}}data:{{/}} %s\n", event.Topic, formatBytes(event.Data))
+	}
+}
+
+// formatBytes prints [b] as hex, followed by its text if it is printable.
+func formatBytes(b []byte) string {
+	if len(b) == 0 {
+		return "0x"
+	}
+	s := "0x" + hex.EncodeToString(b)
+	if !utf8.Valid(b) {
+		return s
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return s
+		}
+	}
+	return fmt.Sprintf("%s (%q)", s, b)
+}
@@ -25,26 +25,26 @@ import (
 func sendAndWait(
 	ctx context.Context, actions []chain.Action, cli *rpc.JSONRPCClient,
 	bcli *brpc.JSONRPCClient, ws *rpc.WebSocketClient, factory chain.AuthFactory, printStatus bool,
-) (bool, ids.ID, error) { //nolint:unparam
+) (*chain.Result, ids.ID, error) { //nolint:unparam
 	parser, err := bcli.Parser(ctx)
 	if err != nil {
-		return false, ids.Empty, err
+		return nil, ids.Empty, err
 	}
-	_, tx, _, err := cli.GenerateTransaction(ctx, parser, actions, factory)
+	_, tx, _, err := cli.GenerateTransaction(ctx, parser, actions, factory, rpc.PriorityFee(priorityFee))
 	if err != nil {
-		return false, ids.Empty, err
+		return nil, ids.Empty, err
 	}
 	if err := ws.RegisterTx(tx); err != nil {
-		return false, ids.Empty, err
+		return nil, ids.Empty, err
 	}
 	var result *chain.Result
 	for {
 		txID, txErr, txResult, err := ws.ListenTx(ctx)
 		if err != nil {
-			return false, ids.Empty, err
+			return nil, ids.Empty, err
 		}
 		if txErr != nil {
-			return false, ids.Empty, txErr
+			return nil, ids.Empty, txErr
 		}
 		if txID == tx.ID() {
 			result = txResult
@@ -55,7 +55,7 @@ func sendAndWait(
 	if printStatus {
 		handler.Root().PrintStatus(tx.ID(), result.Success)
 	}
-	return result.Success, tx.ID(), nil
+	return result, tx.ID(), nil
 }
 
 func handleTx(tx *chain.Transaction, result *chain.Result) {
@@ -77,9 +77,15 @@ func handleTx(tx *chain.Transaction, result *chain.Result) {
 
 	for _, action := range tx.Actions {
 		var summaryStr string
-		switch act := action.(type) { //nolint:gocritic
+		switch act := action.(type) {
 		case *actions.Transfer:
 			summaryStr = fmt.Sprintf("%s %s -> %s\n", utils.FormatBalance(act.Value, consts.Decimals), consts.Symbol, codec.MustAddressBech32(consts.HRP, act.To))
+		case *actions.CreateContract:
+			summaryStr = fmt.Sprintf("deployed %d bytes\n", len(act.Bytecode))
+		case *actions.ExecuteContract:
+			summaryStr = fmt.Sprintf("%s.%s\n", codec.MustAddressBech32(consts.HRP, act.ContractAddress), act.FunctionName)
+		case *actions.UpgradeContract:
+			summaryStr = fmt.Sprintf("upgraded %s\n", codec.MustAddressBech32(consts.HRP, act.ContractAddress))
 		}
 		utils.Outf(
 			"%s {{yellow}}%s{{/}} {{yellow}}actor:{{/}} %s {{yellow}}summary (%s):{{/}} [%s] {{yellow}}fee (max %.2f%%):{{/}} %s %s {{yellow}}consumed:{{/}} [%s]\n",
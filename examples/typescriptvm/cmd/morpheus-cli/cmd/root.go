@@ -38,6 +38,7 @@ var (
 	prometheusData        string
 	startPrometheus       bool
 	maxFee                int64
+	priorityFee           uint64
 
 	rootCmd = &cobra.Command{
 		Use:        "morpheus-cli",
@@ -53,6 +54,7 @@ func init() {
 		keyCmd,
 		chainCmd,
 		actionCmd,
+		contractCmd,
 		spamCmd,
 		prometheusCmd,
 	)
@@ -62,6 +64,14 @@ func init() {
 		defaultDatabase,
 		"path to database (will create it missing)",
 	)
+	for _, cmd := range []*cobra.Command{actionCmd, contractCmd} {
+		cmd.PersistentFlags().Uint64Var(
+			&priorityFee,
+			"priority-fee",
+			0,
+			"fee paid on top of the transaction fee to be included first during congestion",
+		)
+	}
 	rootCmd.PersistentPreRunE = func(*cobra.Command, []string) error {
 		utils.Outf("{{yellow}}database:{{/}} %s\n", dbPath)
 		controller := NewController(dbPath)
@@ -147,6 +157,75 @@ func init() {
 		transferCmd,
 	)
 
+	// contract
+	deployContractCmd.PersistentFlags().Uint16Var(
+		&contractDiscriminator,
+		"discriminator",
+		0,
+		"discriminator of the contract address, to deploy several contracts",
+	)
+	deployContractCmd.PersistentFlags().BoolVar(
+		&contractImmutable,
+		"immutable",
+		false,
+		"prevent any upgrade of the contract",
+	)
+	deployContractCmd.PersistentFlags().StringVar(
+		&contractABIFile,
+		"abi",
+		"",
+		"json file with the abi of the contract, instead of exporting it from the contract",
+	)
+	for _, cmd := range []*cobra.Command{callContractCmd, simulateContractCmd, viewContractCmd} {
+		cmd.PersistentFlags().BoolVar(
+			&contractHexPayload,
+			"hex",
+			false,
+			"decode the payload from hex, for functions missing from the abi",
+		)
+		cmd.PersistentFlags().StringVar(
+			&contractValue,
+			"value",
+			"",
+			"amount to transfer to the contract",
+		)
+		cmd.PersistentFlags().BoolVar(
+			&contractShowDebugLog,
+			"debug-log",
+			true,
+			"print the debug log of the simulation",
+		)
+	}
+	for _, cmd := range []*cobra.Command{callContractCmd, viewContractCmd} {
+		cmd.PersistentFlags().Uint64Var(
+			&contractHeight,
+			"height",
+			0,
+			"height of a recent block to query views at, 0 for the last accepted one",
+		)
+	}
+	stateContractCmd.PersistentFlags().Uint16Var(
+		&contractChunks,
+		"chunks",
+		1,
+		"chunks of the slot",
+	)
+	codeContractCmd.PersistentFlags().StringVar(
+		&contractCodeOutput,
+		"output",
+		"",
+		"file to save the bytecode to",
+	)
+	contractCmd.AddCommand(
+		deployContractCmd,
+		callContractCmd,
+		simulateContractCmd,
+		viewContractCmd,
+		stateContractCmd,
+		storageContractCmd,
+		codeContractCmd,
+	)
+
 	// spam
 	runSpamCmd.PersistentFlags().BoolVar(
 		&randomRecipient,
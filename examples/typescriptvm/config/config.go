@@ -27,6 +27,10 @@ const (
 	defaultContinuousProfilerFrequency = 1 * time.Minute
 	defaultContinuousProfilerMaxFiles  = 10
 	defaultStoreTransactions           = true
+	defaultStoreEvents                 = true
+	defaultViewMaxFuel                 = 100_000_000
+	defaultViewMaxTime                 = 100 * time.Millisecond
+	defaultViewCacheSize               = 1024
 )
 
 type Config struct {
@@ -53,9 +57,15 @@ type Config struct {
 	MempoolSponsorSize    int      `json:"mempoolSponsorSize"`
 	MempoolExemptSponsors []string `json:"mempoolExemptSponsors"`
 
+	// Views
+	ViewMaxFuel   uint64        `json:"viewMaxFuel"`
+	ViewMaxTime   time.Duration `json:"viewMaxTime"`
+	ViewCacheSize int           `json:"viewCacheSize"` // results, per state root and call
+
 	// Misc
 	VerifyAuth        bool          `json:"verifyAuth"`
 	StoreTransactions bool          `json:"storeTransactions"`
+	StoreEvents       bool          `json:"storeEvents"`
 	TestMode          bool          `json:"testMode"` // makes gossip/building manual
 	LogLevel          logging.Level `json:"logLevel"`
 
@@ -102,6 +112,10 @@ func (c *Config) setDefault() {
 	c.StreamingBacklogSize = c.Config.GetStreamingBacklogSize()
 	c.VerifyAuth = c.Config.GetVerifyAuth()
 	c.StoreTransactions = defaultStoreTransactions
+	c.StoreEvents = defaultStoreEvents
+	c.ViewMaxFuel = defaultViewMaxFuel
+	c.ViewMaxTime = defaultViewMaxTime
+	c.ViewCacheSize = defaultViewCacheSize
 }
 
 func (c *Config) GetLogLevel() logging.Level                { return c.LogLevel }
@@ -140,4 +154,5 @@ func (c *Config) GetContinuousProfilerConfig() *profiler.Config {
 }
 func (c *Config) GetVerifyAuth() bool        { return c.VerifyAuth }
 func (c *Config) GetStoreTransactions() bool { return c.StoreTransactions }
+func (c *Config) GetStoreEvents() bool       { return c.StoreEvents }
 func (c *Config) Loaded() bool               { return c.loaded }
@@ -17,6 +17,10 @@ const (
 	Decimals = 9
 )
 
+// MaxContractSizeRule is the [chain.Rules.FetchCustom] key of the maximum
+// contract bytecode size, in bytes.
+const MaxContractSizeRule = "maxContractSize"
+
 var ID ids.ID
 
 func init() {
@@ -8,6 +8,7 @@ const (
 	TransferID        uint8 = 0
 	CreateContractID  uint8 = 1
 	ExecuteContractID uint8 = 2
+	UpgradeContractID uint8 = 3
 
 	// Auth TypeIDs
 	ED25519ID       uint8 = 0
@@ -0,0 +1,371 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package contracttest runs contracts in process, on a mock chain backed by
+// memory, so that contract authors can write table-driven Go tests without a
+// VM. Calls go through the same runtime and actions as on-chain: they are
+// simulated to find the keys they access, then executed with those keys
+// declared.
+package contracttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/genesis"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/runtime"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+)
+
+const (
+	// DefaultMaxFuel is the fuel of calls that do not set one
+	DefaultMaxFuel = 100 * actions.ExecuteContractFuelPerComputeUnit
+
+	// BlockGap is the time between blocks started by [Chain.NextBlock]
+	BlockGap = time.Second
+
+	// genesisTimestamp is the timestamp of the genesis block, in milliseconds
+	genesisTimestamp = 1_700_000_000_000
+)
+
+var _ state.Mutable = memoryState{}
+
+// memoryState is a [state.Mutable] kept in memory.
+type memoryState map[string][]byte
+
+func (m memoryState) GetValue(_ context.Context, key []byte) ([]byte, error) {
+	if v, ok := m[string(key)]; ok {
+		return v, nil
+	}
+	return nil, database.ErrNotFound
+}
+
+func (m memoryState) Insert(_ context.Context, key []byte, value []byte) error {
+	m[string(key)] = bytes.Clone(value)
+	return nil
+}
+
+func (m memoryState) Remove(_ context.Context, key []byte) error {
+	delete(m, string(key))
+	return nil
+}
+
+// Chain is a mock chain. Calls are executed in the block being built, which
+// is accepted by [Chain.NextBlock]. It is not safe for concurrent use.
+type Chain struct {
+	ChainID ids.ID
+
+	rules chain.Rules
+	state memoryState
+
+	// height and timestamp are those of the block being built
+	height    uint64
+	timestamp int64
+
+	// discriminators are the next discriminator of each deployer
+	discriminators map[codec.Address]uint16
+
+	// actions counts the actions executed, to give each a unique ID
+	actions uint64
+}
+
+// New returns a chain with the default genesis rules and no balances. The
+// first block is built on top of genesis.
+func New() *Chain {
+	chainID := ids.GenerateTestID()
+	c := &Chain{
+		ChainID:        chainID,
+		rules:          genesis.Default().Rules(genesisTimestamp, 0, chainID),
+		state:          memoryState{},
+		discriminators: map[codec.Address]uint16{},
+	}
+	c.setBlock(1, genesisTimestamp+BlockGap.Milliseconds())
+	return c
+}
+
+// setBlock starts building the block at [height]. The chain records the
+// previous block as the last one applied to state.
+func (c *Chain) setBlock(height uint64, timestamp int64) {
+	c.height = height
+	c.timestamp = timestamp
+	c.state[string(storage.ChainHeightKey())] = binary.BigEndian.AppendUint64(nil, height-1)
+	c.state[string(storage.ChainTimestampKey())] = binary.BigEndian.AppendUint64(nil, uint64(timestamp-BlockGap.Milliseconds()))
+}
+
+// Height is the height of the block calls are executed in.
+func (c *Chain) Height() uint64 {
+	return c.height
+}
+
+// Timestamp is the timestamp, in milliseconds, of the block calls are
+// executed in.
+func (c *Chain) Timestamp() int64 {
+	return c.timestamp
+}
+
+// NextBlock accepts the block being built and starts the next one,
+// [BlockGap] later.
+func (c *Chain) NextBlock() {
+	c.AdvanceTime(BlockGap)
+}
+
+// AdvanceTime accepts the block being built and starts the next one, [gap]
+// later, to test contracts depending on time.
+func (c *Chain) AdvanceTime(gap time.Duration) {
+	c.setBlock(c.height+1, c.timestamp+gap.Milliseconds())
+}
+
+// Actor returns a deterministic address for [name], for instance "alice".
+func Actor(name string) codec.Address {
+	return codec.CreateAddress(consts.ED25519ID, utils.ToID([]byte(name)))
+}
+
+// Key is the key of [slot], suffixed with its [chunks] like the js_sdk does.
+func Key(slot []byte, chunks uint16) []byte {
+	return keys.EncodeChunks(slot, chunks)
+}
+
+// SetBalance sets the native balance of [addr].
+func (c *Chain) SetBalance(addr codec.Address, amount uint64) error {
+	return storage.SetBalance(context.Background(), c.state, addr, amount)
+}
+
+// Balance returns the native balance of [addr].
+func (c *Chain) Balance(addr codec.Address) (uint64, error) {
+	return storage.GetBalance(context.Background(), c.state, addr)
+}
+
+// State returns the value of [slot] of [contract], or nil if it is not set.
+func (c *Chain) State(contract codec.Address, slot []byte, chunks uint16) ([]byte, error) {
+	return storage.GetContractStateValue(context.Background(), c.state, contract, string(Key(slot, chunks)))
+}
+
+// SetState sets the value of [slot] of [contract], to prepare a test. An
+// empty value removes the slot.
+func (c *Chain) SetState(contract codec.Address, slot []byte, chunks uint16, value []byte) error {
+	k := storage.ContractStateKey(contract, Key(slot, chunks))
+	if !keys.VerifyValue(k, value) {
+		return fmt.Errorf("%w: value of %d bytes does not fit in %d chunks", chain.ErrInvalidKeyValue, len(value), chunks)
+	}
+	return storage.WriteContractStateValue(context.Background(), c.state, k, value)
+}
+
+// Load reads a contract from a .wasm, .js or .ts file, see
+// [runtime.CompileFile].
+func Load(path string) ([]byte, error) {
+	return runtime.CompileFile(path)
+}
+
+// Deploy stores [bytecode] as a new contract of [deployer] and returns its
+// address. The deployment is checked like a [actions.CreateContract].
+func (c *Chain) Deploy(deployer codec.Address, bytecode []byte) (codec.Address, error) {
+	discriminator := c.discriminators[deployer]
+	action := &actions.CreateContract{
+		Bytecode:      bytecode,
+		Discriminator: discriminator,
+	}
+	if _, err := action.Execute(context.Background(), c.rules, c.state, c.timestamp, deployer, c.nextActionID()); err != nil {
+		return codec.EmptyAddress, err
+	}
+	c.discriminators[deployer] = discriminator + 1
+	return storage.GenerateContractAddress(deployer, discriminator), nil
+}
+
+// DeployFile loads the contract at [path] and deploys it.
+func (c *Chain) DeployFile(deployer codec.Address, path string) (codec.Address, error) {
+	bytecode, err := Load(path)
+	if err != nil {
+		return codec.EmptyAddress, err
+	}
+	return c.Deploy(deployer, bytecode)
+}
+
+func (c *Chain) nextActionID() ids.ID {
+	c.actions++
+	return utils.ToID(binary.BigEndian.AppendUint64(nil, c.actions))
+}
+
+// Call describes a call of [Function] of [Contract] by [Actor].
+type Call struct {
+	Actor    codec.Address
+	Contract codec.Address
+	Function string
+	Payload  []byte
+
+	// Value is transferred from the actor to the contract
+	Value uint64
+
+	// MaxFuel defaults to [DefaultMaxFuel]
+	MaxFuel uint64
+}
+
+func (call *Call) maxFuel() uint64 {
+	if call.MaxFuel == 0 {
+		return DefaultMaxFuel
+	}
+	return call.MaxFuel
+}
+
+// Result is the outcome of a call. Failed calls are reported in [Success]
+// and [Error] rather than as errors, which are kept for calls that could not
+// run at all or that trapped, for instance by running out of fuel.
+type Result struct {
+	Success bool
+	Result  []byte
+	Error   string
+
+	FuelConsumed uint64
+	DebugLog     []byte
+
+	// Events are only set if the call succeeded
+	Events []*chain.Event
+
+	// Keys are the slots of the called contract that the call accessed
+	Keys actions.StateKeysWithPermissions
+}
+
+func newResult(res *runtime.JavyExecResult) *Result {
+	return &Result{
+		Success:      res.Result.Success,
+		Result:       res.Result.Result,
+		Error:        res.Result.Error,
+		FuelConsumed: res.FuelConsumed,
+		DebugLog:     res.DebugLog,
+		Events:       []*chain.Event{},
+		Keys:         actions.StateKeysWithPermissions{},
+	}
+}
+
+// Simulate runs [call] without changing the state.
+func (c *Chain) Simulate(call Call) (*Result, error) {
+	sim, err := actions.SimulateContract(
+		context.Background(),
+		c.state,
+		c.ChainID,
+		c.timestamp,
+		call.Contract,
+		call.Actor,
+		call.Function,
+		call.Payload,
+		call.Value,
+		call.maxFuel(),
+		0,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := newResult(sim.JavyExecResult)
+	res.Events = sim.Events
+	res.Keys = sim.Keys
+	return res, nil
+}
+
+// Call runs [call] and keeps its changes if it succeeds. Like on-chain, the
+// call is executed with the keys found by simulating it declared.
+func (c *Chain) Call(call Call) (*Result, error) {
+	ctx := context.Background()
+	sim, err := actions.SimulateContract(
+		ctx,
+		c.state,
+		c.ChainID,
+		c.timestamp,
+		call.Contract,
+		call.Actor,
+		call.Function,
+		call.Payload,
+		call.Value,
+		call.maxFuel(),
+		0,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := newResult(sim.JavyExecResult)
+	if !res.Success {
+		return res, nil
+	}
+
+	action := sim.Action(call.Contract, call.Function, call.Payload, call.Value)
+	action.ComputeUnitsToSpend = call.maxFuel() / actions.ExecuteContractFuelPerComputeUnit
+	snapshot := c.Snapshot()
+	outputs, _, err := action.ExecuteMetered(ctx, c.rules, c.state, c.timestamp, call.Actor, c.nextActionID())
+	if err != nil {
+		// The simulation succeeded, so this only fails if the declared keys
+		// do not match the keys accessed
+		c.Restore(snapshot)
+		return nil, err
+	}
+	res.Result = outputs[0]
+	res.Events, err = action.Events(outputs)
+	if err != nil {
+		return nil, err
+	}
+	res.Keys = sim.Keys
+	return res, nil
+}
+
+// View runs the view [function] of [contract] read-only against the last
+// accepted block, like the view RPC does.
+func (c *Chain) View(actor codec.Address, contract codec.Address, function string, payload []byte) (*Result, error) {
+	res, err := actions.ViewContract(
+		context.Background(),
+		c.state,
+		c.ChainID,
+		contract,
+		actor,
+		function,
+		payload,
+		DefaultMaxFuel,
+		0,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(res.JavyExecResult), nil
+}
+
+// Snapshot is a copy of the state of a [Chain].
+type Snapshot struct {
+	state          memoryState
+	height         uint64
+	timestamp      int64
+	discriminators map[codec.Address]uint16
+	actions        uint64
+}
+
+// Snapshot copies the state of the chain, to restore it later with
+// [Chain.Restore], for instance between the cases of a table-driven test.
+func (c *Chain) Snapshot() *Snapshot {
+	return &Snapshot{
+		state:          maps.Clone(c.state),
+		height:         c.height,
+		timestamp:      c.timestamp,
+		discriminators: maps.Clone(c.discriminators),
+		actions:        c.actions,
+	}
+}
+
+// Restore brings the chain back to [s]. A snapshot may be restored several
+// times.
+func (c *Chain) Restore(s *Snapshot) {
+	c.state = maps.Clone(s.state)
+	c.height = s.height
+	c.timestamp = s.timestamp
+	c.discriminators = maps.Clone(s.discriminators)
+	c.actions = s.actions
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contracttest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/state"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
+)
+
+var countSlot = []byte{1}
+
+func deployCounter(t *testing.T) (*Chain, Call) {
+	t.Helper()
+
+	c := New()
+	addr, err := c.DeployFile(Actor("deployer"), "testdata/counter.js")
+	require.NoError(t, err)
+	return c, Call{Actor: Actor("alice"), Contract: addr, Function: "increment"}
+}
+
+func TestCall(t *testing.T) {
+	require := require.New(t)
+	c, increment := deployCounter(t)
+
+	res, err := c.Call(increment)
+	require.NoError(err)
+	require.True(res.Success, res.Error)
+	require.Equal([]byte{1}, res.Result)
+	require.Positive(res.FuelConsumed)
+	require.Equal(actions.StateKeysWithPermissions{string(Key(countSlot, 1)): state.All}, res.Keys)
+	require.Equal([]*chain.Event{{Emitter: increment.Contract, Topic: "incremented", Data: []byte{1}}}, res.Events)
+
+	value, err := c.State(increment.Contract, countSlot, 1)
+	require.NoError(err)
+	require.Equal([]byte{1}, value)
+
+	// Simulations do not change the state
+	res, err = c.Simulate(increment)
+	require.NoError(err)
+	require.Equal([]byte{2}, res.Result)
+	value, err = c.State(increment.Contract, countSlot, 1)
+	require.NoError(err)
+	require.Equal([]byte{1}, value)
+
+	// Failures are reported in the result
+	res, err = c.Call(Call{Actor: increment.Actor, Contract: increment.Contract, Function: "decrement"})
+	require.NoError(err)
+	require.False(res.Success)
+	require.Contains(res.Error, "decrement not found")
+}
+
+func TestCallTable(t *testing.T) {
+	c, increment := deployCounter(t)
+	start := c.Snapshot()
+
+	tests := []struct {
+		name     string
+		prepared []byte
+		expected []byte
+		maxFuel  uint64
+		err      bool
+	}{
+		{
+			name:     "empty",
+			expected: []byte{1},
+		},
+		{
+			name:     "prepared",
+			prepared: []byte{41},
+			expected: []byte{42},
+		},
+		{
+			name:    "out of fuel",
+			maxFuel: 1,
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			c.Restore(start)
+
+			require.NoError(c.SetState(increment.Contract, countSlot, 1, tt.prepared))
+			call := increment
+			call.MaxFuel = tt.maxFuel
+			res, err := c.Call(call)
+			if tt.err {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			require.True(res.Success, res.Error)
+			require.Equal(tt.expected, res.Result)
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	require := require.New(t)
+	c, increment := deployCounter(t)
+	alice := increment.Actor
+
+	require.NoError(c.SetBalance(alice, 100))
+	snapshot := c.Snapshot()
+	height := c.Height()
+
+	c.NextBlock()
+	require.Equal(height+1, c.Height())
+	increment.Value = 40
+	res, err := c.Call(increment)
+	require.NoError(err)
+	require.True(res.Success, res.Error)
+	balance, err := c.Balance(increment.Contract)
+	require.NoError(err)
+	require.Equal(uint64(40), balance)
+
+	c.Restore(snapshot)
+	require.Equal(height, c.Height())
+	balance, err = c.Balance(alice)
+	require.NoError(err)
+	require.Equal(uint64(100), balance)
+	value, err := c.State(increment.Contract, countSlot, 1)
+	require.NoError(err)
+	require.Nil(value)
+
+	// Snapshots keep the discriminators, so redeploying gives a new address
+	c.Restore(snapshot)
+	bytecode, err := Load("testdata/counter.js")
+	require.NoError(err)
+	addr, err := c.Deploy(Actor("deployer"), bytecode)
+	require.NoError(err)
+	require.NotEqual(increment.Contract, addr)
+}
+
+func TestView(t *testing.T) {
+	require := require.New(t)
+	c, increment := deployCounter(t)
+
+	_, err := c.Call(increment)
+	require.NoError(err)
+	res, err := c.View(increment.Actor, increment.Contract, "get", nil)
+	require.NoError(err)
+	require.True(res.Success, res.Error)
+	require.Equal([]byte{1}, res.Result)
+
+	// Writes are rejected in views, which aborts the contract
+	_, err = c.View(increment.Actor, increment.Contract, "increment", nil)
+	require.ErrorContains(err, "failed to execute contract")
+}
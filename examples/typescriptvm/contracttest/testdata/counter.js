@@ -0,0 +1,90 @@
+// A counter speaking the host call protocol directly, so that it compiles
+// without a TypeScript toolchain. Contracts built with the js_sdk are tested
+// the same way from their .ts sources.
+const HOST_CALL_MAGIC = [0x00, 0x74, 0x73, 0x76];
+const COUNT_KEY = new Uint8Array([1, 0, 1]);
+
+function readAll() {
+    let buffer = new Uint8Array(1024);
+    let used = 0;
+    while (true) {
+        const n = Javy.IO.readSync(0, buffer.subarray(used));
+        if (n === 0) {
+            return new TextDecoder().decode(buffer.subarray(0, used));
+        }
+        used += n;
+        if (used === buffer.length) {
+            const next = new Uint8Array(buffer.length * 2);
+            next.set(buffer);
+            buffer = next;
+        }
+    }
+}
+
+function readExact(length) {
+    const buffer = new Uint8Array(length);
+    let used = 0;
+    while (used < length) {
+        const n = Javy.IO.readSync(0, buffer.subarray(used));
+        if (n <= 0) {
+            throw Error("unexpected end of host response");
+        }
+        used += n;
+    }
+    return buffer;
+}
+
+function hostCall(op, key, value) {
+    const request = new Uint8Array(HOST_CALL_MAGIC.length + 3 + key.length + value.length);
+    request.set(HOST_CALL_MAGIC);
+    request[4] = op;
+    request[5] = (key.length >> 8) & 0xff;
+    request[6] = key.length & 0xff;
+    request.set(key, 7);
+    request.set(value, 7 + key.length);
+    if (Javy.IO.writeSync(2, request) !== request.length) {
+        throw Error("host call rejected");
+    }
+}
+
+function readResponse() {
+    const header = readExact(4);
+    return readExact(((header[0] << 24) | (header[1] << 16) | (header[2] << 8) | header[3]) >>> 0);
+}
+
+function getCount() {
+    hostCall(0, COUNT_KEY, new Uint8Array());
+    const value = readResponse();
+    return value.length ? value[0] : 0;
+}
+
+const BASE64_CHARS = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+
+// Encodes a single byte result
+function toBase64(byte) {
+    return BASE64_CHARS[byte >> 2] + BASE64_CHARS[(byte & 3) << 4] + "==";
+}
+
+function respond(success, result) {
+    const out = success
+        ? JSON.stringify({ success: true, result: toBase64(result) })
+        : JSON.stringify({ success: false, error: result });
+    Javy.IO.writeSync(1, new TextEncoder().encode(out));
+}
+
+const input = JSON.parse(readAll());
+switch (input.functionName) {
+    case "increment": {
+        const count = getCount() + 1;
+        hostCall(1, COUNT_KEY, new Uint8Array([count]));
+        hostCall(5, new TextEncoder().encode("incremented"), new Uint8Array([count]));
+        readResponse();
+        respond(true, count);
+        break;
+    }
+    case "get":
+        respond(true, getCount());
+        break;
+    default:
+        respond(false, `Function ${input.functionName} not found`);
+}
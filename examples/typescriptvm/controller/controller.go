@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
 	"go.uber.org/zap"
 
@@ -37,12 +39,17 @@ type Controller struct {
 
 	snowCtx      *snow.Context
 	genesis      *genesis.Genesis
+	rules        *chain.RulesCache
 	config       *config.Config
 	stateManager *storage.StateManager
 
 	metrics *metrics
 
 	metaDB database.Database
+
+	// viewCache holds the results of views, which only depend on the state
+	// root and the call
+	viewCache *cache.LRU[ids.ID, *actions.ViewResult]
 }
 
 func New() *vm.VM {
@@ -86,7 +93,9 @@ func (c *Controller) Initialize(
 		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 	c.snowCtx.Log.SetLevel(c.config.GetLogLevel())
+	actions.ContractRuntime().SetLogger(c.snowCtx.Log)
 	snowCtx.Log.Info("initialized config", zap.Bool("loaded", c.config.Loaded()), zap.Any("contents", c.config))
+	c.viewCache = &cache.LRU[ids.ID, *actions.ViewResult]{Size: c.config.ViewCacheSize}
 
 	c.genesis, err = genesis.New(genesisBytes, upgradeBytes)
 	if err != nil {
@@ -96,6 +105,7 @@ func (c *Controller) Initialize(
 		)
 	}
 	snowCtx.Log.Info("loaded genesis", zap.Any("genesis", c.genesis))
+	c.rules = c.genesis.RulesCache(c.snowCtx.NetworkID, c.snowCtx.ChainID)
 
 	// Create DBs
 	blockDB, stateDB, metaDB, err := hstorage.New(snowCtx.ChainDataDir, gatherer)
@@ -139,8 +149,7 @@ func (c *Controller) Initialize(
 }
 
 func (c *Controller) Rules(t int64) chain.Rules {
-	// TODO: extend with [UpgradeBytes]
-	return c.genesis.Rules(t, c.snowCtx.NetworkID, c.snowCtx.ChainID)
+	return c.rules.Rules(t)
 }
 
 func (c *Controller) StateManager() chain.StateManager {
@@ -175,11 +184,45 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 					c.metrics.transfer.Inc()
 				}
 			}
+			if c.config.GetStoreEvents() {
+				if err := c.storeEvents(ctx, batch, blk.Hght, uint32(i), tx, result); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return batch.Write()
 }
 
+// storeEvents indexes the events emitted by a successful transaction.
+func (*Controller) storeEvents(
+	ctx context.Context,
+	db database.KeyValueWriter,
+	height uint64,
+	txIndex uint32,
+	tx *chain.Transaction,
+	result *chain.Result,
+) error {
+	var eventIndex uint16
+	for i, action := range tx.Actions {
+		eventAction, ok := action.(chain.EventAction)
+		if !ok {
+			continue
+		}
+		events, err := eventAction.Events(result.Outputs[i])
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := storage.StoreEvent(ctx, db, height, txIndex, eventIndex, tx.ID(), event); err != nil {
+				return err
+			}
+			eventIndex++
+		}
+	}
+	return nil
+}
+
 func (*Controller) Rejected(context.Context, *chain.StatelessBlock) error {
 	return nil
 }
@@ -11,12 +11,16 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/x/merkledb"
 
 	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/genesis"
-	"github.com/ava-labs/hypersdk/examples/typescriptvm/runtime"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/rpc"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
 	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/utils"
 )
 
 func (c *Controller) Genesis() *genesis.Genesis {
@@ -52,33 +56,188 @@ func (c *Controller) GetContractBytecodeFromState(
 	return storage.GetContractBytecodeFromState(ctx, c.inner.ReadState, acct)
 }
 
+func (c *Controller) GetContractMetadataFromState(
+	ctx context.Context,
+	acct codec.Address,
+) (*storage.ContractMetadata, error) {
+	return storage.GetContractMetadataFromState(ctx, c.inner.ReadState, acct)
+}
+
+func (c *Controller) GetContractABIFromState(
+	ctx context.Context,
+	acct codec.Address,
+) ([]byte, error) {
+	return storage.GetContractABIFromState(ctx, c.inner.ReadState, acct)
+}
+
+func (c *Controller) GetContractStateFromState(
+	ctx context.Context,
+	acct codec.Address,
+	slots [][]byte,
+) ([][]byte, error) {
+	return storage.GetContractStateFromState(ctx, c.inner.ReadState, acct, slots)
+}
+
+func (c *Controller) GetContractStorage(
+	ctx context.Context,
+	acct codec.Address,
+	start []byte,
+	limit int,
+) ([]*storage.ContractStateEntry, []byte, error) {
+	db, err := c.inner.State()
+	if err != nil {
+		return nil, nil, err
+	}
+	return storage.GetContractStorage(ctx, db, acct, start, limit)
+}
+
+// ViewContract runs a view against the state after the accepted block at
+// [height], or the last accepted block if [height] is 0. States are
+// identified by their root, which also keys the cached results.
+func (c *Controller) ViewContract(
+	ctx context.Context,
+	contractAddress codec.Address,
+	actor codec.Address,
+	funcName string,
+	payload []byte,
+	height uint64,
+) (*actions.ViewResult, error) {
+	db, err := c.inner.State()
+	if err != nil {
+		return nil, err
+	}
+	var root ids.ID
+	switch lastAccepted := c.inner.LastAcceptedBlock().Height(); {
+	case height > lastAccepted:
+		return nil, fmt.Errorf("%w: %d is not accepted yet", rpc.ErrHeightUnavailable, height)
+	case height > 0 && height < lastAccepted:
+		// Blocks carry the state root of their parent
+		blk, err := c.inner.GetDiskBlock(ctx, height+1)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %d: %w", rpc.ErrHeightUnavailable, height, err)
+		}
+		root = blk.StateRoot
+	default:
+		root, err = db.GetMerkleRoot(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c.view(ctx, db, root, height, contractAddress, actor, funcName, payload)
+}
+
+// view runs a view against the state at [root]. The last accepted state is
+// read directly, while older states are read through range proofs.
+func (c *Controller) view(
+	ctx context.Context,
+	db merkledb.MerkleDB,
+	root ids.ID,
+	height uint64,
+	contractAddress codec.Address,
+	actor codec.Address,
+	funcName string,
+	payload []byte,
+) (*actions.ViewResult, error) {
+	p := codec.NewWriter(0, consts.MaxInt)
+	p.PackID(root)
+	p.PackAddress(contractAddress)
+	p.PackAddress(actor)
+	p.PackString(funcName)
+	p.PackBytes(payload)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	key := utils.ToID(p.Bytes())
+	if res, ok := c.viewCache.Get(key); ok {
+		return res, nil
+	}
+
+	run := func(im storage.ReadState) (*actions.ViewResult, error) {
+		stateHeight, err := storage.GetParentHeight(ctx, im)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %d: %w", rpc.ErrHeightUnavailable, height, err)
+		}
+		if height > 0 && stateHeight != height {
+			// A block was accepted in between
+			return nil, fmt.Errorf("%w: %d is no longer the last accepted block", rpc.ErrHeightUnavailable, height)
+		}
+		return actions.ViewContract(
+			ctx,
+			im,
+			c.snowCtx.ChainID,
+			contractAddress,
+			actor,
+			funcName,
+			payload,
+			c.config.ViewMaxFuel,
+			c.config.ViewMaxTime,
+		)
+	}
+	latest, err := db.GetMerkleRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res *actions.ViewResult
+	if root == latest {
+		res, err = run(storage.ReadState(db.GetValues))
+		current, rootErr := db.GetMerkleRoot(ctx)
+		if rootErr != nil {
+			return nil, rootErr
+		}
+		if current != root {
+			// A block was accepted during the view, which may have read both
+			// states, so it is run again against the state it started from
+			res, err = run(storage.ReadStateAtRoot(db, root))
+		}
+	} else {
+		res, err = run(storage.ReadStateAtRoot(db, root))
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.viewCache.Put(key, res)
+	return res, nil
+}
+
+func (c *Controller) GetEvents(
+	ctx context.Context,
+	emitter codec.Address,
+	topic string,
+	fromHeight uint64,
+	toHeight uint64,
+	limit int,
+) ([]*storage.StoredEvent, error) {
+	return storage.GetEvents(ctx, c.metaDB, emitter, topic, fromHeight, toHeight, limit)
+}
+
 func (c *Controller) ExecuteContractOnState(
 	ctx context.Context,
 	contractAddress codec.Address,
 	actor codec.Address,
 	payload []byte,
 	funcName string,
-) (*runtime.JavyExecResult, error) {
+	value uint64,
+) (*actions.Simulation, error) {
 	bytecode, err := c.GetContractBytecodeFromState(ctx, contractAddress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get contract bytecode: %w", err)
 	}
-	if bytecode == nil {
+	if len(bytecode) == 0 {
 		return nil, fmt.Errorf("contract %s has no bytecode", contractAddress)
 	}
 
-	provider := storage.GetContractStateProviderFromState(ctx, c.inner.ReadState, contractAddress)
-
-	params := runtime.JavyExecParams{ // FIXME:move limits to config
-		MaxFuel:       10 * 1000 * 1000,
-		MaxTime:       time.Millisecond * 10,
-		MaxMemory:     1024 * 1024 * 10,
-		Bytecode:      &bytecode,
-		StateProvider: provider,
-		Payload:       payload,
-		Actor:         actor[:],
-		FunctionName:  funcName,
-	}
-
-	return runtime.NewJavyExec().Execute(params)
+	return actions.SimulateContract( // FIXME:move limits to config
+		ctx,
+		storage.ReadState(c.inner.ReadState),
+		c.snowCtx.ChainID,
+		time.Now().UnixMilli(),
+		contractAddress,
+		actor,
+		funcName,
+		payload,
+		value,
+		10*1000*1000,
+		time.Millisecond*10,
+	)
 }
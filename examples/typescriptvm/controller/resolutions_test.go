@@ -0,0 +1,160 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package controller
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/bytecodealliance/wasmtime-go/v21"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/config"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
+)
+
+var contractAddress = codec.Address{0x05, 0x01}
+
+// testDB counts the reads served through range proofs, and runs [onRead]
+// before the first direct read.
+type testDB struct {
+	merkledb.MerkleDB
+
+	proofs int
+	onRead func()
+}
+
+func (db *testDB) GetRangeProofAtRoot(
+	ctx context.Context,
+	rootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) (*merkledb.RangeProof, error) {
+	db.proofs++
+	return db.MerkleDB.GetRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+}
+
+func (db *testDB) GetValues(ctx context.Context, keys [][]byte) ([][]byte, []error) {
+	if onRead := db.onRead; onRead != nil {
+		db.onRead = nil
+		onRead()
+	}
+	return db.MerkleDB.GetValues(ctx, keys)
+}
+
+func newTestDB(t *testing.T) *testDB {
+	db, err := merkledb.New(context.Background(), memdb.New(), merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor16,
+		RootGenConcurrency:          1,
+		HistoryLength:               4,
+		ValueNodeCacheSize:          1024,
+		IntermediateNodeCacheSize:   1024,
+		IntermediateWriteBufferSize: 1024,
+		IntermediateWriteBatchSize:  1024,
+		Reg:                         prometheus.NewRegistry(),
+		TraceLevel:                  merkledb.InfoTrace,
+		Tracer:                      trace.Noop,
+	})
+	require.NoError(t, err)
+
+	// A contract that returns successfully without touching state
+	output := `{"success":true,"result":""}`
+	wasm, err := wasmtime.Wat2Wasm(fmt.Sprintf(`(module
+  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 1024) %q)
+  (func (export "_start")
+    (i32.store (i32.const 0) (i32.const 1024))
+    (i32.store (i32.const 4) (i32.const %d))
+    (drop (call $fd_write (i32.const 1) (i32.const 0) (i32.const 1) (i32.const 8)))))`, output, len(output)))
+	require.NoError(t, err)
+	require.NoError(t, db.Put(storage.ContractBytecodeKey(contractAddress), wasm))
+	require.NoError(t, db.Put(storage.ChainTimestampKey(), binary.BigEndian.AppendUint64(nil, 1_000)))
+	return &testDB{MerkleDB: db}
+}
+
+// accept sets the height of the state to [height] and returns its root.
+func (db *testDB) accept(t *testing.T, height uint64) ids.ID {
+	require.NoError(t, db.Put(storage.ChainHeightKey(), binary.BigEndian.AppendUint64(nil, height)))
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(t, err)
+	return root
+}
+
+func newTestController() *Controller {
+	return &Controller{
+		snowCtx:   &snow.Context{},
+		config:    &config.Config{ViewMaxFuel: 100_000_000},
+		viewCache: &cache.LRU[ids.ID, *actions.ViewResult]{Size: 16},
+	}
+}
+
+func TestViewLatestState(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db := newTestDB(t)
+	root := db.accept(t, 1)
+
+	// The last accepted state is read without proofs
+	res, err := newTestController().view(ctx, db, root, 1, contractAddress, codec.EmptyAddress, "get", nil)
+	require.NoError(err)
+	require.True(res.Result.Success, res.Result.Error)
+	require.Equal(uint64(1), res.Height)
+	require.Zero(db.proofs)
+}
+
+func TestViewHistoricalState(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db := newTestDB(t)
+	root := db.accept(t, 1)
+	db.accept(t, 2)
+
+	// Older states are read through proofs
+	c := newTestController()
+	res, err := c.view(ctx, db, root, 1, contractAddress, codec.EmptyAddress, "get", nil)
+	require.NoError(err)
+	require.True(res.Result.Success, res.Result.Error)
+	require.Equal(uint64(1), res.Height)
+	require.Positive(db.proofs)
+
+	// Results are cached by root
+	proofs := db.proofs
+	cached, err := c.view(ctx, db, root, 1, contractAddress, codec.EmptyAddress, "get", nil)
+	require.NoError(err)
+	require.Equal(res, cached)
+	require.Equal(proofs, db.proofs)
+}
+
+func TestViewAcceptedDuringView(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db := newTestDB(t)
+	root := db.accept(t, 1)
+
+	// A block accepted while the view reads the last accepted state makes it
+	// run again against the state it started from
+	db.onRead = func() { db.accept(t, 2) }
+	res, err := newTestController().view(ctx, db, root, 0, contractAddress, codec.EmptyAddress, "get", nil)
+	require.NoError(err)
+	require.True(res.Result.Success, res.Result.Error)
+	require.Equal(uint64(1), res.Height)
+	require.Positive(db.proofs)
+}
@@ -8,4 +8,7 @@ import "errors"
 var (
 	ErrInvalidHRP    = errors.New("invalid HRP")
 	ErrInvalidTarget = errors.New("invalid target")
+
+	ErrInvalidMaxContractSize = errors.New("invalid max contract size")
+	ErrInvalidWarpQuorum      = errors.New("invalid warp quorum")
 )
@@ -8,9 +8,11 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
+	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
@@ -57,8 +59,27 @@ type Genesis struct {
 	StorageKeyWriteUnits      uint64 `json:"storageKeyWriteUnits"`
 	StorageValueWriteUnits    uint64 `json:"storageValueWriteUnits"` // per chunk
 
+	// Contract Parameters
+	MaxContractSize int `json:"maxContractSize"` // bytes
+
+	// Warp Parameters
+	//
+	// Warp messages are only accepted from [WarpSourceChains], when signed by
+	// [WarpQuorumNumerator]/[WarpQuorumDenominator] of the stake of the source
+	// subnet.
+	WarpSourceChains          []ids.ID `json:"warpSourceChains"`
+	WarpQuorumNumerator       uint64   `json:"warpQuorumNumerator"`
+	WarpQuorumDenominator     uint64   `json:"warpQuorumDenominator"`
+	WarpBaseComputeUnits      uint64   `json:"warpBaseComputeUnits"`
+	WarpComputeUnitsPerSigner uint64   `json:"warpComputeUnitsPerSigner"`
+
 	// Allocates
 	CustomAllocation []*CustomAllocation `json:"customAllocation"`
+
+	// Upgrades change the rules over time. They are read from the upgrade
+	// bytes of the VM, which replace any set in the genesis, and served with
+	// the genesis, so that clients use the rules of the chain.
+	Upgrades chain.Upgrades `json:"upgrades,omitempty"`
 }
 
 func Default() *Genesis {
@@ -79,7 +100,7 @@ func Default() *Genesis {
 		// Tx Parameters
 		ValidityWindow:      60 * hconsts.MillisecondsPerSecond, // ms
 		MaxActionsPerTx:     16,
-		MaxOutputsPerAction: 1,
+		MaxOutputsPerAction: 33, // contract result and up to 32 events
 
 		// Tx Fee Compute Parameters
 		BaseComputeUnits: 1,
@@ -93,16 +114,41 @@ func Default() *Genesis {
 		StorageValueAllocateUnits: 5,
 		StorageKeyWriteUnits:      10,
 		StorageValueWriteUnits:    3,
+
+		// Contract Parameters
+		MaxContractSize: storage.MaxContractBytecodeSize,
+
+		// Warp Parameters
+		WarpQuorumNumerator:       67,
+		WarpQuorumDenominator:     100,
+		WarpBaseComputeUnits:      1_024,
+		WarpComputeUnitsPerSigner: 128,
 	}
 }
 
-func New(b []byte, _ []byte /* upgradeBytes */) (*Genesis, error) {
+func New(b []byte, upgradeBytes []byte) (*Genesis, error) {
 	g := Default()
 	if len(b) > 0 {
 		if err := json.Unmarshal(b, g); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal config %s: %w", string(b), err)
 		}
 	}
+	if len(upgradeBytes) > 0 {
+		upgrades, err := chain.ParseUpgrades(upgradeBytes)
+		if err != nil {
+			return nil, err
+		}
+		g.Upgrades = upgrades
+	}
+	if err := g.Upgrades.Verify(consts.ActionRegistry, consts.AuthRegistry); err != nil {
+		return nil, err
+	}
+	if g.MaxContractSize <= 0 || g.MaxContractSize > storage.MaxContractBytecodeSize {
+		return nil, fmt.Errorf("%w: must be between 1 and %d bytes", ErrInvalidMaxContractSize, storage.MaxContractBytecodeSize)
+	}
+	if g.WarpQuorumDenominator == 0 || g.WarpQuorumNumerator > g.WarpQuorumDenominator {
+		return nil, fmt.Errorf("%w: %d/%d", ErrInvalidWarpQuorum, g.WarpQuorumNumerator, g.WarpQuorumDenominator)
+	}
 	return g, nil
 }
 
@@ -0,0 +1,147 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package genesis
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/fees"
+
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+
+	_ "github.com/ava-labs/hypersdk/examples/typescriptvm/registry"
+)
+
+const upgradeBytes = `{
+	"upgrades": [
+		{
+			"timestamp": 1000,
+			"validityWindow": 30000,
+			"minUnitPrice": [1, 2, 3, 4, 5],
+			"enableActions": [3],
+			"disableAuths": [2]
+		},
+		{
+			"timestamp": 2000,
+			"validityWindow": 10000,
+			"enableAuths": [2]
+		}
+	]
+}`
+
+func TestUpgrades(t *testing.T) {
+	g, err := New(nil, []byte(upgradeBytes))
+	require.NoError(t, err)
+	require.NoError(t, g.Upgrades.Verify(consts.ActionRegistry, consts.AuthRegistry))
+	rules := func(t int64) chain.Rules {
+		return g.Rules(t, 1, ids.Empty)
+	}
+	require.NoError(t, g.Upgrades.VerifyApplied(rules))
+	cache := g.RulesCache(1, ids.Empty)
+
+	tests := []struct {
+		name           string
+		timestamp      int64
+		validityWindow int64
+		minUnitPrice   fees.Dimensions
+		upgradeAction  bool
+		blsAuth        bool
+	}{
+		{
+			name:           "genesis",
+			timestamp:      999,
+			validityWindow: Default().ValidityWindow,
+			minUnitPrice:   Default().MinUnitPrice,
+			blsAuth:        true,
+		},
+		{
+			name:           "first upgrade",
+			timestamp:      1000,
+			validityWindow: 30_000,
+			minUnitPrice:   fees.Dimensions{1, 2, 3, 4, 5},
+			upgradeAction:  true,
+		},
+		{
+			name:           "second upgrade",
+			timestamp:      2500,
+			validityWindow: 10_000,
+			minUnitPrice:   fees.Dimensions{1, 2, 3, 4, 5},
+			upgradeAction:  true,
+			blsAuth:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			r := rules(tt.timestamp)
+			require.Equal(r, cache.Rules(tt.timestamp))
+			require.Equal(tt.validityWindow, r.GetValidityWindow())
+			require.Equal(tt.minUnitPrice, r.GetMinUnitPrice())
+			require.Equal(Default().MaxBlockUnits, r.GetMaxBlockUnits())
+			registry, ok := r.(chain.RegistryRules)
+			require.True(ok)
+			require.True(registry.ActionEnabled(consts.TransferID))
+			require.Equal(tt.upgradeAction, registry.ActionEnabled(consts.UpgradeContractID))
+			require.True(registry.AuthEnabled(consts.ED25519ID))
+			require.Equal(tt.blsAuth, registry.AuthEnabled(consts.BLSID))
+
+			warpRules, ok := r.(chain.WarpRules)
+			require.True(ok)
+			require.Equal(Default().WarpBaseComputeUnits, warpRules.GetWarpBaseComputeUnits())
+
+			value, ok := r.FetchCustom(consts.MaxContractSizeRule)
+			require.True(ok)
+			require.Equal(Default().MaxContractSize, value)
+		})
+	}
+}
+
+func TestInvalidUpgrades(t *testing.T) {
+	tests := []struct {
+		name         string
+		genesisBytes string
+		upgradeBytes string
+	}{
+		{
+			name:         "unsorted",
+			upgradeBytes: `{"upgrades": [{"timestamp": 2000}, {"timestamp": 1000}]}`,
+		},
+		{
+			name:         "unregistered action",
+			upgradeBytes: `{"upgrades": [{"timestamp": 1000, "enableActions": [42]}]}`,
+		},
+		{
+			name:         "enabled and disabled",
+			upgradeBytes: `{"upgrades": [{"timestamp": 1000, "enableAuths": [1], "disableAuths": [1]}]}`,
+		},
+		{
+			name:         "zero validity window",
+			upgradeBytes: `{"upgrades": [{"timestamp": 1000, "validityWindow": 0}]}`,
+		},
+		{
+			name:         "in genesis",
+			genesisBytes: `{"upgrades": [{"timestamp": 1000, "enableActions": [42]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New([]byte(tt.genesisBytes), []byte(tt.upgradeBytes))
+			require.ErrorIs(t, err, chain.ErrInvalidUpgrade)
+		})
+	}
+
+	// Rules that ignore the upgrades are caught
+	require := require.New(t)
+	g, err := New(nil, []byte(upgradeBytes))
+	require.NoError(err)
+	err = g.Upgrades.VerifyApplied(func(int64) chain.Rules {
+		return &Rules{g, 1, ids.Empty}
+	})
+	require.ErrorIs(err, chain.ErrUpgradeNotApplied)
+}
@@ -4,14 +4,20 @@
 package genesis
 
 import (
+	"slices"
+
 	"github.com/ava-labs/avalanchego/ids"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
 	"github.com/ava-labs/hypersdk/fees"
 )
 
-var _ chain.Rules = (*Rules)(nil)
+var (
+	_ chain.Rules     = (*Rules)(nil)
+	_ chain.WarpRules = (*Rules)(nil)
+)
 
 type Rules struct {
 	g *Genesis
@@ -20,9 +26,15 @@ type Rules struct {
 	chainID   ids.ID
 }
 
-// TODO: use upgradeBytes
-func (g *Genesis) Rules(_ int64, networkID uint32, chainID ids.ID) *Rules {
-	return &Rules{g, networkID, chainID}
+// Rules returns the rules at [t], with the upgrades activated at [t] applied.
+func (g *Genesis) Rules(t int64, networkID uint32, chainID ids.ID) chain.Rules {
+	return g.Upgrades.Rules(&Rules{g, networkID, chainID}, t)
+}
+
+// RulesCache resolves the rules after each upgrade once, for callers that
+// look up the rules repeatedly.
+func (g *Genesis) RulesCache(networkID uint32, chainID ids.ID) *chain.RulesCache {
+	return g.Upgrades.Cache(&Rules{g, networkID, chainID})
 }
 
 func (r *Rules) NetworkID() uint32 {
@@ -101,6 +113,24 @@ func (r *Rules) GetWindowTargetUnits() fees.Dimensions {
 	return r.g.WindowTargetUnits
 }
 
-func (*Rules) FetchCustom(string) (any, bool) {
-	return nil, false
+func (r *Rules) FetchCustom(key string) (any, bool) {
+	switch key {
+	case consts.MaxContractSizeRule:
+		return r.g.MaxContractSize, true
+	default:
+		return nil, false
+	}
+}
+
+func (r *Rules) GetWarpConfig(sourceChainID ids.ID) (bool, uint64, uint64) {
+	allowed := slices.Contains(r.g.WarpSourceChains, sourceChainID)
+	return allowed, r.g.WarpQuorumNumerator, r.g.WarpQuorumDenominator
+}
+
+func (r *Rules) GetWarpBaseComputeUnits() uint64 {
+	return r.g.WarpBaseComputeUnits
+}
+
+func (r *Rules) GetWarpComputeUnitsPerSigner() uint64 {
+	return r.g.WarpComputeUnitsPerSigner
 }
@@ -24,11 +24,13 @@ func init() {
 		consts.ActionRegistry.Register((&actions.Transfer{}).GetTypeID(), actions.UnmarshalTransfer, false),
 		consts.ActionRegistry.Register((&actions.CreateContract{}).GetTypeID(), actions.UnmarshalCreateContract, false),
 		consts.ActionRegistry.Register((&actions.ExecuteContract{}).GetTypeID(), actions.UnmarshalExecuteContract, false),
+		consts.ActionRegistry.Register((&actions.UpgradeContract{}).GetTypeID(), actions.UnmarshalUpgradeContract, false),
 
 		// When registering new auth, ALWAYS make sure to append at the end.
 		consts.AuthRegistry.Register((&auth.ED25519{}).GetTypeID(), auth.UnmarshalED25519, false),
 		consts.AuthRegistry.Register((&auth.SECP256R1{}).GetTypeID(), auth.UnmarshalSECP256R1, false),
 		consts.AuthRegistry.Register((&auth.BLS{}).GetTypeID(), auth.UnmarshalBLS, false),
+		consts.AuthRegistry.Register((&auth.SmartContract{}).GetTypeID(), auth.UnmarshalSmartContract, false),
 	)
 	if errs.Errored() {
 		panic(errs.Err)
@@ -10,8 +10,9 @@ import (
 	"github.com/ava-labs/avalanchego/trace"
 
 	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/genesis"
-	"github.com/ava-labs/hypersdk/examples/typescriptvm/runtime"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
 	"github.com/ava-labs/hypersdk/fees"
 )
 
@@ -21,5 +22,11 @@ type Controller interface {
 	GetTransaction(context.Context, ids.ID) (bool, int64, bool, fees.Dimensions, uint64, error)
 	GetBalanceFromState(context.Context, codec.Address) (uint64, error)
 	GetContractBytecodeFromState(context.Context, codec.Address) ([]byte, error)
-	ExecuteContractOnState(context.Context, codec.Address, codec.Address, []byte, string) (*runtime.JavyExecResult, error)
+	GetContractMetadataFromState(context.Context, codec.Address) (*storage.ContractMetadata, error)
+	GetContractABIFromState(context.Context, codec.Address) ([]byte, error)
+	GetContractStateFromState(context.Context, codec.Address, [][]byte) ([][]byte, error)
+	GetContractStorage(context.Context, codec.Address, []byte, int) ([]*storage.ContractStateEntry, []byte, error)
+	ExecuteContractOnState(context.Context, codec.Address, codec.Address, []byte, string, uint64) (*actions.Simulation, error)
+	ViewContract(context.Context, codec.Address, codec.Address, string, []byte, uint64) (*actions.ViewResult, error)
+	GetEvents(context.Context, codec.Address, string, uint64, uint64, int) ([]*storage.StoredEvent, error)
 }
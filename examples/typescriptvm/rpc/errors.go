@@ -5,4 +5,10 @@ package rpc
 
 import "errors"
 
-var ErrTxNotFound = errors.New("tx not found")
+var (
+	ErrTxNotFound         = errors.New("tx not found")
+	ErrInvalidHeightRange = errors.New("invalid height range")
+	ErrTooManySlots       = errors.New("too many slots")
+	ErrContractCallFailed = errors.New("contract call failed")
+	ErrHeightUnavailable  = errors.New("state at height is unavailable")
+)
@@ -5,6 +5,7 @@ package rpc
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -13,12 +14,17 @@ import (
 	"github.com/ava-labs/hypersdk/state"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/abi"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/genesis"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/storage"
 	"github.com/ava-labs/hypersdk/requester"
 	"github.com/ava-labs/hypersdk/rpc"
 	"github.com/ava-labs/hypersdk/utils"
+
+	hconsts "github.com/ava-labs/hypersdk/consts"
 )
 
 type JSONRPCClient struct {
@@ -132,7 +138,7 @@ var _ chain.Parser = (*Parser)(nil)
 type Parser struct {
 	networkID uint32
 	chainID   ids.ID
-	genesis   *genesis.Genesis
+	rules     *chain.RulesCache
 }
 
 func (p *Parser) ChainID() ids.ID {
@@ -140,7 +146,7 @@ func (p *Parser) ChainID() ids.ID {
 }
 
 func (p *Parser) Rules(t int64) chain.Rules {
-	return p.genesis.Rules(t, p.networkID, p.chainID)
+	return p.rules.Rules(t)
 }
 
 func (*Parser) Registry() (chain.ActionRegistry, chain.AuthRegistry) {
@@ -156,7 +162,7 @@ func (cli *JSONRPCClient) Parser(ctx context.Context) (chain.Parser, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Parser{cli.networkID, cli.chainID, g}, nil
+	return &Parser{cli.networkID, cli.chainID, g.RulesCache(cli.networkID, cli.chainID)}, nil
 }
 
 func (cli *JSONRPCClient) ContractBytecode(ctx context.Context, addr string) ([]byte, error) {
@@ -172,6 +178,68 @@ func (cli *JSONRPCClient) ContractBytecode(ctx context.Context, addr string) ([]
 	return resp.Bytecode, err
 }
 
+func (cli *JSONRPCClient) ContractMetadata(ctx context.Context, addr string) (*ContractMetadataReply, error) {
+	resp := new(ContractMetadataReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"contractMetadata",
+		&ContractMetadataArgs{
+			Address: addr,
+		},
+		resp,
+	)
+	return resp, err
+}
+
+// ContractABI returns the ABI the contract at [addr] was deployed with, or
+// nil if it has none.
+func (cli *JSONRPCClient) ContractABI(ctx context.Context, addr string) (*abi.ABI, error) {
+	resp := new(ContractABIReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"contractABI",
+		&ContractABIArgs{
+			Address: addr,
+		},
+		resp,
+	)
+	return resp.ABI, err
+}
+
+// ContractState returns the values of [slots] of the contract at [addr], nil
+// for missing slots.
+func (cli *JSONRPCClient) ContractState(ctx context.Context, addr string, slots [][]byte) ([][]byte, error) {
+	resp := new(ContractStateReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"contractState",
+		&ContractStateArgs{
+			Address: addr,
+			Slots:   slots,
+		},
+		resp,
+	)
+	return resp.Values, err
+}
+
+// ContractStorageDump returns a page of up to [limit] slots of the contract
+// at [addr], starting at [cursor], and the cursor of the next page, which is
+// nil after the last page.
+func (cli *JSONRPCClient) ContractStorageDump(ctx context.Context, addr string, cursor []byte, limit int) ([]ContractStateEntryReply, []byte, error) {
+	resp := new(ContractStorageDumpReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"contractStorageDump",
+		&ContractStorageDumpArgs{
+			Address: addr,
+			Cursor:  cursor,
+			Limit:   limit,
+		},
+		resp,
+	)
+	return resp.Entries, resp.Cursor, err
+}
+
 type ExecuteContractClientReply struct {
 	DebugLog          string
 	Result            []byte
@@ -179,9 +247,12 @@ type ExecuteContractClientReply struct {
 	Error             string
 	Keys              map[string]state.Permissions
 	ComputeUnitsSpent uint64
+	Callees           []actions.ContractStateKeys
+	Recipients        []codec.Address
+	Events            []EventReply
 }
 
-func (cli *JSONRPCClient) ExecuteContract(ctx context.Context, addr string, funcName string, input []byte, actor string) (ExecuteContractClientReply, error) {
+func (cli *JSONRPCClient) ExecuteContract(ctx context.Context, addr string, funcName string, input []byte, actor string, value uint64) (ExecuteContractClientReply, error) {
 	originalResp := new(ExecuteContractReply)
 	err := cli.requester.SendRequest(
 		ctx,
@@ -191,10 +262,37 @@ func (cli *JSONRPCClient) ExecuteContract(ctx context.Context, addr string, func
 			Payload:         input,
 			FunctionName:    funcName,
 			Actor:           actor,
+			Value:           value,
 		},
 		originalResp,
 	)
+	if err != nil {
+		return ExecuteContractClientReply{}, err
+	}
+	return newExecuteContractClientReply(originalResp)
+}
+
+// View runs the view [funcName] of the contract at [addr] against the state
+// after the block at [height], or the last accepted block if 0. [actor] may
+// be empty.
+func (cli *JSONRPCClient) View(ctx context.Context, addr string, funcName string, input []byte, actor string, height uint64) (*ViewReply, error) {
+	resp := new(ViewReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"view",
+		&ViewArgs{
+			ContractAddress: addr,
+			FunctionName:    funcName,
+			Payload:         input,
+			Actor:           actor,
+			Height:          height,
+		},
+		resp,
+	)
+	return resp, err
+}
 
+func newExecuteContractClientReply(originalResp *ExecuteContractReply) (ExecuteContractClientReply, error) {
 	resp := new(ExecuteContractClientReply)
 	resp.DebugLog = originalResp.DebugLog
 	resp.Result = originalResp.Result
@@ -202,18 +300,98 @@ func (cli *JSONRPCClient) ExecuteContract(ctx context.Context, addr string, func
 	resp.Error = originalResp.Error
 	resp.ComputeUnitsSpent = originalResp.ComputeUnitsSpent
 
-	resp.Keys = make(map[string]state.Permissions)
+	resp.Keys = keysWithPermissions(originalResp.ReadKeys, originalResp.UpdatedKeys)
 
-	for _, key := range originalResp.ReadKeys {
-		resp.Keys[string(key)] = state.Read
+	resp.Callees = make([]actions.ContractStateKeys, 0, len(originalResp.Callees))
+	for _, callee := range originalResp.Callees {
+		addr, err := codec.ParseAddressBech32(consts.HRP, callee.ContractAddress)
+		if err != nil {
+			return *resp, err
+		}
+		resp.Callees = append(resp.Callees, actions.ContractStateKeys{
+			ContractAddress: addr,
+			Keys:            keysWithPermissions(callee.ReadKeys, callee.UpdatedKeys),
+		})
 	}
-	for _, key := range originalResp.UpdatedKeys {
-		if _, hadRead := (resp.Keys)[string(key)]; !hadRead {
-			resp.Keys[string(key)] = state.Write | state.Allocate
-		} else {
-			resp.Keys[string(key)] = state.Write | state.Allocate | state.Read
+
+	resp.Recipients = make([]codec.Address, 0, len(originalResp.Recipients))
+	for _, recipient := range originalResp.Recipients {
+		addr, err := codec.ParseAddressBech32(consts.HRP, recipient)
+		if err != nil {
+			return *resp, err
 		}
+		resp.Recipients = append(resp.Recipients, addr)
 	}
 
-	return *resp, err
+	resp.Events = originalResp.Events
+
+	return *resp, nil
+}
+
+// PrepareExecuteContract simulates a call and returns an [actions.ExecuteContract]
+// to execute it on-chain, with its state keys, balances and compute units
+// declared. It fails if the simulated call fails.
+func (cli *JSONRPCClient) PrepareExecuteContract(ctx context.Context, addr string, funcName string, input []byte, actor string, value uint64) (*actions.ExecuteContract, ExecuteContractClientReply, error) {
+	originalResp := new(PrepareExecuteContractReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"prepareExecuteContract",
+		&ExecuteContractArgs{
+			ContractAddress: addr,
+			Payload:         input,
+			FunctionName:    funcName,
+			Actor:           actor,
+			Value:           value,
+		},
+		originalResp,
+	)
+	if err != nil {
+		return nil, ExecuteContractClientReply{}, err
+	}
+	resp, err := newExecuteContractClientReply(&originalResp.ExecuteContractReply)
+	if err != nil {
+		return nil, resp, err
+	}
+	if !resp.Success {
+		return nil, resp, fmt.Errorf("%w: %s", ErrContractCallFailed, resp.Error)
+	}
+	action, err := actions.UnmarshalExecuteContract(codec.NewReader(originalResp.Action, hconsts.NetworkSizeLimit))
+	if err != nil {
+		return nil, resp, err
+	}
+	return action.(*actions.ExecuteContract), resp, nil
+}
+
+// Events returns the events of [contract] emitted between [fromHeight] and
+// [toHeight] (inclusive). If [topic] is empty, events of every topic are
+// returned.
+func (cli *JSONRPCClient) Events(ctx context.Context, contract string, topic string, fromHeight uint64, toHeight uint64) ([]EventReply, error) {
+	resp := new(EventsReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"events",
+		&EventsArgs{
+			Contract:   contract,
+			Topic:      topic,
+			FromHeight: fromHeight,
+			ToHeight:   toHeight,
+		},
+		resp,
+	)
+	return resp.Events, err
+}
+
+func keysWithPermissions(readKeys [][]byte, updatedKeys [][]byte) map[string]state.Permissions {
+	keys := make(map[string]state.Permissions)
+	for _, key := range readKeys {
+		keys[string(key)] = state.Read
+	}
+	for _, key := range updatedKeys {
+		if _, hadRead := keys[string(key)]; !hadRead {
+			keys[string(key)] = state.Write | state.Allocate
+		} else {
+			keys[string(key)] = state.Write | state.Allocate | state.Read
+		}
+	}
+	return keys
 }
@@ -4,15 +4,20 @@
 package rpc
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/ava-labs/avalanchego/ids"
 
 	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/typescriptvm/abi"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/actions"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
 	"github.com/ava-labs/hypersdk/examples/typescriptvm/genesis"
 	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+
+	hconsts "github.com/ava-labs/hypersdk/consts"
 )
 
 type JSONRPCServer struct {
@@ -109,11 +114,156 @@ func (j *JSONRPCServer) ContractBytecode(req *http.Request, args *ContractByteco
 	return err
 }
 
+type ContractMetadataArgs struct {
+	Address string `json:"address"`
+}
+
+type ContractMetadataReply struct {
+	Admin     string `json:"admin"`
+	Immutable bool   `json:"immutable"`
+	Version   uint32 `json:"version"`
+	CodeHash  ids.ID `json:"codeHash"`
+}
+
+func (j *JSONRPCServer) ContractMetadata(req *http.Request, args *ContractMetadataArgs, reply *ContractMetadataReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.ContractMetadata")
+	defer span.End()
+
+	addr, err := codec.ParseAddressBech32(consts.HRP, args.Address)
+	if err != nil {
+		return err
+	}
+	md, err := j.c.GetContractMetadataFromState(ctx, addr)
+	if err != nil {
+		return err
+	}
+	reply.Admin = codec.MustAddressBech32(consts.HRP, md.Admin)
+	reply.Immutable = md.Immutable
+	reply.Version = md.Version
+	reply.CodeHash = md.CodeHash
+	return nil
+}
+
+type ContractABIArgs struct {
+	Address string `json:"address"`
+}
+
+type ContractABIReply struct {
+	// ABI is null if the contract was deployed without one
+	ABI *abi.ABI `json:"abi"`
+}
+
+func (j *JSONRPCServer) ContractABI(req *http.Request, args *ContractABIArgs, reply *ContractABIReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.ContractABI")
+	defer span.End()
+
+	addr, err := codec.ParseAddressBech32(consts.HRP, args.Address)
+	if err != nil {
+		return err
+	}
+	b, err := j.c.GetContractABIFromState(ctx, addr)
+	if err != nil || len(b) == 0 {
+		return err
+	}
+	reply.ABI, err = abi.Parse(b)
+	return err
+}
+
+// maxContractStateSlots bounds the slots read by a single
+// [JSONRPCServer.ContractState] call.
+const maxContractStateSlots = 256
+
+type ContractStateArgs struct {
+	Address string   `json:"address"`
+	Slots   [][]byte `json:"slots"`
+}
+
+type ContractStateReply struct {
+	Values [][]byte `json:"values"`
+}
+
+// ContractState returns the values of [ContractStateArgs.Slots], which
+// include their chunks suffix. Missing slots have a null value.
+func (j *JSONRPCServer) ContractState(req *http.Request, args *ContractStateArgs, reply *ContractStateReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.ContractState")
+	defer span.End()
+
+	if len(args.Slots) > maxContractStateSlots {
+		return ErrTooManySlots
+	}
+	addr, err := codec.ParseAddressBech32(consts.HRP, args.Address)
+	if err != nil {
+		return err
+	}
+	values, err := j.c.GetContractStateFromState(ctx, addr, args.Slots)
+	if err != nil {
+		return err
+	}
+	reply.Values = values
+	return nil
+}
+
+// maxContractStorageDumpLimit bounds the slots returned by a single
+// [JSONRPCServer.ContractStorageDump] call.
+const maxContractStorageDumpLimit = 1024
+
+type ContractStorageDumpArgs struct {
+	Address string `json:"address"`
+
+	// Cursor is the slot to start at, as returned by the previous page
+	Cursor []byte `json:"cursor"`
+
+	// Limit defaults to, and is capped at, [maxContractStorageDumpLimit]
+	Limit int `json:"limit"`
+}
+
+type ContractStateEntryReply struct {
+	Slot  []byte `json:"slot"`
+	Value []byte `json:"value"`
+}
+
+type ContractStorageDumpReply struct {
+	Entries []ContractStateEntryReply `json:"entries"`
+
+	// Cursor is null once the last slot has been returned
+	Cursor []byte `json:"cursor"`
+}
+
+// ContractStorageDump lists the slots of a contract in key order, a page at a
+// time, as of the last accepted block.
+func (j *JSONRPCServer) ContractStorageDump(req *http.Request, args *ContractStorageDumpArgs, reply *ContractStorageDumpReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.ContractStorageDump")
+	defer span.End()
+
+	addr, err := codec.ParseAddressBech32(consts.HRP, args.Address)
+	if err != nil {
+		return err
+	}
+	limit := args.Limit
+	if limit <= 0 || limit > maxContractStorageDumpLimit {
+		limit = maxContractStorageDumpLimit
+	}
+	entries, cursor, err := j.c.GetContractStorage(ctx, addr, args.Cursor, limit)
+	if err != nil {
+		return err
+	}
+	reply.Entries = make([]ContractStateEntryReply, 0, len(entries))
+	for _, entry := range entries {
+		reply.Entries = append(reply.Entries, ContractStateEntryReply{
+			Slot:  entry.Slot,
+			Value: entry.Value,
+		})
+	}
+	reply.Cursor = cursor
+	return nil
+}
+
 type ExecuteContractArgs struct {
 	ContractAddress string `json:"contractAddress"`
 	FunctionName    string `json:"functionName"`
 	Payload         []byte `json:"payload"`
 	Actor           string `json:"actor"`
+	Value           uint64 `json:"value"`
 }
 
 type ExecuteContractReply struct {
@@ -124,25 +274,77 @@ type ExecuteContractReply struct {
 	UpdatedKeys       [][]byte `json:"updatedKeys"`
 	ReadKeys          [][]byte `json:"readKeys"`
 	ComputeUnitsSpent uint64   `json:"computeUnitsSpent"`
+
+	Callees    []CalleeKeysReply `json:"callees"`
+	Recipients []string          `json:"recipients"`
+	Events     []EventReply      `json:"events"`
+}
+
+// CalleeKeysReply lists the keys accessed by a contract called during the
+// execution.
+type CalleeKeysReply struct {
+	ContractAddress string   `json:"contractAddress"`
+	UpdatedKeys     [][]byte `json:"updatedKeys"`
+	ReadKeys        [][]byte `json:"readKeys"`
 }
 
 func (j *JSONRPCServer) ExecuteContract(req *http.Request, args *ExecuteContractArgs, reply *ExecuteContractReply) error {
 	ctx, span := j.c.Tracer().Start(req.Context(), "Server.ExecuteContract")
 	defer span.End()
 
+	_, _, err := j.simulateContract(ctx, args, reply)
+	return err
+}
+
+type PrepareExecuteContractReply struct {
+	ExecuteContractReply
+
+	// Action is the packed [actions.ExecuteContract] repeating the simulated
+	// call, ready to be included in a transaction. It is only set if the call
+	// succeeded.
+	Action []byte `json:"action"`
+}
+
+// PrepareExecuteContract simulates a call and returns the action to execute it
+// on-chain, declaring the state keys, balances and compute units it needs.
+func (j *JSONRPCServer) PrepareExecuteContract(req *http.Request, args *ExecuteContractArgs, reply *PrepareExecuteContractReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.PrepareExecuteContract")
+	defer span.End()
+
+	res, contractAddr, err := j.simulateContract(ctx, args, &reply.ExecuteContractReply)
+	if err != nil || !res.Result.Success {
+		return err
+	}
+
+	action := res.Action(contractAddr, args.FunctionName, args.Payload, args.Value)
+	p := codec.NewWriter(action.Size(), hconsts.NetworkSizeLimit)
+	action.Marshal(p)
+	if err := p.Err(); err != nil {
+		return err
+	}
+	reply.Action = p.Bytes()
+	reply.ComputeUnitsSpent = action.ComputeUnitsToSpend
+	return nil
+}
+
+func (j *JSONRPCServer) simulateContract(
+	ctx context.Context,
+	args *ExecuteContractArgs,
+	reply *ExecuteContractReply,
+) (*actions.Simulation, codec.Address, error) {
 	contractAddr, err := codec.ParseAddressBech32(consts.HRP, args.ContractAddress)
 	if err != nil {
-		return err
+		return nil, codec.EmptyAddress, err
 	}
 
 	actorAddr, err := codec.ParseAddressBech32(consts.HRP, args.Actor)
 	if err != nil {
-		return err
+		return nil, codec.EmptyAddress, err
 	}
 
-	res, err := j.c.ExecuteContractOnState(ctx, contractAddr, actorAddr, args.Payload, args.FunctionName)
+	res, err := j.c.ExecuteContractOnState(ctx, contractAddr, actorAddr, args.Payload, args.FunctionName, args.Value)
 	if err != nil {
-		return err
+		return nil, codec.EmptyAddress, err
 	}
 
 	reply.DebugLog = string(res.DebugLog)
@@ -161,8 +363,145 @@ func (j *JSONRPCServer) ExecuteContract(req *http.Request, args *ExecuteContract
 		reply.UpdatedKeys = append(reply.UpdatedKeys, []byte(key))
 	}
 
-	reply.ComputeUnitsSpent = res.FuelConsumed / 1_000_000 // TODO: move to consts
-	reply.ComputeUnitsSpent = max(actions.ExecuteContractMinComputeUnits, reply.ComputeUnitsSpent)
+	reply.ComputeUnitsSpent = actions.ComputeUnitsForFuel(res.FuelConsumed)
+
+	reply.Callees = make([]CalleeKeysReply, 0, len(res.Callees))
+	for _, callee := range res.Callees {
+		calleeReply := CalleeKeysReply{
+			ContractAddress: codec.MustAddressBech32(consts.HRP, callee.ContractAddress),
+			UpdatedKeys:     [][]byte{},
+			ReadKeys:        [][]byte{},
+		}
+		for key, perms := range callee.Keys {
+			if perms.Has(state.Read) {
+				calleeReply.ReadKeys = append(calleeReply.ReadKeys, []byte(key))
+			}
+			if perms.Has(state.Write) {
+				calleeReply.UpdatedKeys = append(calleeReply.UpdatedKeys, []byte(key))
+			}
+		}
+		reply.Callees = append(reply.Callees, calleeReply)
+	}
+
+	reply.Recipients = make([]string, 0, len(res.Recipients))
+	for _, recipient := range res.Recipients {
+		reply.Recipients = append(reply.Recipients, codec.MustAddressBech32(consts.HRP, recipient))
+	}
+
+	reply.Events = make([]EventReply, 0, len(res.Events))
+	for _, event := range res.Events {
+		reply.Events = append(reply.Events, EventReply{
+			Contract: codec.MustAddressBech32(consts.HRP, event.Emitter),
+			Topic:    event.Topic,
+			Data:     event.Data,
+		})
+	}
+
+	return res, contractAddr, nil
+}
+
+type ViewArgs struct {
+	ContractAddress string `json:"contractAddress"`
+	FunctionName    string `json:"functionName"`
+	Payload         []byte `json:"payload"`
+	// Actor is the caller seen by the view, the empty address if not set
+	Actor string `json:"actor"`
+	// Height selects the state after the accepted block at this height, or
+	// the last accepted block if 0. Only recent blocks can be queried.
+	Height uint64 `json:"height"`
+}
+
+type ViewReply struct {
+	// Height is the height of the block whose state was queried
+	Height       uint64 `json:"height"`
+	Result       []byte `json:"result"`
+	Success      bool   `json:"success"`
+	Error        string `json:"error"`
+	FuelConsumed uint64 `json:"fuelConsumed"`
+}
+
+// View runs a view function of a contract. Views are read-only: writes,
+// transfers and events fail, so that they can be served cheaply and their
+// results cached.
+func (j *JSONRPCServer) View(req *http.Request, args *ViewArgs, reply *ViewReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.View")
+	defer span.End()
+
+	contractAddr, err := codec.ParseAddressBech32(consts.HRP, args.ContractAddress)
+	if err != nil {
+		return err
+	}
+	actorAddr := codec.EmptyAddress
+	if args.Actor != "" {
+		actorAddr, err = codec.ParseAddressBech32(consts.HRP, args.Actor)
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := j.c.ViewContract(ctx, contractAddr, actorAddr, args.FunctionName, args.Payload, args.Height)
+	if err != nil {
+		return err
+	}
+	reply.Height = res.Height
+	reply.Result = res.Result.Result
+	reply.Success = res.Result.Success
+	reply.Error = res.Result.Error
+	reply.FuelConsumed = res.FuelConsumed
+	return nil
+}
+
+// maxEventsPerQuery bounds the events returned by a single [JSONRPCServer.Events] call.
+const maxEventsPerQuery = 1024
+
+type EventsArgs struct {
+	Contract string `json:"contract"`
+	// Topic filters the events, all topics are returned if empty
+	Topic      string `json:"topic"`
+	FromHeight uint64 `json:"fromHeight"`
+	ToHeight   uint64 `json:"toHeight"`
+}
+
+type EventReply struct {
+	// TxID and Height are not set for simulated events
+	TxID     ids.ID `json:"txId"`
+	Height   uint64 `json:"height"`
+	Contract string `json:"contract"`
+	Topic    string `json:"topic"`
+	Data     []byte `json:"data"`
+}
+
+type EventsReply struct {
+	// Events are returned in the order they were emitted. If [maxEventsPerQuery]
+	// events are returned, the next ones may be queried from the height of the
+	// last one.
+	Events []EventReply `json:"events"`
+}
+
+func (j *JSONRPCServer) Events(req *http.Request, args *EventsArgs, reply *EventsReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Events")
+	defer span.End()
 
+	if args.FromHeight > args.ToHeight {
+		return ErrInvalidHeightRange
+	}
+	addr, err := codec.ParseAddressBech32(consts.HRP, args.Contract)
+	if err != nil {
+		return err
+	}
+	events, err := j.c.GetEvents(ctx, addr, args.Topic, args.FromHeight, args.ToHeight, maxEventsPerQuery)
+	if err != nil {
+		return err
+	}
+	reply.Events = make([]EventReply, 0, len(events))
+	for _, stored := range events {
+		reply.Events = append(reply.Events, EventReply{
+			TxID:     stored.TxID,
+			Height:   stored.Height,
+			Contract: args.Contract,
+			Topic:    stored.Event.Topic,
+			Data:     stored.Event.Data,
+		})
+	}
 	return nil
 }
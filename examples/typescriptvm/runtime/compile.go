@@ -0,0 +1,110 @@
+package runtime
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bytecodealliance/wasmtime-go/v21"
+)
+
+var ErrUnsupportedFile = errors.New("unsupported contract file")
+
+var (
+	compilerOnce     sync.Once
+	compilerStore    *wasmtime.Store
+	compilerInstance *wasmtime.Instance
+	compilerErr      error
+	compilerLock     sync.Mutex
+)
+
+// CompileJS turns JS source into a dynamically linked Javy module, the same
+// shape `javy compile -d` produces, using the embedded provider to generate
+// the QuickJS bytecode. It lets tests and tools build contracts without the
+// Javy toolchain.
+func CompileJS(src string) ([]byte, error) {
+	compilerOnce.Do(func() {
+		engine := wasmtime.NewEngine()
+		module, err := wasmtime.NewModule(engine, javyProviderWasm)
+		if err != nil {
+			compilerErr = err
+			return
+		}
+		compilerStore = wasmtime.NewStore(engine)
+		compilerStore.SetWasi(wasmtime.NewWasiConfig())
+		linker := wasmtime.NewLinker(engine)
+		if err := linker.DefineWasi(); err != nil {
+			compilerErr = err
+			return
+		}
+		compilerInstance, compilerErr = linker.Instantiate(compilerStore, module)
+	})
+	if compilerErr != nil {
+		return nil, fmt.Errorf("instantiating javy compiler: %w", compilerErr)
+	}
+
+	compilerLock.Lock()
+	defer compilerLock.Unlock()
+
+	ptr, err := compilerInstance.GetFunc(compilerStore, "canonical_abi_realloc").
+		Call(compilerStore, int32(0), int32(0), int32(1), int32(len(src)))
+	if err != nil {
+		return nil, fmt.Errorf("allocating source: %w", err)
+	}
+	mem := compilerInstance.GetExport(compilerStore, "memory").Memory()
+	copy(mem.UnsafeData(compilerStore)[ptr.(int32):], src)
+
+	ret, err := compilerInstance.GetFunc(compilerStore, "compile_src").
+		Call(compilerStore, ptr, int32(len(src)))
+	if err != nil {
+		return nil, fmt.Errorf("compiling source: %w", err)
+	}
+	data := mem.UnsafeData(compilerStore)
+	bytecodePtr := binary.LittleEndian.Uint32(data[ret.(int32):])
+	bytecodeLen := binary.LittleEndian.Uint32(data[ret.(int32)+4:])
+
+	var escaped strings.Builder
+	for _, b := range data[bytecodePtr : bytecodePtr+bytecodeLen] {
+		fmt.Fprintf(&escaped, "\\%02x", b)
+	}
+	return wasmtime.Wat2Wasm(fmt.Sprintf(`(module
+  (import "javy_quickjs_provider_v2" "canonical_abi_realloc" (func $realloc (param i32 i32 i32 i32) (result i32)))
+  (import "javy_quickjs_provider_v2" "eval_bytecode" (func $eval (param i32 i32)))
+  (import "javy_quickjs_provider_v2" "memory" (memory 0))
+  (data $bytecode "%s")
+  (func (export "_start") (local $ptr i32)
+    (local.set $ptr (call $realloc (i32.const 0) (i32.const 0) (i32.const 1) (i32.const %[2]d)))
+    (memory.init $bytecode (local.get $ptr) (i32.const 0) (i32.const %[2]d))
+    (data.drop $bytecode)
+    (call $eval (local.get $ptr) (i32.const %[2]d))))`, escaped.String(), bytecodeLen))
+
+}
+
+// CompileFile reads a wasm module, or compiles JS and TypeScript sources.
+// TypeScript is bundled with esbuild, like the js_sdk build script does, so
+// it requires node.
+func CompileFile(path string) ([]byte, error) {
+	switch filepath.Ext(path) {
+	case ".wasm":
+		return os.ReadFile(path)
+	case ".js":
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return CompileJS(string(src))
+	case ".ts":
+		out, err := exec.Command("npx", "esbuild", path, "--bundle").Output()
+		if err != nil {
+			return nil, fmt.Errorf("bundling %s: %w", path, err)
+		}
+		return CompileJS(string(out))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFile, path)
+	}
+}
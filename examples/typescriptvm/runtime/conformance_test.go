@@ -0,0 +1,131 @@
+package runtime
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateConformance = flag.Bool("update-conformance", false, "rewrite testdata/conformance/fuel.json")
+
+const (
+	conformanceDir     = "testdata/conformance"
+	conformanceRepeats = 4
+)
+
+type conformanceCase struct {
+	name     string
+	bytecode []byte
+}
+
+func loadConformanceCorpus(t *testing.T) []conformanceCase {
+	prelude, err := os.ReadFile(filepath.Join(conformanceDir, "prelude.js"))
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(conformanceDir, "*.js"))
+	require.NoError(t, err)
+	sort.Strings(files)
+
+	cases := []conformanceCase{}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".js")
+		if name == "prelude" {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		require.NoError(t, err)
+		cases = append(cases, conformanceCase{
+			name:     name,
+			bytecode: compileJS(t, string(prelude)+"\n"+string(src)),
+		})
+	}
+	return cases
+}
+
+func runConformanceCase(exec *JavyExec, c conformanceCase) (*JavyExecResult, error) {
+	provider := NewDummyStateProvider()
+	provider.Update(map[string][]byte{
+		string([]byte{2, 0, 1}): {40},
+		string([]byte{5, 0, 1}): {2},
+	})
+	return exec.Execute(JavyExecParams{
+		MaxFuel:       100_000_000,
+		MaxMemory:     64 * 1024 * 1024,
+		Bytecode:      &c.bytecode,
+		StateProvider: provider.StateProvider,
+		Payload:       []byte{1, 2, 3},
+		FunctionName:  c.name,
+		Actor:         []byte{1, 2, 3, 4},
+	})
+}
+
+// TestConformance ensures that fuel and results are reproducible: across
+// repeated and concurrent runs sharing compiled modules, with a freshly
+// compiled provider and against the recorded fuel of every case. A change in the recorded fuel means that
+// validators running different versions would disagree.
+func TestConformance(t *testing.T) {
+	require := require.New(t)
+	cases := loadConformanceCorpus(t)
+
+	fresh := NewJavyExec()
+	defer fresh.Close()
+	expected := make(map[string]*JavyExecResult, len(cases))
+	for _, c := range cases {
+		res, err := runConformanceCase(fresh, c)
+		require.NoError(err, c.name)
+		require.True(res.Result.Success, "%s: %s", c.name, res.Result.Error)
+		res.TimeTaken = 0
+		expected[c.name] = res
+	}
+
+	shared := NewJavyExec()
+	defer shared.Close()
+	var wg sync.WaitGroup
+	results := make([][]*JavyExecResult, len(cases))
+	errs := make([][]error, len(cases))
+	for i, c := range cases {
+		results[i] = make([]*JavyExecResult, conformanceRepeats)
+		errs[i] = make([]error, conformanceRepeats)
+		for j := 0; j < conformanceRepeats; j++ {
+			wg.Add(1)
+			go func(i, j int, c conformanceCase) {
+				defer wg.Done()
+				results[i][j], errs[i][j] = runConformanceCase(shared, c)
+			}(i, j, c)
+		}
+	}
+	wg.Wait()
+
+	for i, c := range cases {
+		for j := 0; j < conformanceRepeats; j++ {
+			require.NoError(errs[i][j], c.name)
+			res := results[i][j]
+			res.TimeTaken = 0
+			require.Equal(expected[c.name], res, "%s: run %d diverged", c.name, j)
+		}
+	}
+
+	fuel := make(map[string]uint64, len(cases))
+	for name, res := range expected {
+		fuel[name] = res.FuelConsumed
+	}
+	fuelPath := filepath.Join(conformanceDir, "fuel.json")
+	if *updateConformance {
+		b, err := json.MarshalIndent(fuel, "", "  ")
+		require.NoError(err)
+		require.NoError(os.WriteFile(fuelPath, append(b, '\n'), 0o600))
+		return
+	}
+	b, err := os.ReadFile(fuelPath)
+	require.NoError(err)
+	recorded := map[string]uint64{}
+	require.NoError(json.Unmarshal(b, &recorded))
+	require.Equal(recorded, fuel, "fuel schedule changed, run with -update-conformance if intended")
+}
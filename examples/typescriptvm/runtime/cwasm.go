@@ -2,55 +2,87 @@ package runtime
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
-	"sync"
 
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/bytecodealliance/wasmtime-go/v21"
 )
 
 const WASMTIME_VERSION = "v21"
 
-//go:generate bash -c "test -f ./javy_provider.wasm || curl -L https://github.com/bytecodealliance/javy/releases/download/v3.0.0/javy-quickjs_provider.wasm.gz | gunzip > ./javy_provider.wasm"
+// moduleCacheSize is the number of compiled contracts kept per [JavyExec].
+const moduleCacheSize = 128
 
-//go:embed javy_provider.wasm
-var javyProviderWasm []byte
+// providerModuleName is the name contracts import the Javy provider under.
+// Contracts reach WASI and the host functions through it only.
+const providerModuleName = "javy_quickjs_provider_v2"
 
-var javyProviderCompiled *[]byte = nil
+var ErrInvalidModule = errors.New("invalid contract module")
 
-var compileWasmMutex = sync.Mutex{}
-
-func getCwasmBytes() (*[]byte, error) {
-	if javyProviderCompiled != nil {
-		return javyProviderCompiled, nil
-	}
+//go:generate bash -c "test -f ./javy_provider.wasm || curl -L https://github.com/bytecodealliance/javy/releases/download/v3.0.0/javy-quickjs_provider.wasm.gz | gunzip > ./javy_provider.wasm"
 
-	compileWasmMutex.Lock()
-	defer compileWasmMutex.Unlock()
+//go:embed javy_provider.wasm
+var javyProviderWasm []byte
 
-	if javyProviderCompiled != nil {
-		return javyProviderCompiled, nil
+// providerModule compiles the Javy provider once per engine.
+func (exec *JavyExec) providerModule() (*wasmtime.Module, error) {
+	exec.providerOnce.Do(func() {
+		exec.provider, exec.providerErr = wasmtime.NewModule(exec.engine, javyProviderWasm)
+	})
+	if exec.providerErr != nil {
+		return nil, fmt.Errorf("instantiating javy provider module: %v", exec.providerErr)
 	}
+	return exec.provider, nil
+}
 
-	config := wasmtime.NewConfig()
-	config.SetConsumeFuel(true)
-
-	engine := wasmtime.NewEngineWithConfig(config)
-
-	javyProviderModule, err := wasmtime.NewModule(engine, javyProviderWasm)
-	if err != nil {
-		return nil, fmt.Errorf("instantiating javy provider module: %v", err)
+// userModule returns the compiled contract for [bytecode], compiling it if it
+// is not cached yet. Compiled modules hold no state, so they are safe to share
+// between calls.
+func (exec *JavyExec) userModule(bytecode []byte) (*wasmtime.Module, error) {
+	id := ids.ID(hashing.ComputeHash256Array(bytecode))
+	if module, ok := exec.modules.Get(id); ok {
+		return module, nil
 	}
-
-	compiledJavyProviderWasm, err := javyProviderModule.Serialize()
+	module, err := wasmtime.NewModule(exec.engine, bytecode)
 	if err != nil {
-		return nil, fmt.Errorf("serializing javy provider module: %v", err)
+		return nil, fmt.Errorf("instantiating user code module: %v", err)
 	}
+	exec.modules.Put(id, module)
+	return module, nil
+}
 
-	javyProviderCompiled = &compiledJavyProviderWasm
-
-	return javyProviderCompiled, nil
+// Precompile compiles [bytecode] ahead of its first call, e.g. when the
+// contract is created.
+func (exec *JavyExec) Precompile(bytecode []byte) error {
+	_, err := exec.userModule(bytecode)
+	return err
 }
 
-func getCwasmCachePath() (string, error) {
-	return "", nil
+// Validate compiles [bytecode] and checks that it is a dynamically linked Javy
+// module: it may only import from the provider and must export a _start
+// function without parameters or results. The compiled module is cached, like
+// [JavyExec.Precompile] does.
+func (exec *JavyExec) Validate(bytecode []byte) error {
+	module, err := exec.userModule(bytecode)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidModule, err)
+	}
+	for _, imp := range module.Imports() {
+		if imp.Module() != providerModuleName {
+			return fmt.Errorf("%w: imports from %q", ErrInvalidModule, imp.Module())
+		}
+	}
+	for _, exp := range module.Exports() {
+		if exp.Name() != "_start" {
+			continue
+		}
+		fn := exp.Type().FuncType()
+		if fn == nil || len(fn.Params()) != 0 || len(fn.Results()) != 0 {
+			return fmt.Errorf("%w: _start is not a function without parameters or results", ErrInvalidModule)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: _start is not exported", ErrInvalidModule)
 }
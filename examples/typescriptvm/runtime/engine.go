@@ -0,0 +1,50 @@
+package runtime
+
+import (
+	"time"
+
+	"github.com/bytecodealliance/wasmtime-go/v21"
+)
+
+// Fuel schedule
+//
+// Fuel is the only resource measured during consensus execution, so it must
+// be identical on every validator:
+//   - every executed wasm operator costs 1 fuel (wasmtime's default schedule,
+//     which ignores nop, drop, block and loop)
+//   - every host call costs [HostCallFuel] plus [HostCallFuelPerByte] for each
+//     byte exchanged with the host
+//
+// Wall-clock limits ([JavyExecParams.MaxTime]) interrupt execution
+// non-deterministically and must only be used outside of consensus.
+const (
+	HostCallFuel        = 1_000
+	HostCallFuelPerByte = 1
+
+	// maxWasmStack is pinned so that stack exhaustion happens at the same
+	// depth regardless of the wasmtime defaults.
+	maxWasmStack = 512 * 1024
+
+	// noEpochDeadline is used when no wall-clock limit is requested.
+	noEpochDeadline = 1 << 62
+
+	// epochTick is the resolution of wall-clock limits.
+	epochTick = time.Millisecond
+)
+
+// newEngineConfig returns the pinned configuration used to compile and run
+// contracts. Any change here may alter fuel consumption and must be treated
+// as a network upgrade.
+func newEngineConfig() *wasmtime.Config {
+	config := wasmtime.NewConfig()
+	config.SetConsumeFuel(true)
+	config.SetEpochInterruption(true)
+	config.SetStrategy(wasmtime.StrategyCranelift)
+	config.SetCraneliftOptLevel(wasmtime.OptLevelSpeed)
+	config.EnableCraneliftFlag("enable_nan_canonicalization")
+	config.SetMaxWasmStack(maxWasmStack)
+	config.SetWasmThreads(false)
+	config.SetWasmMemory64(false)
+	config.SetWasmMultiMemory(false)
+	return config
+}
@@ -1,15 +1,17 @@
 package runtime
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"os"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/bytecodealliance/wasmtime-go/v21"
+	"go.uber.org/zap"
 )
 
 type JavyExecResult struct {
@@ -19,143 +21,128 @@ type JavyExecResult struct {
 	DebugLog     []byte
 }
 
+// JavyExec runs contracts on a shared engine. Compiled modules are cached and
+// reused, while every call gets a fresh store. It is safe for concurrent use.
 type JavyExec struct {
-	executeMutexes map[uint64]*sync.Mutex
-	storesCache    map[uint64]*struct {
-		store    *wasmtime.Store
-		mainFunc *wasmtime.Func
-	}
-}
+	engine *wasmtime.Engine
 
-func NewJavyExec() *JavyExec {
-	return &JavyExec{
-		executeMutexes: map[uint64]*sync.Mutex{},
-		storesCache: map[uint64]*struct {
-			store    *wasmtime.Store
-			mainFunc *wasmtime.Func
-		}{},
-	}
-}
+	providerOnce sync.Once
+	provider     *wasmtime.Module
+	providerErr  error
 
-func (exec *JavyExec) Execute(params JavyExecParams) (*JavyExecResult, error) {
-	state := make(map[string][]byte)
+	modules *cache.LRU[ids.ID, *wasmtime.Module]
 
-	for i := 0; i < 100; i++ { //curcuit breaker
-		res, err := exec.executeOnState(params, state)
-		if err != nil {
-			return nil, err
-		}
+	epochOnce sync.Once
+	epochStop chan struct{}
+	closeOnce sync.Once
 
-		if strings.Contains(res.Result.Error, "NO_VALUE_AT_ADDRESS") {
-			addrHex := strings.Split(res.Result.Error, "\"")[1]
-
-			addressBytes, err := hex.DecodeString(strings.TrimPrefix(addrHex, "0x"))
-			if err != nil {
-				return nil, fmt.Errorf("error decoding address %s: %v", addrHex, err)
-			}
-
-			newVal, err := params.StateProvider(string(addressBytes))
-			if err != nil {
-				return nil, fmt.Errorf("error retrieving state for address %x: %v", addressBytes, err)
-			}
-			state[string(addressBytes)] = newVal
+	// log receives the output of contracts at debug level
+	log atomic.Pointer[logging.Logger]
+}
 
-			fmt.Printf("state %+v", state)
-		} else {
-			return res, nil
-		}
+func NewJavyExec() *JavyExec {
+	return &JavyExec{
+		engine:    wasmtime.NewEngineWithConfig(newEngineConfig()),
+		modules:   &cache.LRU[ids.ID, *wasmtime.Module]{Size: moduleCacheSize},
+		epochStop: make(chan struct{}),
 	}
+}
 
-	return nil, fmt.Errorf("execution failed after 100 attempts")
+// SetLogger logs the output of contracts to [log] at debug level. Nothing is
+// logged by default.
+func (exec *JavyExec) SetLogger(log logging.Logger) {
+	exec.log.Store(&log)
 }
 
-func (exec *JavyExec) executeOnState(params JavyExecParams, state map[string][]byte) (*JavyExecResult, error) {
-	store, mainFunc, err := exec.createStore(params.Bytecode)
-	if err != nil {
-		return nil, err
+func (exec *JavyExec) logger() logging.Logger {
+	if log := exec.log.Load(); log != nil {
+		return *log
 	}
+	return logging.NoLog{}
+}
 
-	store.Limiter(params.MaxMemory, 629, 2, 1, 1)
+// Close stops the epoch ticker. The [JavyExec] must not be used afterwards.
+func (exec *JavyExec) Close() {
+	exec.closeOnce.Do(func() {
+		close(exec.epochStop)
+	})
+}
 
-	if !EXECUTE_CACHE_ENABLED {
-		defer store.Engine.Close()
-		defer store.Close()
-	}
+// startEpochTicker advances the engine epoch every [epochTick]. Stores sharing
+// the engine express their wall-clock limits in ticks, so no call can cut
+// another one short.
+func (exec *JavyExec) startEpochTicker() {
+	exec.epochOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(epochTick)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					exec.engine.IncrementEpoch()
+				case <-exec.epochStop:
+					return
+				}
+			}
+		}()
+	})
+}
 
+func (exec *JavyExec) Execute(params JavyExecParams) (*JavyExecResult, error) {
 	callDataJson, err := json.Marshal(JSPayload{
-		CurrentState: &state,
 		Payload:      params.Payload,
 		FunctionName: params.FunctionName,
 		Actor:        params.Actor,
+		Contract:     params.Contract,
+		Value:        params.Value,
+		Height:       params.Block.Height,
+		Timestamp:    params.Block.Timestamp,
+		ChainID:      params.Block.ChainID,
+		ActionID:     params.Block.ActionID,
+		ReadOnly:     params.ReadOnly,
 	})
-
 	if err != nil {
 		return nil, fmt.Errorf("marshalling call data: %v", err)
 	}
 
-	//FIXME: use syscall.Mkfifo instead of torturing disk with temp files
-	stdoutFile, err := os.CreateTemp("", "stdout*")
-	if err != nil {
-		return nil, fmt.Errorf("creating stdout file: %v", err)
-	}
-	defer os.Remove(stdoutFile.Name())
+	host := newHostState(params.StateProvider, callDataJson)
+	host.caller = params.ContractCaller
+	host.balanceOf = params.BalanceOf
+	host.transfer = params.Transfer
+	host.emit = params.Emit
+	host.readOnly = params.ReadOnly
 
-	stderrFile, err := os.CreateTemp("", "stderr*")
+	store, mainFunc, err := exec.createStore(params.Bytecode, host)
 	if err != nil {
-		return nil, fmt.Errorf("creating stderr file: %v", err)
+		return nil, err
 	}
-	defer os.Remove(stderrFile.Name())
 
-	stdinFile, err := os.CreateTemp("", "stdin*")
-	if err != nil {
-		return nil, fmt.Errorf("creating stdin file: %v", err)
-	}
-	defer os.Remove(stdinFile.Name())
+	store.Limiter(params.MaxMemory, 629, 2, 1, 1)
 
-	_, err = stdinFile.Write(callDataJson)
-	if err != nil {
-		return nil, fmt.Errorf("writing to stdin file: %v", err)
-	}
+	defer store.Close()
 
 	err = store.SetFuel(params.MaxFuel)
 	if err != nil {
 		return nil, fmt.Errorf("setting fuel: %v", err)
 	}
 
-	wasiConfig := wasmtime.NewWasiConfig()
-	wasiConfig.SetStdoutFile(stdoutFile.Name())
-	wasiConfig.SetStderrFile(stderrFile.Name())
-	wasiConfig.SetStdinFile(stdinFile.Name())
-	store.SetWasi(wasiConfig)
-
-	startTime := time.Now()
-
-	finished := false
+	// stdio is served by the host functions, WASI only backs the remaining imports
+	store.SetWasi(wasmtime.NewWasiConfig())
 
-	timeoutErrCh := make(chan error, 1)
-	go func() {
-		time.Sleep(params.MaxTime)
-		if !finished {
-			fmt.Printf("Execution timed out\n")
-			store.Engine.IncrementEpoch()
-			timeoutErrCh <- fmt.Errorf("execution timed out")
-		}
-	}()
+	// Wall-clock limits are only honoured outside of consensus, see [JavyExecParams.MaxTime]
+	if params.MaxTime > 0 {
+		exec.startEpochTicker()
+		store.SetEpochDeadline(uint64(params.MaxTime/epochTick) + 1)
+	} else {
+		store.SetEpochDeadline(noEpochDeadline)
+	}
 
+	startTime := time.Now()
 	_, err = mainFunc.Call(store)
-	finished = true
+	execTime := time.Since(startTime)
 	if err != nil {
 		return nil, fmt.Errorf("calling user code main function: %v", err)
 	}
-	execTime := time.Since(startTime)
-
-	select {
-	case err := <-timeoutErrCh:
-		if err != nil {
-			return nil, err
-		}
-	default:
-	}
 
 	fuelAfter, err := store.GetFuel()
 	if err != nil {
@@ -163,25 +150,23 @@ func (exec *JavyExec) executeOnState(params JavyExecParams, state map[string][]b
 	}
 	consumedFuel := params.MaxFuel - fuelAfter
 
-	stdoutBytes, err := os.ReadFile(stdoutFile.Name())
-	if err != nil {
-		return nil, fmt.Errorf("reading stdout file: %v", err)
-	}
-
-	fmt.Printf("\n>>>>DEBUG stdout>>>\n> %s\n<<<<DEBUG stdout<<<<\n\n", strings.ReplaceAll(string(stdoutBytes), "\n", "\n> "))
-
-	stderrBytes, err := os.ReadFile(stderrFile.Name())
-	if err != nil {
-		return nil, fmt.Errorf("reading stderr file: %v", err)
-	}
-
-	fmt.Printf("\n>>>>DEBUG stderr>>>\n> %s\n<<<<DEBUG stderr<<<<\n\n", strings.ReplaceAll(string(stderrBytes), "\n", "\n> "))
+	stdoutBytes := host.stdout.Bytes()
+	stderrBytes := host.stderr.Bytes()
+	exec.logger().Debug("contract executed",
+		zap.ByteString("stdout", stdoutBytes),
+		zap.ByteString("stderr", stderrBytes),
+	)
 
 	var stdoutResult ResultJSON
 	err = json.Unmarshal(stdoutBytes, &stdoutResult)
 	if err != nil {
 		return nil, fmt.Errorf("unmarshalling stdout: %v", err)
 	}
+	stdoutResult.ReadKeys = host.reads
+	stdoutResult.UpdatedKeys = map[string][]byte{}
+	if stdoutResult.Success {
+		stdoutResult.UpdatedKeys = host.writes
+	}
 
 	return &JavyExecResult{
 		FuelConsumed: consumedFuel,
@@ -0,0 +1,156 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go/v21"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCachedModuleIsolation ensures that calls sharing a compiled module do
+// not observe each other's globals.
+func TestCachedModuleIsolation(t *testing.T) {
+	require := require.New(t)
+
+	prelude, err := os.ReadFile(filepath.Join(conformanceDir, "prelude.js"))
+	require.NoError(err)
+	bytecode := compileJS(t, string(prelude)+`
+globalThis.calls = (globalThis.calls || 0) + 1;
+respond(globalThis.calls);
+`)
+
+	exec := NewJavyExec()
+	defer exec.Close()
+	require.NoError(exec.Precompile(bytecode))
+	require.Equal(1, exec.modules.Len())
+
+	for i := 0; i < 3; i++ {
+		res, err := exec.Execute(JavyExecParams{
+			MaxFuel:       100_000_000,
+			MaxMemory:     64 * 1024 * 1024,
+			Bytecode:      &bytecode,
+			StateProvider: NewDummyStateProvider().StateProvider,
+		})
+		require.NoError(err)
+		require.True(res.Result.Success, res.Result.Error)
+		require.Equal([]byte("1"), res.Result.Result)
+	}
+	require.Equal(1, exec.modules.Len())
+}
+
+func TestBlockContext(t *testing.T) {
+	require := require.New(t)
+
+	prelude, err := os.ReadFile(filepath.Join(conformanceDir, "prelude.js"))
+	require.NoError(err)
+	bytecode := compileJS(t, string(prelude)+`
+respond([input.height, input.timestamp, input.chainId, input.actionId, input.contract].join(","));
+`)
+
+	exec := NewJavyExec()
+	defer exec.Close()
+	res, err := exec.Execute(JavyExecParams{
+		MaxFuel:       100_000_000,
+		MaxMemory:     64 * 1024 * 1024,
+		Bytecode:      &bytecode,
+		StateProvider: NewDummyStateProvider().StateProvider,
+		Contract:      []byte{4},
+		Block: BlockContext{
+			Height:    1 << 60,
+			Timestamp: 1_700_000_000_000,
+			ChainID:   []byte{1},
+			ActionID:  []byte{2},
+		},
+	})
+	require.NoError(err)
+	require.True(res.Result.Success, res.Result.Error)
+	require.Equal("1152921504606846976,1700000000000,AQ==,Ag==,BA==", string(res.Result.Result))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		wat  string
+		err  string
+	}{
+		{
+			name: "javy module",
+			wat: `(module
+  (import "javy_quickjs_provider_v2" "memory" (memory 0))
+  (func (export "_start")))`,
+		},
+		{
+			name: "wasi import",
+			wat: `(module
+  (import "wasi_snapshot_preview1" "proc_exit" (func (param i32)))
+  (func (export "_start")))`,
+			err: `imports from "wasi_snapshot_preview1"`,
+		},
+		{
+			name: "missing _start",
+			wat:  `(module (func (export "main")))`,
+			err:  "_start is not exported",
+		},
+		{
+			name: "_start with results",
+			wat:  `(module (func (export "_start") (result i32) (i32.const 0)))`,
+			err:  "_start is not a function",
+		},
+	}
+
+	exec := NewJavyExec()
+	defer exec.Close()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			wasm, err := wasmtime.Wat2Wasm(tt.wat)
+			require.NoError(err)
+			err = exec.Validate(wasm)
+			if tt.err == "" {
+				require.NoError(err)
+				return
+			}
+			require.ErrorIs(err, ErrInvalidModule)
+			require.ErrorContains(err, tt.err)
+		})
+	}
+
+	require.ErrorIs(t, exec.Validate([]byte{0x00, 'a', 's', 'm'}), ErrInvalidModule)
+	require.NoError(t, exec.Validate(compileJS(t, `console.log("hello")`)))
+}
+
+// TestLargePayload ensures that payloads and host calls larger than
+// [maxStderrSize] are not mistaken for logs.
+func TestLargePayload(t *testing.T) {
+	require := require.New(t)
+
+	prelude, err := os.ReadFile(filepath.Join(conformanceDir, "prelude.js"))
+	require.NoError(err)
+	bytecode := compileJS(t, string(prelude)+fmt.Sprintf(`
+const value = new Uint8Array(%d).fill(7);
+setBytes(new Uint8Array([1, 0x10, 0x00]), value);
+respond(input.payload.length + "," + getBytes(new Uint8Array([1, 0x10, 0x00])).length);
+`, 2*maxStderrSize))
+
+	exec := NewJavyExec()
+	defer exec.Close()
+	payload := bytes.Repeat([]byte{1}, 3*maxStderrSize)
+	res, err := exec.Execute(JavyExecParams{
+		MaxFuel:       1_000_000_000,
+		MaxMemory:     64 * 1024 * 1024,
+		Bytecode:      &bytecode,
+		StateProvider: NewDummyStateProvider().StateProvider,
+		Payload:       payload,
+	})
+	require.NoError(err)
+	require.True(res.Result.Success, res.Result.Error)
+	require.Equal(fmt.Sprintf("%d,%d", base64.StdEncoding.EncodedLen(len(payload)), 2*maxStderrSize), string(res.Result.Result))
+	require.Len(res.Result.UpdatedKeys[string([]byte{1, 0x10, 0x00})], 2*maxStderrSize)
+	require.Empty(res.DebugLog)
+}
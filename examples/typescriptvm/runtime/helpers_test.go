@@ -0,0 +1,15 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func compileJS(t *testing.T, src string) []byte {
+	t.Helper()
+
+	wasm, err := CompileJS(src)
+	require.NoError(t, err)
+	return wasm
+}
@@ -0,0 +1,485 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/bytecodealliance/wasmtime-go/v21"
+
+	"github.com/ava-labs/hypersdk/keys"
+)
+
+// Javy only lets JS talk to the host through Javy.IO, which is limited to
+// stdin, stdout and stderr. State access is therefore served by host
+// functions shadowing the WASI fd_read/fd_write imports of the provider:
+// requests are written to stderr prefixed with [hostCallMagic] and responses
+// are served back on stdin, after the call payload has been consumed.
+//
+// Because the shadowed imports own the stdio descriptors, stdout and stderr
+// are collected in memory as well, up to [maxStdoutSize] and [maxStderrSize].
+// Host calls and the payload do not count towards either. The clock is
+// shadowed too, so Date and the Math.random seed never observe the
+// validator's wall clock.
+//
+// Request layout: magic(4) | op(1) | keyLen(uint16) | key | value
+// Get response:   valueLen(uint32) | value
+//
+// Keys end with the maximum number of 64-byte chunks of their value, as a
+// uint16. Sets exceeding it are rejected, and deletes carry no value: they
+// are recorded as an empty write, which removes the key once committed.
+//
+// Contract calls use the callee address as key and
+// maxFuel(uint64) | functionNameLen(uint16) | functionName | payload as value.
+// Balance queries and transfers use the address as key, and transfers the
+// amount(uint64) as value. Events use the topic as key and the event data as
+// value.
+// Call, balance, transfer and event responses: len(uint32) | success(1) | data
+// where data is the call result, the balance(uint64) or the error.
+//
+// The read-only op carries neither key nor value and has no response. It
+// switches the rest of the call to read-only, which cannot be undone: sets and
+// deletes are then rejected, transfers and events fail, and nested calls are
+// read-only as well.
+const (
+	wasiModule = "wasi_snapshot_preview1"
+
+	fdStdin  = 0
+	fdStdout = 1
+	fdStderr = 2
+
+	hostOpGetBytes     = 0
+	hostOpSetBytes     = 1
+	hostOpCallContract = 2
+	hostOpBalanceOf    = 3
+	hostOpTransfer     = 4
+	hostOpEmit         = 5
+	hostOpDeleteBytes  = 6
+	hostOpReadOnly     = 7
+
+	// maxStdoutSize and maxStderrSize cap the output a single call may
+	// produce. Exceeding either aborts the call, which is deterministic since
+	// both only depend on what the contract writes.
+	maxStdoutSize = 1024 * 1024
+	maxStderrSize = 64 * 1024
+
+	wasiErrnoSuccess = 0
+	wasiErrnoBadf    = 8
+	wasiErrnoInval   = 28
+)
+
+var hostCallMagic = []byte{0x00, 't', 's', 'v'}
+
+// hostState holds everything a single contract call can observe through the
+// host functions. It must never be shared between calls.
+type hostState struct {
+	provider  StateProvider
+	caller    ContractCaller
+	balanceOf BalanceProvider
+	transfer  TransferHandler
+	emit      EventHandler
+
+	// readOnly is set for the whole call, or from the moment the contract
+	// requests it
+	readOnly bool
+
+	// stdin holds bytes not yet consumed by the guest: first the JSON payload
+	// and later the responses to host calls.
+	stdin []byte
+
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+
+	values  map[string][]byte
+	reads   [][]byte
+	readSet map[string]struct{}
+	writes  map[string][]byte
+}
+
+func newHostState(provider StateProvider, payload []byte) *hostState {
+	return &hostState{
+		provider: provider,
+		stdin:    payload,
+		values:   map[string][]byte{},
+		readSet:  map[string]struct{}{},
+		writes:   map[string][]byte{},
+	}
+}
+
+func (h *hostState) getBytes(key []byte) ([]byte, error) {
+	k := string(key)
+	if _, ok := h.readSet[k]; !ok {
+		h.readSet[k] = struct{}{}
+		h.reads = append(h.reads, key)
+	}
+
+	if val, ok := h.values[k]; ok {
+		return val, nil
+	}
+	val, err := h.provider(k)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving state for address %x: %w", key, err)
+	}
+	h.values[k] = val
+	return val, nil
+}
+
+func (h *hostState) setBytes(key []byte, value []byte) {
+	k := string(key)
+	if current, ok := h.values[k]; ok && bytes.Equal(current, value) {
+		return
+	}
+	h.values[k] = value
+	h.writes[k] = value
+}
+
+// fuelMeter is the part of [wasmtime.Store] used to charge nested calls.
+type fuelMeter interface {
+	GetFuel() (uint64, error)
+	SetFuel(uint64) error
+}
+
+// callContract runs a nested call and charges the fuel it consumed to [meter].
+// Failures of the callee are reported to the caller rather than trapping, so
+// the caller may recover from them.
+func (h *hostState) callContract(meter fuelMeter, address []byte, req []byte) (bool, error) {
+	if len(req) < 10 {
+		return false, nil
+	}
+	requested := binary.BigEndian.Uint64(req[:8])
+	nameLen := int(binary.BigEndian.Uint16(req[8:10]))
+	req = req[10:]
+	if len(req) < nameLen {
+		return false, nil
+	}
+	functionName := string(req[:nameLen])
+	payload := req[nameLen:]
+
+	remaining, err := meter.GetFuel()
+	if err != nil {
+		return false, err
+	}
+	maxFuel := remaining
+	if requested > 0 && requested < remaining {
+		maxFuel = requested
+	}
+
+	var (
+		consumed = maxFuel
+		success  bool
+		output   []byte
+	)
+	if h.caller == nil {
+		output = []byte("contract calls are not supported")
+	} else {
+		res, err := h.caller(address, functionName, payload, maxFuel, h.readOnly)
+		switch {
+		case err != nil:
+			output = []byte(err.Error())
+		case !res.Result.Success:
+			consumed = res.FuelConsumed
+			output = []byte(res.Result.Error)
+		default:
+			consumed = res.FuelConsumed
+			success = true
+			output = res.Result.Result
+		}
+	}
+	if err := meter.SetFuel(remaining - min(consumed, remaining)); err != nil {
+		return false, err
+	}
+
+	h.respond(success, output)
+	return true, nil
+}
+
+// respond queues a response carrying a success flag on stdin.
+func (h *hostState) respond(success bool, data []byte) {
+	h.stdin = binary.BigEndian.AppendUint32(h.stdin, uint32(1+len(data)))
+	if success {
+		h.stdin = append(h.stdin, 1)
+	} else {
+		h.stdin = append(h.stdin, 0)
+	}
+	h.stdin = append(h.stdin, data...)
+}
+
+func (h *hostState) getBalance(address []byte) {
+	if h.balanceOf == nil {
+		h.respond(false, []byte("balances are not supported"))
+		return
+	}
+	balance, err := h.balanceOf(address)
+	if err != nil {
+		h.respond(false, []byte(err.Error()))
+		return
+	}
+	h.respond(true, binary.BigEndian.AppendUint64(nil, balance))
+}
+
+func (h *hostState) transferTo(to []byte, amount []byte) bool {
+	if len(amount) != 8 {
+		return false
+	}
+	if h.transfer == nil {
+		h.respond(false, []byte("transfers are not supported"))
+		return true
+	}
+	if h.readOnly {
+		h.respond(false, []byte("transfers are not allowed in a read-only call"))
+		return true
+	}
+	if err := h.transfer(to, binary.BigEndian.Uint64(amount)); err != nil {
+		h.respond(false, []byte(err.Error()))
+		return true
+	}
+	h.respond(true, nil)
+	return true
+}
+
+func (h *hostState) emitEvent(topic []byte, data []byte) {
+	if h.emit == nil {
+		h.respond(false, []byte("events are not supported"))
+		return
+	}
+	if h.readOnly {
+		h.respond(false, []byte("events are not allowed in a read-only call"))
+		return
+	}
+	if err := h.emit(string(topic), data); err != nil {
+		h.respond(false, []byte(err.Error()))
+		return
+	}
+	h.respond(true, nil)
+}
+
+// handleCall processes a single host call, queuing any response on stdin.
+// It returns false if [req] is not a well-formed host call.
+func (h *hostState) handleCall(meter fuelMeter, req []byte) (bool, error) {
+	req = req[len(hostCallMagic):]
+	if len(req) < 3 {
+		return false, nil
+	}
+	op := req[0]
+	keyLen := int(binary.BigEndian.Uint16(req[1:3]))
+	req = req[3:]
+	if len(req) < keyLen {
+		return false, nil
+	}
+	key := bytes.Clone(req[:keyLen])
+	value := bytes.Clone(req[keyLen:])
+
+	switch op {
+	case hostOpGetBytes:
+		val, err := h.getBytes(key)
+		if err != nil {
+			return false, err
+		}
+		h.stdin = binary.BigEndian.AppendUint32(h.stdin, uint32(len(val)))
+		h.stdin = append(h.stdin, val...)
+		return true, nil
+	case hostOpSetBytes:
+		if h.readOnly || !keys.VerifyValue(key, value) {
+			return false, nil
+		}
+		h.setBytes(key, value)
+		return true, nil
+	case hostOpDeleteBytes:
+		if h.readOnly || !keys.Valid(string(key)) || len(value) != 0 {
+			return false, nil
+		}
+		h.setBytes(key, nil)
+		return true, nil
+	case hostOpCallContract:
+		return h.callContract(meter, key, value)
+	case hostOpBalanceOf:
+		h.getBalance(key)
+		return true, nil
+	case hostOpTransfer:
+		return h.transferTo(key, value), nil
+	case hostOpEmit:
+		h.emitEvent(key, value)
+		return true, nil
+	case hostOpReadOnly:
+		if len(key) != 0 || len(value) != 0 {
+			return false, nil
+		}
+		h.readOnly = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// defineHostFuncs shadows the WASI stdio imports used by the Javy provider.
+// Must be called after [wasmtime.Linker.DefineWasi].
+func defineHostFuncs(store *wasmtime.Store, linker *wasmtime.Linker, h *hostState) error {
+	i32 := wasmtime.NewValType(wasmtime.KindI32)
+	ioType := wasmtime.NewFuncType([]*wasmtime.ValType{i32, i32, i32, i32}, []*wasmtime.ValType{i32})
+
+	// charge deducts the host call cost from the store fuel, see [HostCallFuel]
+	charge := func(size int) *wasmtime.Trap {
+		cost := uint64(HostCallFuel + size*HostCallFuelPerByte)
+		fuel, err := store.GetFuel()
+		if err != nil {
+			return wasmtime.NewTrap(err.Error())
+		}
+		if fuel < cost {
+			_ = store.SetFuel(0)
+			return wasmtime.NewTrap("all fuel consumed by host call")
+		}
+		if err := store.SetFuel(fuel - cost); err != nil {
+			return wasmtime.NewTrap(err.Error())
+		}
+		return nil
+	}
+
+	linker.AllowShadowing(true)
+	err := linker.Define(store, wasiModule, "fd_read", wasmtime.NewFunc(store, ioType,
+		func(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+			if args[0].I32() != fdStdin {
+				return errno(wasiErrnoBadf), nil
+			}
+			mem := guestMemory(caller)
+			if mem == nil {
+				return nil, wasmtime.NewTrap("guest memory is not exported")
+			}
+			iovecs, ok := readIovecs(mem, args[1].I32(), args[2].I32())
+			if !ok {
+				return errno(wasiErrnoInval), nil
+			}
+			var n int
+			for _, iov := range iovecs {
+				copied := copy(iov, h.stdin)
+				h.stdin = h.stdin[copied:]
+				n += copied
+				if len(h.stdin) == 0 {
+					break
+				}
+			}
+			if trap := charge(n); trap != nil {
+				return nil, trap
+			}
+			if !writeUint32(mem, args[3].I32(), uint32(n)) {
+				return errno(wasiErrnoInval), nil
+			}
+			return errno(wasiErrnoSuccess), nil
+		},
+	))
+	if err != nil {
+		return fmt.Errorf("defining fd_read: %v", err)
+	}
+
+	err = linker.Define(store, wasiModule, "fd_write", wasmtime.NewFunc(store, ioType,
+		func(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+			fd := args[0].I32()
+			if fd != fdStdout && fd != fdStderr {
+				return errno(wasiErrnoBadf), nil
+			}
+			mem := guestMemory(caller)
+			if mem == nil {
+				return nil, wasmtime.NewTrap("guest memory is not exported")
+			}
+			iovecs, ok := readIovecs(mem, args[1].I32(), args[2].I32())
+			if !ok {
+				return errno(wasiErrnoInval), nil
+			}
+			data := bytes.Join(iovecs, nil)
+			if trap := charge(len(data)); trap != nil {
+				return nil, trap
+			}
+
+			switch {
+			case fd == fdStderr && bytes.HasPrefix(data, hostCallMagic):
+				handled, err := h.handleCall(store, data)
+				if err != nil {
+					return nil, wasmtime.NewTrap(err.Error())
+				}
+				if !handled {
+					return errno(wasiErrnoInval), nil
+				}
+			case fd == fdStderr:
+				if h.stderr.Len()+len(data) > maxStderrSize {
+					return nil, wasmtime.NewTrap("stderr limit exceeded")
+				}
+				h.stderr.Write(data)
+			default:
+				if h.stdout.Len()+len(data) > maxStdoutSize {
+					return nil, wasmtime.NewTrap("stdout limit exceeded")
+				}
+				h.stdout.Write(data)
+			}
+
+			if !writeUint32(mem, args[3].I32(), uint32(len(data))) {
+				return errno(wasiErrnoInval), nil
+			}
+			return errno(wasiErrnoSuccess), nil
+		},
+	))
+	if err != nil {
+		return fmt.Errorf("defining fd_write: %v", err)
+	}
+
+	i64 := wasmtime.NewValType(wasmtime.KindI64)
+	clockType := wasmtime.NewFuncType([]*wasmtime.ValType{i32, i64, i32}, []*wasmtime.ValType{i32})
+	err = linker.Define(store, wasiModule, "clock_time_get", wasmtime.NewFunc(store, clockType,
+		func(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+			mem := guestMemory(caller)
+			if mem == nil {
+				return nil, wasmtime.NewTrap("guest memory is not exported")
+			}
+			offset := uint64(uint32(args[2].I32()))
+			if offset+8 > uint64(len(mem)) {
+				return errno(wasiErrnoInval), nil
+			}
+			binary.LittleEndian.PutUint64(mem[offset:], 0)
+			return errno(wasiErrnoSuccess), nil
+		},
+	))
+	if err != nil {
+		return fmt.Errorf("defining clock_time_get: %v", err)
+	}
+	return nil
+}
+
+func errno(code int32) []wasmtime.Val {
+	return []wasmtime.Val{wasmtime.ValI32(code)}
+}
+
+func guestMemory(caller *wasmtime.Caller) []byte {
+	ext := caller.GetExport("memory")
+	if ext == nil || ext.Memory() == nil {
+		return nil
+	}
+	return ext.Memory().UnsafeData(caller)
+}
+
+// readIovecs resolves a WASI iovec array into slices of guest memory.
+func readIovecs(mem []byte, ptr int32, count int32) ([][]byte, bool) {
+	if count < 0 {
+		return nil, false
+	}
+	iovecs := make([][]byte, 0, count)
+	for i := int32(0); i < count; i++ {
+		offset := uint64(uint32(ptr)) + uint64(i)*8
+		if offset+8 > uint64(len(mem)) {
+			return nil, false
+		}
+		bufPtr := uint64(binary.LittleEndian.Uint32(mem[offset:]))
+		bufLen := uint64(binary.LittleEndian.Uint32(mem[offset+4:]))
+		if bufPtr+bufLen > uint64(len(mem)) {
+			return nil, false
+		}
+		iovecs = append(iovecs, mem[bufPtr:bufPtr+bufLen])
+	}
+	return iovecs, true
+}
+
+func writeUint32(mem []byte, ptr int32, v uint32) bool {
+	offset := uint64(uint32(ptr))
+	if offset+4 > uint64(len(mem)) {
+		return false
+	}
+	binary.LittleEndian.PutUint32(mem[offset:], v)
+	return true
+}
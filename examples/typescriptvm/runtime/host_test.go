@@ -0,0 +1,239 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go/v21"
+	"github.com/stretchr/testify/require"
+)
+
+// hostCallWat mimics the provider: it issues a get and a set host call on
+// stderr and reads the get response back from stdin.
+const hostCallWat = `
+(module
+  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (import "wasi_snapshot_preview1" "fd_read" (func $fd_read (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  ;; get 0x0102
+  (data (i32.const 100) "\00tsv\00\00\02\01\02")
+  ;; set 0x0103 = 0xaabb
+  (data (i32.const 120) "\00tsv\01\00\02\01\03\aa\bb")
+  (func (export "_start")
+    (i32.store (i32.const 0) (i32.const 100))
+    (i32.store (i32.const 4) (i32.const 9))
+    (drop (call $fd_write (i32.const 2) (i32.const 0) (i32.const 1) (i32.const 16)))
+
+    (i32.store (i32.const 32) (i32.const 200))
+    (i32.store (i32.const 36) (i32.const 64))
+    (drop (call $fd_read (i32.const 0) (i32.const 32) (i32.const 1) (i32.const 48)))
+
+    (i32.store (i32.const 0) (i32.const 120))
+    (i32.store (i32.const 4) (i32.const 11))
+    (drop (call $fd_write (i32.const 2) (i32.const 0) (i32.const 1) (i32.const 16)))
+  )
+)`
+
+func TestHostFuncs(t *testing.T) {
+	require := require.New(t)
+
+	provider := NewDummyStateProvider()
+	provider.Update(map[string][]byte{string([]byte{1, 2}): {7, 8, 9}})
+	host := newHostState(provider.StateProvider, nil)
+
+	wasm, err := wasmtime.Wat2Wasm(hostCallWat)
+	require.NoError(err)
+	engine := wasmtime.NewEngineWithConfig(newEngineConfig())
+	defer engine.Close()
+	module, err := wasmtime.NewModule(engine, wasm)
+	require.NoError(err)
+
+	store := wasmtime.NewStore(engine)
+	defer store.Close()
+	store.SetEpochDeadline(noEpochDeadline)
+	require.NoError(store.SetFuel(1_000_000))
+	store.SetWasi(wasmtime.NewWasiConfig())
+	linker := wasmtime.NewLinker(engine)
+	require.NoError(linker.DefineWasi())
+	require.NoError(defineHostFuncs(store, linker, host))
+
+	instance, err := linker.Instantiate(store, module)
+	require.NoError(err)
+	_, err = instance.GetFunc(store, "_start").Call(store)
+	require.NoError(err)
+
+	mem := instance.GetExport(store, "memory").Memory().UnsafeData(store)
+	require.Equal([]byte{7, 0, 0, 0}, mem[48:52])            // nread
+	require.Equal([]byte{0, 0, 0, 3, 7, 8, 9}, mem[200:207]) // length-prefixed value
+
+	// two writes of 9 and 11 bytes and one read of 7 bytes
+	fuel, err := store.GetFuel()
+	require.NoError(err)
+	require.Less(fuel, uint64(1_000_000-3*HostCallFuel-27*HostCallFuelPerByte))
+
+	require.Equal([][]byte{{1, 2}}, host.reads)
+	require.Equal(map[string][]byte{string([]byte{1, 3}): {0xaa, 0xbb}}, host.writes)
+}
+
+// stderrFloodWat writes [maxStderrSize]+1 bytes to stderr in a single call.
+var stderrFloodWat = fmt.Sprintf(`
+(module
+  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 2)
+  (func (export "_start")
+    (i32.store (i32.const 0) (i32.const 16))
+    (i32.store (i32.const 4) (i32.const %d))
+    (drop (call $fd_write (i32.const 2) (i32.const 0) (i32.const 1) (i32.const 8)))
+  )
+)`, maxStderrSize+1)
+
+func TestHostOutputLimit(t *testing.T) {
+	require := require.New(t)
+
+	host := newHostState(NewDummyStateProvider().StateProvider, nil)
+
+	wasm, err := wasmtime.Wat2Wasm(stderrFloodWat)
+	require.NoError(err)
+	engine := wasmtime.NewEngineWithConfig(newEngineConfig())
+	defer engine.Close()
+	module, err := wasmtime.NewModule(engine, wasm)
+	require.NoError(err)
+
+	store := wasmtime.NewStore(engine)
+	defer store.Close()
+	store.SetEpochDeadline(noEpochDeadline)
+	require.NoError(store.SetFuel(1_000_000))
+	store.SetWasi(wasmtime.NewWasiConfig())
+	linker := wasmtime.NewLinker(engine)
+	require.NoError(linker.DefineWasi())
+	require.NoError(defineHostFuncs(store, linker, host))
+
+	instance, err := linker.Instantiate(store, module)
+	require.NoError(err)
+	_, err = instance.GetFunc(store, "_start").Call(store)
+	require.ErrorContains(err, "stderr limit exceeded")
+	require.Zero(host.stderr.Len())
+}
+
+func TestHostSetBytesSkipsUnchanged(t *testing.T) {
+	require := require.New(t)
+
+	provider := NewDummyStateProvider()
+	provider.Update(map[string][]byte{"k": {1}})
+	host := newHostState(provider.StateProvider, nil)
+
+	_, err := host.getBytes([]byte("k"))
+	require.NoError(err)
+	host.setBytes([]byte("k"), []byte{1})
+	require.Empty(host.writes)
+
+	host.setBytes([]byte("k"), []byte{2})
+	require.Equal(map[string][]byte{"k": {2}}, host.writes)
+}
+
+func TestHostStateChunks(t *testing.T) {
+	require := require.New(t)
+
+	host := newHostState(NewDummyStateProvider().StateProvider, nil)
+	request := func(op byte, key []byte, value []byte) []byte {
+		req := append(bytes.Clone(hostCallMagic), op)
+		req = binary.BigEndian.AppendUint16(req, uint16(len(key)))
+		req = append(req, key...)
+		return append(req, value...)
+	}
+
+	// A single chunk holds up to 63 bytes, as in [keys.VerifyValue]
+	handled, err := host.handleCall(nil, request(hostOpSetBytes, []byte{1, 0, 1}, make([]byte, 63)))
+	require.NoError(err)
+	require.True(handled)
+	handled, err = host.handleCall(nil, request(hostOpSetBytes, []byte{2, 0, 1}, make([]byte, 64)))
+	require.NoError(err)
+	require.False(handled)
+	handled, err = host.handleCall(nil, request(hostOpSetBytes, []byte{1}, []byte{1}))
+	require.NoError(err)
+	require.False(handled)
+
+	// Deletes are recorded as empty writes and carry no value
+	handled, err = host.handleCall(nil, request(hostOpDeleteBytes, []byte{1, 0, 1}, nil))
+	require.NoError(err)
+	require.True(handled)
+	handled, err = host.handleCall(nil, request(hostOpDeleteBytes, []byte{3, 0, 1}, []byte{1}))
+	require.NoError(err)
+	require.False(handled)
+	require.Equal(map[string][]byte{string([]byte{1, 0, 1}): nil}, host.writes)
+
+	val, err := host.getBytes([]byte{1, 0, 1})
+	require.NoError(err)
+	require.Empty(val)
+}
+
+func TestHostReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	var calledReadOnly bool
+	host := newHostState(NewDummyStateProvider().StateProvider, nil)
+	host.transfer = func([]byte, uint64) error { return nil }
+	host.caller = func(_ []byte, _ string, _ []byte, _ uint64, readOnly bool) (*JavyExecResult, error) {
+		calledReadOnly = readOnly
+		return &JavyExecResult{Result: ResultJSON{Success: true}}, nil
+	}
+	request := func(op byte, key []byte, value []byte) []byte {
+		req := append(bytes.Clone(hostCallMagic), op)
+		req = binary.BigEndian.AppendUint16(req, uint16(len(key)))
+		req = append(req, key...)
+		return append(req, value...)
+	}
+	call := request(hostOpCallContract, []byte{1}, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+
+	handled, err := host.handleCall(nil, request(hostOpSetBytes, []byte{1, 0, 1}, []byte{1}))
+	require.NoError(err)
+	require.True(handled)
+	handled, err = host.handleCall(&fixedFuel{fuel: 100}, call)
+	require.NoError(err)
+	require.True(handled)
+	require.False(calledReadOnly)
+
+	// Switching to read-only cannot be undone
+	handled, err = host.handleCall(nil, request(hostOpReadOnly, nil, nil))
+	require.NoError(err)
+	require.True(handled)
+	handled, err = host.handleCall(nil, request(hostOpReadOnly, []byte{1}, nil))
+	require.NoError(err)
+	require.False(handled)
+
+	handled, err = host.handleCall(nil, request(hostOpSetBytes, []byte{2, 0, 1}, []byte{1}))
+	require.NoError(err)
+	require.False(handled)
+	handled, err = host.handleCall(nil, request(hostOpDeleteBytes, []byte{1, 0, 1}, nil))
+	require.NoError(err)
+	require.False(handled)
+	require.Equal(map[string][]byte{string([]byte{1, 0, 1}): {1}}, host.writes)
+
+	host.stdin = nil
+	handled, err = host.handleCall(nil, request(hostOpTransfer, []byte{1}, binary.BigEndian.AppendUint64(nil, 1)))
+	require.NoError(err)
+	require.True(handled)
+	require.Equal(byte(0), host.stdin[4]) // failed
+	require.Contains(string(host.stdin[5:]), "read-only")
+
+	handled, err = host.handleCall(&fixedFuel{fuel: 100}, call)
+	require.NoError(err)
+	require.True(handled)
+	require.True(calledReadOnly)
+}
+
+// fixedFuel is a [fuelMeter] holding [fuel].
+type fixedFuel struct {
+	fuel uint64
+}
+
+func (f *fixedFuel) GetFuel() (uint64, error) {
+	return f.fuel, nil
+}
+
+func (f *fixedFuel) SetFuel(fuel uint64) error {
+	f.fuel = fuel
+	return nil
+}
@@ -0,0 +1,135 @@
+// Encodes values like the abi package of the VM, so that clients can call
+// functions described by the ABI of a contract: integers are big endian, bools
+// take one byte, addresses are raw, strings are prefixed with their uint16
+// length and bytes with their uint32 length.
+
+export type AbiType = "u8" | "u16" | "u32" | "u64" | "i64" | "bool" | "address" | "string" | "bytes";
+
+export type AbiParam = {
+    name: string;
+    type: AbiType;
+};
+
+export type FunctionAbi = {
+    args?: AbiParam[];
+    returns?: AbiParam[];
+    // Read-only functions do not write state, transfer funds nor emit events
+    readOnly?: boolean;
+};
+
+export type AbiValue = number | bigint | boolean | string | Uint8Array;
+
+const ADDRESS_LENGTH = 33;
+const MAX_UINT16 = 0xffff;
+
+const fixedSizes: Partial<Record<AbiType, number>> = {
+    u8: 1, u16: 2, u32: 4, u64: 8, i64: 8, bool: 1, address: ADDRESS_LENGTH,
+};
+
+// Decodes the arguments of a call, or its result, described by [params].
+// Integers of 64 bits are returned as bigints, other integers as numbers.
+export function decodeValues(params: AbiParam[], data: Uint8Array): AbiValue[] {
+    const view = new DataView(data.buffer, data.byteOffset, data.byteLength);
+    let offset = 0;
+    const take = (size: number, name: string) => {
+        if (offset + size > data.length) {
+            throw new Error(`Not enough bytes to decode ${name}.`);
+        }
+        offset += size;
+        return offset - size;
+    };
+
+    const values = params.map((param): AbiValue => {
+        const size = fixedSizes[param.type];
+        if (size !== undefined) {
+            const at = take(size, param.name);
+            switch (param.type) {
+                case "u8": return view.getUint8(at);
+                case "u16": return view.getUint16(at);
+                case "u32": return view.getUint32(at);
+                case "u64": return view.getBigUint64(at);
+                case "i64": return view.getBigInt64(at);
+                case "bool": return view.getUint8(at) !== 0;
+                default: return data.slice(at, at + size);
+            }
+        }
+        switch (param.type) {
+            case "string": {
+                const length = view.getUint16(take(2, param.name));
+                const at = take(length, param.name);
+                return new TextDecoder().decode(data.subarray(at, at + length));
+            }
+            case "bytes": {
+                const length = view.getUint32(take(4, param.name));
+                const at = take(length, param.name);
+                return data.slice(at, at + length);
+            }
+            default:
+                throw new Error(`Unknown type ${param.type}.`);
+        }
+    });
+    if (offset !== data.length) {
+        throw new Error("Trailing bytes after the decoded values.");
+    }
+    return values;
+}
+
+// Encodes [values], described by [params], for instance as the result of a
+// function.
+export function encodeValues(params: AbiParam[], values: AbiValue[]): Uint8Array {
+    if (params.length !== values.length) {
+        throw new Error(`Expected ${params.length} values, got ${values.length}.`);
+    }
+    const parts: Uint8Array[] = params.map((param, i) => {
+        const value = values[i];
+        const size = fixedSizes[param.type];
+        const part = new Uint8Array(size ?? 0);
+        const view = new DataView(part.buffer);
+        switch (param.type) {
+            case "u8": view.setUint8(0, Number(value)); return part;
+            case "u16": view.setUint16(0, Number(value)); return part;
+            case "u32": view.setUint32(0, Number(value)); return part;
+            case "u64": view.setBigUint64(0, BigInt(value as number | bigint)); return part;
+            case "i64": view.setBigInt64(0, BigInt(value as number | bigint)); return part;
+            case "bool": view.setUint8(0, value ? 1 : 0); return part;
+            case "address": {
+                const address = value as Uint8Array;
+                if (address.length !== ADDRESS_LENGTH) {
+                    throw new Error(`${param.name} must be ${ADDRESS_LENGTH} bytes.`);
+                }
+                return address;
+            }
+            case "string": {
+                const bytes = new TextEncoder().encode(value as string);
+                if (bytes.length > MAX_UINT16) {
+                    throw new Error(`${param.name} must be at most ${MAX_UINT16} bytes.`);
+                }
+                return withLength(bytes, 2);
+            }
+            case "bytes":
+                return withLength(value as Uint8Array, 4);
+            default:
+                throw new Error(`Unknown type ${param.type}.`);
+        }
+    });
+
+    const out = new Uint8Array(parts.reduce((n, part) => n + part.length, 0));
+    let offset = 0;
+    for (const part of parts) {
+        out.set(part, offset);
+        offset += part.length;
+    }
+    return out;
+}
+
+function withLength(bytes: Uint8Array, prefix: 2 | 4): Uint8Array {
+    const out = new Uint8Array(prefix + bytes.length);
+    const view = new DataView(out.buffer);
+    if (prefix === 2) {
+        view.setUint16(0, bytes.length);
+    } else {
+        view.setUint32(0, bytes.length);
+    }
+    out.set(bytes, prefix);
+    return out;
+}
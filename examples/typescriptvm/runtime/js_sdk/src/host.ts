@@ -0,0 +1,98 @@
+import { readStdinExact, writeStdErrBytes } from "./javy_io";
+
+// Must match the host call layout in runtime/host.go
+const HOST_CALL_MAGIC = [0x00, 0x74, 0x73, 0x76];
+
+const enum HostOp {
+    GetBytes = 0,
+    SetBytes = 1,
+    CallContract = 2,
+    BalanceOf = 3,
+    Transfer = 4,
+    Emit = 5,
+    DeleteBytes = 6,
+    ReadOnly = 7,
+}
+
+function hostCall(op: HostOp, key: Uint8Array, value: Uint8Array = new Uint8Array()) {
+    const request = new Uint8Array(HOST_CALL_MAGIC.length + 3 + key.length + value.length);
+    request.set(HOST_CALL_MAGIC);
+    let offset = HOST_CALL_MAGIC.length;
+    request[offset++] = op;
+    request[offset++] = (key.length >> 8) & 0xff;
+    request[offset++] = key.length & 0xff;
+    request.set(key, offset);
+    request.set(value, offset + key.length);
+    writeStdErrBytes(request);
+}
+
+export function hostGetBytes(key: Uint8Array): Uint8Array {
+    hostCall(HostOp.GetBytes, key);
+    const header = readStdinExact(4);
+    const length = ((header[0] << 24) | (header[1] << 16) | (header[2] << 8) | header[3]) >>> 0;
+    return readStdinExact(length);
+}
+
+// The host rejects values larger than the chunks encoded in [key].
+export function hostSetBytes(key: Uint8Array, value: Uint8Array): void {
+    hostCall(HostOp.SetBytes, key, value);
+}
+
+// Removes [key] from the state once the call succeeds.
+export function hostDeleteBytes(key: Uint8Array): void {
+    hostCall(HostOp.DeleteBytes, key);
+}
+
+// Runs [functionName] of the contract at [address] with the calling contract as
+// actor. The callee may use at most [fuel] (0 means all remaining fuel). Throws
+// if the callee fails, in which case none of its state changes are kept.
+export function hostCallContract(address: Uint8Array, functionName: string, payload: Uint8Array, fuel: number): Uint8Array {
+    const name = new TextEncoder().encode(functionName);
+    const value = new Uint8Array(8 + 2 + name.length + payload.length);
+    const view = new DataView(value.buffer);
+    view.setUint32(0, Math.floor(fuel / 0x100000000));
+    view.setUint32(4, fuel >>> 0);
+    view.setUint16(8, name.length);
+    value.set(name, 10);
+    value.set(payload, 10 + name.length);
+    hostCall(HostOp.CallContract, address, value);
+    return readResponse("Contract call");
+}
+
+// Returns the native balance of [address].
+export function hostBalanceOf(address: Uint8Array): bigint {
+    hostCall(HostOp.BalanceOf, address);
+    const response = readResponse("Balance query");
+    return new DataView(response.buffer, response.byteOffset, response.byteLength).getBigUint64(0);
+}
+
+// Moves [amount] of the native token from the running contract to [to].
+export function hostTransfer(to: Uint8Array, amount: bigint): void {
+    const value = new Uint8Array(8);
+    new DataView(value.buffer).setBigUint64(0, amount);
+    hostCall(HostOp.Transfer, to, value);
+    readResponse("Transfer");
+}
+
+// Records an event, which is only kept if the call succeeds.
+export function hostEmit(topic: Uint8Array, data: Uint8Array): void {
+    hostCall(HostOp.Emit, topic, data);
+    readResponse("Emit");
+}
+
+// Makes the rest of the call read-only: the host then rejects writes,
+// transfers and events, including those of nested calls. It cannot be undone.
+export function hostReadOnly(): void {
+    hostCall(HostOp.ReadOnly, new Uint8Array());
+}
+
+// Reads a response carrying a success flag, throwing if it reports a failure.
+function readResponse(operation: string): Uint8Array {
+    const header = readStdinExact(4);
+    const length = ((header[0] << 24) | (header[1] << 16) | (header[2] << 8) | header[3]) >>> 0;
+    const response = readStdinExact(length);
+    if (response[0] !== 1) {
+        throw new Error(`${operation} failed: ${new TextDecoder().decode(response.subarray(1))}`);
+    }
+    return response.subarray(1);
+}
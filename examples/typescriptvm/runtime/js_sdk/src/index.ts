@@ -1,10 +1,17 @@
-import { Base64ToHexString, Base64ToUint8Array, HexStringToBase64, Uint8ArrayToBase64, Uint8ArrayToHex } from "./encoders";
+import { AbiParam, FunctionAbi, decodeValues } from "./abi";
+import { Base64ToUint8Array, Uint8ArrayToBase64 } from "./encoders";
+import { hostBalanceOf, hostCallContract, hostDeleteBytes, hostEmit, hostGetBytes, hostReadOnly, hostSetBytes, hostTransfer } from "./host";
 import { readStdin, writeStdOut } from "./javy_io";
-import { ExecuteContractFunc } from "./types";
+import { AuthorizeFunc, Context, ExecuteContractFunc, Ledger } from "./types";
 
 const MAX_SLOT_ADDR_LENGTH = 34;
+const ADDRESS_LENGTH = 33;
+const MAX_UINT64 = (1n << 64n) - 1n;
+// Must match the limits in actions/contract_call.go
+const MAX_EVENT_TOPIC_LENGTH = 64;
+const MAX_EVENT_DATA_LENGTH = 1024;
 
-const keyHexAddress = (slotAddr: Uint8Array, chunks: number) => {
+const keyAddress = (slotAddr: Uint8Array, chunks: number) => {
     //check slot and chunks are valid
     if (slotAddr.length > MAX_SLOT_ADDR_LENGTH || slotAddr.length === 0) {
         throw new Error(`Slot address must be between 1 and ${MAX_SLOT_ADDR_LENGTH} bytes.`);
@@ -13,99 +20,188 @@ const keyHexAddress = (slotAddr: Uint8Array, chunks: number) => {
         throw new Error("Size must be a value between 1 and 65535.");
     }
 
-    return `${Uint8ArrayToHex(slotAddr)}${chunks.toString(16).padStart(4, '0')}`
+    const address = new Uint8Array(slotAddr.length + 2);
+    address.set(slotAddr);
+    address[slotAddr.length] = (chunks >> 8) & 0xff;
+    address[slotAddr.length + 1] = chunks & 0xff;
+    return address;
 }
 
 console.warn = console.log//FIXME: monkey-patching
 
-class TracebleDB {
-    private _state: Record<string, Uint8Array>;
-    private _reads: Set<string>;
-    private _writes: Set<string>;
+// Set while running a view, or any function called read-only. The host
+// enforces it as well, this only fails earlier with a clearer error.
+let readOnly = false;
 
-    constructor(state: Record<string, Uint8Array>) {
-        this._state = state;
-        this._reads = new Set();
-        this._writes = new Set();
+function checkWritable(operation: string) {
+    if (readOnly) {
+        throw new Error(`${operation} is not allowed in a read-only call.`);
     }
+}
 
-    getValue(slot: Uint8Array, chunks: number): Uint8Array {
-        const address = keyHexAddress(slot, chunks);
-        console.log(`Reading ${address}`)
+// Reads and writes are tracked by the host, values are fetched lazily.
+function getBytes(slot: Uint8Array, chunks: number): Uint8Array {
+    const address = keyAddress(slot, chunks);
+    return hostGetBytes(address);
+}
 
-        this._reads.add(address);
+// The host enforces that [value] fits in the [chunks] declared for the slot, as
+// checked by the chain, and throws otherwise. Writing an empty value deletes
+// the slot.
+function setBytes(slot: Uint8Array, chunks: number, value: Uint8Array): void {
+    checkWritable("Writing");
+    const address = keyAddress(slot, chunks);
+    hostSetBytes(address, value);
+}
 
-        if (!this._state[address]) {
-            throw new Error(`NO_VALUE_AT_ADDRESS="${address}"`)
-        }
+// Removes a slot from the state, freeing its storage. Deleted slots read as
+// empty values, like slots that were never written.
+export function deleteBytes(slot: Uint8Array, chunks: number): void {
+    checkWritable("Deleting");
+    const address = keyAddress(slot, chunks);
+    hostDeleteBytes(address);
+}
 
-        return this._state[address];
+function checkAddress(address: Uint8Array) {
+    if (address.length !== ADDRESS_LENGTH) {
+        throw new Error(`Address must be ${ADDRESS_LENGTH} bytes.`);
     }
+}
 
-    setValue(slot: Uint8Array, chunks: number, value: Uint8Array): void {
-        const address = keyHexAddress(slot, chunks);
-        console.log(`Writing ${address}`)
-        //check size
-        if (value.length > 64 * chunks) {
-            throw new Error(`Value is larger than the size of the key (max size: ${64 * chunks} in ${chunks} 64-byte chunks, value: ${value.length})`);
-        }
-
-        if (this._state[address]
-            && (this._state[address].length === value.length)
-            && this._state[address].every((byte, i) => byte === value[i])
-        ) {
-            console.warn(`Skipping write of ${address} because it is already set to the same value.`);
-            return;
-        }
+function callContract(address: Uint8Array, functionName: string, payload: Uint8Array, fuel: number = 0): Uint8Array {
+    checkAddress(address);
+    if (!Number.isSafeInteger(fuel) || fuel < 0) {
+        throw new Error("Fuel must be a non-negative integer.");
+    }
+    return hostCallContract(address, functionName, payload, fuel);
+}
 
-        this._writes.add(address);
+function balanceOf(address: Uint8Array): bigint {
+    checkAddress(address);
+    return hostBalanceOf(address);
+}
 
-        this._state[address] = value
+function transfer(to: Uint8Array, amount: bigint): void {
+    checkWritable("Transferring");
+    checkAddress(to);
+    if (amount <= 0n || amount > MAX_UINT64) {
+        throw new Error("Amount must be a positive uint64.");
     }
+    hostTransfer(to, amount);
+}
 
-    getReadAdresses(): string[] {
-        return [...this._reads];
+// Emits an event, recorded in the transaction result and streamed to
+// subscribers of the contract once the transaction is accepted. Events of a
+// failed call are discarded.
+export function emit(topic: string, data: Uint8Array = new Uint8Array()): void {
+    checkWritable("Emitting");
+    const topicBytes = new TextEncoder().encode(topic);
+    if (topicBytes.length > MAX_EVENT_TOPIC_LENGTH) {
+        throw new Error(`Topic must be at most ${MAX_EVENT_TOPIC_LENGTH} bytes.`);
     }
-
-    getWriteAddresses(): Record<string, Uint8Array> {
-        const result: Record<string, Uint8Array> = {};
-        this._writes.forEach(address => {
-            result[address] = this._state[address];
-        });
-        return result;
+    if (data.length > MAX_EVENT_DATA_LENGTH) {
+        throw new Error(`Event data must be at most ${MAX_EVENT_DATA_LENGTH} bytes.`);
     }
+    hostEmit(topicBytes, data);
 }
 
+// Must match abi.ExportFunction in abi/abi.go
+const ABI_FUNCTION_NAME = "__abi";
 
 const funcs: Record<string, ExecuteContractFunc> = {};
-export function registerFunc(name: string, func: ExecuteContractFunc) {
+const abis: Record<string, FunctionAbi> = {};
+const views = new Set<string>();
+
+// Registers [func] as callable under [name]. Functions registered with an
+// [abi] are listed in the ABI of the contract, which the CLI publishes when
+// deploying it so that clients can encode calls and decode results. Functions
+// whose [abi] is read-only are registered as views.
+export function registerFunc(name: string, func: ExecuteContractFunc, abi?: FunctionAbi) {
+    if (name === ABI_FUNCTION_NAME) {
+        throw new Error(`${ABI_FUNCTION_NAME} is reserved.`);
+    }
     funcs[name] = func;
+    if (abi) {
+        abis[name] = abi;
+    }
+    if (abi?.readOnly) {
+        views.add(name);
+    } else {
+        views.delete(name);
+    }
+}
+
+// Registers [func] as a view under [name]. Views always run read-only, so
+// writes, transfers and events fail, and they are the only functions that can
+// be called read-only, for instance through the view RPC of the VM.
+export function registerView(name: string, func: ExecuteContractFunc, abi?: FunctionAbi) {
+    registerFunc(name, func, { ...abi, readOnly: true });
+}
+
+// Must match auth.AuthorizeFunction and auth.AuthorizeArgs in auth/contract.go
+const AUTHORIZE_FUNCTION_NAME = "authorize";
+const AUTHORIZE_ARGS: AbiParam[] = [
+    { name: "signers", type: "bytes" },
+    { name: "data", type: "bytes" },
+];
+
+// Registers [func] to authorize transactions sent on behalf of the contract,
+// which makes it usable as an account, for instance as a multisig. It runs as
+// a view when transactions are verified: transactions it rejects are dropped
+// and the fees of the others are paid by the contract.
+export function registerAuthorize(func: AuthorizeFunc) {
+    registerView(AUTHORIZE_FUNCTION_NAME, (payload, _actor, getBytes, _setBytes, _callContract, _ledger, context) => {
+        const [signers, data] = decodeValues(AUTHORIZE_ARGS, payload) as Uint8Array[];
+        const addresses: Uint8Array[] = [];
+        for (let offset = 0; offset < signers.length; offset += ADDRESS_LENGTH) {
+            addresses.push(signers.subarray(offset, offset + ADDRESS_LENGTH));
+        }
+        if (!func(addresses, data, getBytes, context)) {
+            throw new Error("Transaction not authorized.");
+        }
+        return new Uint8Array();
+    }, { args: AUTHORIZE_ARGS });
+}
+
+// Returns the JSON encoded ABI of the registered functions.
+function exportAbi(): Uint8Array {
+    const functions = Object.entries(abis).map(([name, abi]) => ({
+        name,
+        args: abi.args ?? [],
+        returns: abi.returns ?? [],
+        readOnly: abi.readOnly ?? false,
+    }));
+    return new TextEncoder().encode(JSON.stringify({ functions }));
 }
 
 export function execute() {
     try {
         const stdin = readStdin();
         const argsJSON = JSON.parse(stdin) as {
-            currentState: Record<string, string>,
             payload: string,
             actor: string,
             functionName: string,
+            contract: string,
+            value: string,
+            height: string,
+            timestamp: string,
+            chainId: string,
+            actionId: string,
+            readOnly: boolean,
         }
 
-        console.log('argsJSON', JSON.stringify(argsJSON))
-        console.log('stdin', stdin)
-
-
-        const stateBytes: Record<string, Uint8Array> = {};
-        for (const [key, value] of Object.entries(argsJSON.currentState)) {
-            stateBytes[Base64ToHexString(key)] = Uint8Array.from(Base64ToUint8Array(value));
-        }
-
-        const db = new TracebleDB(stateBytes);
         const actor = Base64ToUint8Array(argsJSON.actor);
         const payload = Base64ToUint8Array(argsJSON.payload);
         const functionName = argsJSON.functionName;
 
+        if (functionName === ABI_FUNCTION_NAME) {
+            writeStdOut(JSON.stringify({
+                success: true,
+                result: Uint8ArrayToBase64(exportAbi()),
+            }))
+            return
+        }
+
         const func = funcs[functionName];
         if (!func) {
             writeStdOut(JSON.stringify({
@@ -115,18 +211,37 @@ export function execute() {
             return
         }
 
-        const result = func(payload, actor, db.getValue.bind(db), db.setValue.bind(db))
-
-        const updatedKeys: Record<string, string> = {};
-        for (const [key, value] of Object.entries(db.getWriteAddresses())) {
-            updatedKeys[HexStringToBase64(key)] = Uint8ArrayToBase64(value);
+        if (views.has(functionName)) {
+            hostReadOnly();
+            readOnly = true;
+        } else if (argsJSON.readOnly) {
+            writeStdOut(JSON.stringify({
+                success: false,
+                error: `Function ${functionName} is not a view`,
+            }))
+            return
         }
 
+        const contract = Base64ToUint8Array(argsJSON.contract ?? "");
+        const ledger: Ledger = {
+            self: contract,
+            value: BigInt(argsJSON.value ?? "0"),
+            balanceOf,
+            transfer,
+        };
+        const context: Context = {
+            height: BigInt(argsJSON.height ?? "0"),
+            timestamp: BigInt(argsJSON.timestamp ?? "0"),
+            chainId: Base64ToUint8Array(argsJSON.chainId ?? ""),
+            actionId: Base64ToUint8Array(argsJSON.actionId ?? ""),
+            contract,
+        };
+
+        const result = func(payload, actor, getBytes, setBytes, callContract, ledger, context)
+
         writeStdOut(JSON.stringify({
             success: true,
             result: Uint8ArrayToBase64(result),
-            readKeys: db.getReadAdresses().map(key => HexStringToBase64(key)),
-            updatedKeys,
         }))
     } catch (e) {
         writeStdOut(JSON.stringify({
@@ -136,6 +251,7 @@ export function execute() {
     }
 }
 
+export * as abi from './abi';
 export * as encoders from './encoders';
 export * as io from './javy_io';
 export * from './types.d';
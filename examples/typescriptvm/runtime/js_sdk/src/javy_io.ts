@@ -43,6 +43,27 @@ export function writeStdErr(input: string) {
     writeFileSync(STDIO.Stderr, buffer);
 }
 
+// Writes the whole buffer with a single call, as expected by the host functions.
+export function writeStdErrBytes(buffer: Uint8Array) {
+    const bytesWritten = Javy.IO.writeSync(STDIO.Stderr, buffer);
+    if (bytesWritten !== buffer.length) {
+        throw Error("Host call was not accepted");
+    }
+}
+
+export function readStdinExact(length: number): Uint8Array {
+    const buffer = new Uint8Array(length);
+    let bytesUsed = 0;
+    while (bytesUsed < length) {
+        const bytesRead = Javy.IO.readSync(STDIO.Stdin, buffer.subarray(bytesUsed));
+        if (bytesRead <= 0) {
+            throw Error("Unexpected end of host response");
+        }
+        bytesUsed += bytesRead;
+    }
+    return buffer;
+}
+
 function writeFileSync(fd: number, buffer: Uint8Array) {
     while (buffer.length > 0) {
         // Try to write the entire buffer.
@@ -15,4 +15,34 @@ declare global {
 export type GetBytesFunc = (slot: Uint8Array, chunks: number) => Uint8Array;
 export type SetBytesFunc = (slot: Uint8Array, chunks: number, value: Uint8Array) => void;
 
-export type ExecuteContractFunc = (payload: Uint8Array, actor: Uint8Array, getBytes: GetBytesFunc, setBytes: SetBytesFunc) => Uint8Array;
+export type CallContractFunc = (address: Uint8Array, functionName: string, payload: Uint8Array, fuel?: number) => Uint8Array;
+
+// Ledger gives access to native token balances. Amounts are uint64.
+export type Ledger = {
+    // Address of the running contract
+    self: Uint8Array;
+    // Amount transferred to the contract with this call
+    value: bigint;
+    balanceOf: (address: Uint8Array) => bigint;
+    // Sends [amount] from the running contract to [to]
+    transfer: (to: Uint8Array, amount: bigint) => void;
+};
+
+// Context describes the block and action the contract is executed in.
+export type Context = {
+    // Height of the block being built or verified
+    height: bigint;
+    // Block timestamp, in milliseconds
+    timestamp: bigint;
+    chainId: Uint8Array;
+    actionId: Uint8Array;
+    // Address of the running contract
+    contract: Uint8Array;
+};
+
+// AuthorizeFunc decides whether a transaction signed by [signers] may act on
+// behalf of the contract, and pay its fees. [data] is chosen by the sender, for
+// instance to select a session key.
+export type AuthorizeFunc = (signers: Uint8Array[], data: Uint8Array, getBytes: GetBytesFunc, context: Context) => boolean;
+
+export type ExecuteContractFunc = (payload: Uint8Array, actor: Uint8Array, getBytes: GetBytesFunc, setBytes: SetBytesFunc, callContract: CallContractFunc, ledger: Ledger, context: Context) => Uint8Array;
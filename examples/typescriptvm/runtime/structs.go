@@ -1,47 +1,76 @@
 package runtime
 
-import (
-	"encoding/base64"
-	"encoding/json"
-	"time"
-)
+import "time"
 
 type StateProvider func(string) ([]byte, error)
 
+// ContractCaller runs a nested contract call on behalf of the running
+// contract. [maxFuel] is the part of the caller's fuel the callee may use.
+// Calls made by a read-only call must be read-only as well.
+type ContractCaller func(address []byte, functionName string, payload []byte, maxFuel uint64, readOnly bool) (*JavyExecResult, error)
+
+// BalanceProvider returns the native balance of an address.
+type BalanceProvider func(address []byte) (uint64, error)
+
+// TransferHandler moves native tokens from the running contract to [to].
+type TransferHandler func(to []byte, amount uint64) error
+
+// EventHandler records an event emitted by the running contract.
+type EventHandler func(topic string, data []byte) error
+
+// BlockContext describes the block and action a contract is executed in.
+type BlockContext struct {
+	// Height is the height of the block being built or verified
+	Height uint64
+	// Timestamp is the block timestamp, in milliseconds
+	Timestamp int64
+	ChainID   []byte
+	ActionID  []byte
+}
+
 type JavyExecParams struct {
-	MaxFuel       uint64
+	MaxFuel uint64
+	// MaxTime aborts execution after a wall-clock duration. It is not
+	// deterministic and must be zero when executing as part of consensus.
 	MaxTime       time.Duration
 	MaxMemory     int64
 	Bytecode      *[]byte
 	StateProvider StateProvider
-	Payload       []byte
-	FunctionName  string
-	Actor         []byte
+	// ContractCaller serves cross-contract calls. If nil, calls fail.
+	ContractCaller ContractCaller
+	// BalanceOf and Transfer give access to native balances. If nil, the
+	// corresponding calls fail.
+	BalanceOf BalanceProvider
+	Transfer  TransferHandler
+	// Emit records events. If nil, emitting fails.
+	Emit         EventHandler
+	Payload      []byte
+	FunctionName string
+	Actor        []byte
+	// Contract is the address of the running contract
+	Contract []byte
+	// Value is the amount transferred to the contract with the call
+	Value uint64
+	Block BlockContext
+	// ReadOnly calls may not write state, transfer funds nor emit events.
+	// Contracts may also switch their own call to read-only, which is how
+	// the js_sdk enforces views.
+	ReadOnly bool
 }
 
 // payload
 
 type JSPayload struct {
-	CurrentState *map[string][]byte `json:"currentState"`
-	Payload      []byte             `json:"payload"`
-	FunctionName string             `json:"functionName"`
-	Actor        []byte             `json:"actor"`
-}
-
-func (p JSPayload) MarshalJSON() ([]byte, error) {
-	type Alias JSPayload
-	encodedState := make(map[string][]byte)
-	for k, v := range *p.CurrentState {
-		encodedKey := base64.StdEncoding.EncodeToString([]byte(k))
-		encodedState[encodedKey] = v
-	}
-	return json.Marshal(&struct {
-		CurrentState map[string][]byte `json:"currentState"`
-		*Alias
-	}{
-		CurrentState: encodedState,
-		Alias:        (*Alias)(&p),
-	})
+	Payload      []byte `json:"payload"`
+	FunctionName string `json:"functionName"`
+	Actor        []byte `json:"actor"`
+	Contract     []byte `json:"contract"`
+	Value        uint64 `json:"value,string"`
+	Height       uint64 `json:"height,string"`
+	Timestamp    int64  `json:"timestamp,string"`
+	ChainID      []byte `json:"chainId"`
+	ActionID     []byte `json:"actionId"`
+	ReadOnly     bool   `json:"readOnly,omitempty"`
 }
 
 // state provider
@@ -74,35 +103,12 @@ func (d *DummyStateProvider) Update(newVals map[string][]byte) {
 //result json
 
 type ResultJSON struct {
-	Result      []byte            `json:"result"`
-	Success     bool              `json:"success"`
-	UpdatedKeys map[string][]byte `json:"updatedKeys"`
-	ReadKeys    [][]byte          `json:"readKeys"`
-	Error       string            `json:"error"`
-}
-
-func (r *ResultJSON) UnmarshalJSON(data []byte) error {
-	type Alias ResultJSON
-	aux := &struct {
-		UpdatedKeys map[string][]byte `json:"updatedKeys"`
-		*Alias
-	}{
-		Alias: (*Alias)(r),
-	}
-
-	if err := json.Unmarshal(data, &aux); err != nil {
-		return err
-	}
-
-	decodedKeys := make(map[string][]byte)
-	for k, v := range aux.UpdatedKeys {
-		decodedKey, err := base64.StdEncoding.DecodeString(k)
-		if err != nil {
-			return err
-		}
-		decodedKeys[string(decodedKey)] = v
-	}
-	r.UpdatedKeys = decodedKeys
-
-	return nil
+	Result  []byte `json:"result"`
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+
+	// Tracked by the host functions rather than reported by the contract.
+	// Empty values in UpdatedKeys are deletions.
+	UpdatedKeys map[string][]byte `json:"-"`
+	ReadKeys    [][]byte          `json:"-"`
 }
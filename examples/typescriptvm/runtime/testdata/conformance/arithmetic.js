@@ -0,0 +1,6 @@
+let acc = 0;
+for (let i = 0; i < 20000; i++) {
+    acc = (acc * 31 + i) % 1000003;
+    acc ^= (i << 3) & 0xffff;
+}
+respond(acc);
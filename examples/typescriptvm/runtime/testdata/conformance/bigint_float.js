@@ -0,0 +1,9 @@
+let big = 1n;
+for (let i = 1n; i <= 60n; i++) {
+    big *= i;
+}
+let f = 0;
+for (let i = 1; i < 2000; i++) {
+    f += Math.sin(i) * Math.sqrt(i) / Math.pow(1.0001, i);
+}
+respond(big.toString(36) + "|" + f.toFixed(12) + "|" + (0.1 + 0.2) + "|" + (1 / 3).toString(2).slice(0, 40));
@@ -0,0 +1,5 @@
+const values = [Date.now(), new Date().toISOString()];
+for (let i = 0; i < 5; i++) {
+    values.push(Math.random());
+}
+respond(JSON.stringify(values));
@@ -0,0 +1,16 @@
+const map = new Map();
+const set = new Set();
+let errors = 0;
+for (let i = 0; i < 3000; i++) {
+    map.set("k" + (i % 97), (map.get("k" + (i % 97)) || 0) + i);
+    set.add(i % 131);
+    try {
+        if (i % 17 === 0) {
+            throw new Error("boom " + i);
+        }
+    } catch (e) {
+        errors += e.message.length;
+    }
+}
+const keys = [...map.keys()].sort();
+respond(keys.length + ":" + set.size + ":" + errors + ":" + map.get(keys[0]));
@@ -0,0 +1,76 @@
+// Minimal stand-in for the js_sdk, speaking the host call protocol directly
+// so that the corpus can be compiled without a TypeScript toolchain.
+const HOST_CALL_MAGIC = [0x00, 0x74, 0x73, 0x76];
+const BASE64_CHARS = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+
+function readAll() {
+    let buffer = new Uint8Array(1024);
+    let used = 0;
+    while (true) {
+        const n = Javy.IO.readSync(0, buffer.subarray(used));
+        if (n === 0) {
+            return new TextDecoder().decode(buffer.subarray(0, used));
+        }
+        used += n;
+        if (used === buffer.length) {
+            const next = new Uint8Array(buffer.length * 2);
+            next.set(buffer);
+            buffer = next;
+        }
+    }
+}
+
+function readExact(length) {
+    const buffer = new Uint8Array(length);
+    let used = 0;
+    while (used < length) {
+        const n = Javy.IO.readSync(0, buffer.subarray(used));
+        if (n <= 0) {
+            throw Error("unexpected end of host response");
+        }
+        used += n;
+    }
+    return buffer;
+}
+
+function hostCall(op, key, value) {
+    const request = new Uint8Array(HOST_CALL_MAGIC.length + 3 + key.length + value.length);
+    request.set(HOST_CALL_MAGIC);
+    request[4] = op;
+    request[5] = (key.length >> 8) & 0xff;
+    request[6] = key.length & 0xff;
+    request.set(key, 7);
+    request.set(value, 7 + key.length);
+    if (Javy.IO.writeSync(2, request) !== request.length) {
+        throw Error("host call rejected");
+    }
+}
+
+function getBytes(key) {
+    hostCall(0, key, new Uint8Array());
+    const header = readExact(4);
+    return readExact(((header[0] << 24) | (header[1] << 16) | (header[2] << 8) | header[3]) >>> 0);
+}
+
+function setBytes(key, value) {
+    hostCall(1, key, value);
+}
+
+function toBase64(bytes) {
+    let out = "";
+    for (let i = 0; i < bytes.length; i += 3) {
+        const n = (bytes[i] << 16) | ((bytes[i + 1] || 0) << 8) | (bytes[i + 2] || 0);
+        out += BASE64_CHARS[(n >> 18) & 63] + BASE64_CHARS[(n >> 12) & 63];
+        out += i + 1 < bytes.length ? BASE64_CHARS[(n >> 6) & 63] : "=";
+        out += i + 2 < bytes.length ? BASE64_CHARS[n & 63] : "=";
+    }
+    return out;
+}
+
+function respond(result) {
+    const bytes = new TextEncoder().encode(String(result));
+    const out = JSON.stringify({ success: true, result: toBase64(bytes) });
+    Javy.IO.writeSync(1, new TextEncoder().encode(out));
+}
+
+const input = JSON.parse(readAll());
@@ -0,0 +1,10 @@
+const count = 8;
+let total = 0;
+for (let i = 1; i <= count; i++) {
+    const key = new Uint8Array([i, 0, 1]);
+    const value = getBytes(key);
+    total += value.length ? value[0] : 0;
+    setBytes(key, new Uint8Array([(value.length ? value[0] : 0) + 1, i]));
+}
+console.log("total", total);
+respond(total);
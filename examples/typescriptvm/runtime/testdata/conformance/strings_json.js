@@ -0,0 +1,9 @@
+const items = [];
+for (let i = 0; i < 200; i++) {
+    items.push({ id: i, name: "item-" + ((i * 7919) % 200), tags: ["a" + (i % 3), "b" + (i % 5)] });
+}
+items.sort((a, b) => a.name < b.name ? -1 : a.name > b.name ? 1 : a.id - b.id);
+const encoded = JSON.stringify(items);
+const decoded = JSON.parse(encoded);
+const summary = decoded.map(x => x.name.replace(/item-(\d+)/, "$1")).join(",");
+respond(summary.length + ":" + summary.slice(0, 64));
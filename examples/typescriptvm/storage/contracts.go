@@ -1,11 +1,13 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
 
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
@@ -32,19 +34,98 @@ func GenerateContractAddress(sender codec.Address, discriminator uint16) codec.A
 	return codec.CreateAddress(mconsts.SMARTCONTRACTID, id)
 }
 
+// ContractMetadata describes the deployment of a contract.
+type ContractMetadata struct {
+	// Admin may upgrade the contract and hand over its administration
+	Admin codec.Address `json:"admin"`
+	// Immutable contracts can no longer be upgraded
+	Immutable bool `json:"immutable"`
+	// Version starts at 1 and is incremented every time the code is upgraded
+	Version uint32 `json:"version"`
+	// CodeHash is the hash of the current bytecode
+	CodeHash ids.ID `json:"codeHash"`
+}
+
+const contractMetadataLen = codec.AddressLen + consts.BoolLen + consts.Uint32Len + ids.IDLen
+
+// [contractMetadataPrefix] + [address]
+func ContractMetadataKey(addr codec.Address) (k []byte) {
+	k = make([]byte, 1+codec.AddressLen+consts.Uint16Len)
+	k[0] = contractMetadataPrefix
+	copy(k[1:], addr[:])
+	binary.BigEndian.PutUint16(k[1+codec.AddressLen:], ContractMetadataChunks)
+	return
+}
+
+// ContractCodeHash identifies [bytecode] in [ContractMetadata].
+func ContractCodeHash(bytecode []byte) ids.ID {
+	return utils.ToID(bytecode)
+}
+
+func GetContractMetadata(
+	ctx context.Context,
+	im state.Immutable,
+	addr codec.Address,
+) (*ContractMetadata, error) {
+	return innerGetContractMetadata(im.GetValue(ctx, ContractMetadataKey(addr)))
+}
+
+// Used to serve RPC queries
+func GetContractMetadataFromState(
+	ctx context.Context,
+	f ReadState,
+	addr codec.Address,
+) (*ContractMetadata, error) {
+	values, errs := f(ctx, [][]byte{ContractMetadataKey(addr)})
+	return innerGetContractMetadata(values[0], errs[0])
+}
+
+func innerGetContractMetadata(v []byte, err error) (*ContractMetadata, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(v) != contractMetadataLen {
+		return nil, ErrInvalidContractMetadata
+	}
+	var md ContractMetadata
+	copy(md.Admin[:], v)
+	md.Immutable = v[codec.AddressLen] == 1
+	md.Version = binary.BigEndian.Uint32(v[codec.AddressLen+consts.BoolLen:])
+	copy(md.CodeHash[:], v[codec.AddressLen+consts.BoolLen+consts.Uint32Len:])
+	return &md, nil
+}
+
+func SetContractMetadata(
+	ctx context.Context,
+	mu state.Mutable,
+	addr codec.Address,
+	md *ContractMetadata,
+) error {
+	v := make([]byte, contractMetadataLen)
+	copy(v, md.Admin[:])
+	if md.Immutable {
+		v[codec.AddressLen] = 1
+	}
+	binary.BigEndian.PutUint32(v[codec.AddressLen+consts.BoolLen:], md.Version)
+	copy(v[codec.AddressLen+consts.BoolLen+consts.Uint32Len:], md.CodeHash[:])
+	return mu.Insert(ctx, ContractMetadataKey(addr), v)
+}
+
+// CreateContract deploys [bytecode] with [addr] as admin.
 func CreateContract(
 	ctx context.Context,
 	mu state.Mutable,
 	addr codec.Address,
 	bytecode []byte,
 	discriminator uint16,
+	immutable bool,
 ) (codec.Address, error) {
 	contractAddress := GenerateContractAddress(addr, discriminator)
 	bytecodeKey := ContractBytecodeKey(contractAddress)
 
 	_, err := mu.GetValue(ctx, bytecodeKey)
 	if err == nil {
-		return codec.EmptyAddress, errors.New("contract already exists")
+		return codec.EmptyAddress, ErrContractExists
 	} else if !errors.Is(err, database.ErrNotFound) {
 		return codec.EmptyAddress, err
 	}
@@ -54,9 +135,84 @@ func CreateContract(
 		return codec.EmptyAddress, err
 	}
 
+	err = SetContractMetadata(ctx, mu, contractAddress, &ContractMetadata{
+		Admin:     addr,
+		Immutable: immutable,
+		Version:   1,
+		CodeHash:  ContractCodeHash(bytecode),
+	})
+	if err != nil {
+		return codec.EmptyAddress, err
+	}
+
 	return contractAddress, nil
 }
 
+// UpgradeContract replaces the bytecode of a deployed contract, keeping its
+// state. Permissions are checked by the caller.
+func UpgradeContract(
+	ctx context.Context,
+	mu state.Mutable,
+	contractAddress codec.Address,
+	md *ContractMetadata,
+	bytecode []byte,
+) error {
+	if err := mu.Insert(ctx, ContractBytecodeKey(contractAddress), bytecode); err != nil {
+		return err
+	}
+	md.Version++
+	md.CodeHash = ContractCodeHash(bytecode)
+	return SetContractMetadata(ctx, mu, contractAddress, md)
+}
+
+// [contractABIPrefix] + [address]
+func ContractABIKey(addr codec.Address) (k []byte) {
+	k = make([]byte, 1+codec.AddressLen+consts.Uint16Len)
+	k[0] = contractABIPrefix
+	copy(k[1:], addr[:])
+	binary.BigEndian.PutUint16(k[1+codec.AddressLen:], ContractABIChunks)
+	return
+}
+
+// SetContractABI stores the JSON encoded ABI of a contract, or removes it if
+// [abi] is empty. It is verified by the caller.
+func SetContractABI(
+	ctx context.Context,
+	mu state.Mutable,
+	addr codec.Address,
+	abi []byte,
+) error {
+	return WriteContractStateValue(ctx, mu, ContractABIKey(addr), abi)
+}
+
+// GetContractABI returns the JSON encoded ABI of a contract, or an empty value
+// if it was deployed without one.
+func GetContractABI(
+	ctx context.Context,
+	im state.Immutable,
+	addr codec.Address,
+) ([]byte, error) {
+	val, err := im.GetValue(ctx, ContractABIKey(addr))
+	if errors.Is(err, database.ErrNotFound) {
+		return []byte{}, nil
+	}
+	return val, err
+}
+
+// Used to serve RPC queries. Contracts deployed without an ABI return an
+// empty value.
+func GetContractABIFromState(
+	ctx context.Context,
+	f ReadState,
+	addr codec.Address,
+) ([]byte, error) {
+	values, errs := f(ctx, [][]byte{ContractABIKey(addr)})
+	if errors.Is(errs[0], database.ErrNotFound) {
+		return []byte{}, nil
+	}
+	return values[0], errs[0]
+}
+
 // Used to serve RPC queries
 func GetContractBytecodeFromState(
 	ctx context.Context,
@@ -73,6 +229,66 @@ func GetContractBytecodeFromState(
 	return values[0], errs[0]
 }
 
+// GetContractStateFromState reads the values of [slots] of a contract. Slots
+// that were never written, or were deleted, have a nil value.
+func GetContractStateFromState(
+	ctx context.Context,
+	f ReadState,
+	addr codec.Address,
+	slots [][]byte,
+) ([][]byte, error) {
+	keys := make([][]byte, len(slots))
+	for i, slot := range slots {
+		keys[i] = ContractStateKey(addr, slot)
+	}
+	values, errs := f(ctx, keys)
+	for i, err := range errs {
+		if errors.Is(err, database.ErrNotFound) {
+			values[i] = nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// ContractStateEntry is a slot of a contract, including its chunks suffix,
+// and its value.
+type ContractStateEntry struct {
+	Slot  []byte
+	Value []byte
+}
+
+// GetContractStorage returns up to [limit] slots of a contract in key order,
+// starting at [start]. The returned cursor is the slot to start the next page
+// at, or nil once every slot has been returned.
+func GetContractStorage(
+	_ context.Context,
+	db database.Iteratee,
+	addr codec.Address,
+	start []byte,
+	limit int,
+) ([]*ContractStateEntry, []byte, error) {
+	prefix := ContractStateKey(addr, nil)
+	it := db.NewIteratorWithStartAndPrefix(ContractStateKey(addr, start), prefix)
+	defer it.Release()
+
+	entries := []*ContractStateEntry{}
+	for it.Next() {
+		slot := bytes.Clone(it.Key()[len(prefix):])
+		if len(entries) == limit {
+			return entries, slot, it.Error()
+		}
+		entries = append(entries, &ContractStateEntry{
+			Slot:  slot,
+			Value: bytes.Clone(it.Value()),
+		})
+	}
+	return entries, nil, it.Error()
+}
+
 func ContractStateKey(contractAddr codec.Address, postfix []byte) []byte {
 	return append(append([]byte{contractStatePrefix}, contractAddr[:]...), postfix[:]...)
 }
@@ -130,10 +346,19 @@ func UpdateContractStateFields(
 ) error {
 	for key, val := range fields {
 		k := ContractStateKey(contractAddress, []byte(key))
-		err := mu.Insert(ctx, k, val)
-		if err != nil {
+		if err := WriteContractStateValue(ctx, mu, k, val); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// WriteContractStateValue inserts [val] at the contract state key [k], or
+// removes the key if [val] is empty. Contracts read missing keys as empty
+// values, so both are equivalent to them but removing frees the storage.
+func WriteContractStateValue(ctx context.Context, mu state.Mutable, k []byte, val []byte) error {
+	if len(val) == 0 {
+		return mu.Remove(ctx, k)
+	}
+	return mu.Insert(ctx, k, val)
+}
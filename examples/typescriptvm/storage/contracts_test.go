@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+func TestGetContractStorage(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	var (
+		contract = codec.Address{0x05, 0x01}
+		other    = codec.Address{0x05, 0x02}
+		db       = memdb.New()
+	)
+	for _, slot := range [][]byte{{1, 0, 1}, {2, 0, 1}, {3, 0, 1}} {
+		require.NoError(db.Put(ContractStateKey(contract, slot), slot[:1]))
+	}
+	require.NoError(db.Put(ContractStateKey(other, []byte{1, 0, 1}), []byte{9}))
+	require.NoError(db.Put(ContractBytecodeKey(contract), []byte{9}))
+
+	entries, cursor, err := GetContractStorage(ctx, db, contract, nil, 2)
+	require.NoError(err)
+	require.Equal([]*ContractStateEntry{
+		{Slot: []byte{1, 0, 1}, Value: []byte{1}},
+		{Slot: []byte{2, 0, 1}, Value: []byte{2}},
+	}, entries)
+	require.Equal([]byte{3, 0, 1}, cursor)
+
+	entries, cursor, err = GetContractStorage(ctx, db, contract, cursor, 2)
+	require.NoError(err)
+	require.Equal([]*ContractStateEntry{{Slot: []byte{3, 0, 1}, Value: []byte{3}}}, entries)
+	require.Nil(cursor)
+}
@@ -5,4 +5,8 @@ package storage
 
 import "errors"
 
-var ErrInvalidBalance = errors.New("invalid balance")
+var (
+	ErrInvalidBalance          = errors.New("invalid balance")
+	ErrContractExists          = errors.New("contract already exists")
+	ErrInvalidContractMetadata = errors.New("invalid contract metadata")
+)
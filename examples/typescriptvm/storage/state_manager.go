@@ -9,9 +9,14 @@ import (
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
 )
 
-var _ (chain.StateManager) = (*StateManager)(nil)
+var (
+	_ (chain.StateManager) = (*StateManager)(nil)
+	_ (chain.FeeRefunder)  = (*StateManager)(nil)
+)
 
 type StateManager struct{}
 
@@ -27,10 +32,20 @@ func (*StateManager) FeeKey() []byte {
 	return FeeKey()
 }
 
+// SponsorStateKeys also declares the bytecode and the ABI of contract
+// sponsors, which authorize the transactions they pay for, and the height
+// given to them.
 func (*StateManager) SponsorStateKeys(addr codec.Address) state.Keys {
-	return state.Keys{
-		string(BalanceKey(addr)): state.Read | state.Write,
+	// [Deduct] removes emptied balances, which [Refund] re-creates
+	stateKeys := state.Keys{
+		string(BalanceKey(addr)): state.All,
+	}
+	if addr[0] == mconsts.SMARTCONTRACTID {
+		stateKeys[string(ContractBytecodeKey(addr))] = state.Read
+		stateKeys[string(ContractABIKey(addr))] = state.Read
+		stateKeys[string(ChainHeightKey())] = state.Read
 	}
+	return stateKeys
 }
 
 func (*StateManager) CanDeduct(
@@ -57,3 +72,14 @@ func (*StateManager) Deduct(
 ) error {
 	return SubBalance(ctx, mu, addr, amount)
 }
+
+// Refund returns unused fees to [addr]. The balance key may have been removed
+// by [Deduct], so it is re-created if needed.
+func (*StateManager) Refund(
+	ctx context.Context,
+	addr codec.Address,
+	mu state.Mutable,
+	amount uint64,
+) error {
+	return AddBalance(ctx, mu, addr, amount, true)
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/tstate"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/typescriptvm/consts"
+)
+
+func TestRefundEmptiedBalance(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	sm := &StateManager{}
+	addr := codec.CreateAddress(mconsts.ED25519ID, ids.GenerateTestID())
+	stateKeys := sm.SponsorStateKeys(addr)
+	tsv := tstate.New(len(stateKeys)).NewView(stateKeys, map[string][]byte{
+		string(BalanceKey(addr)): binary.BigEndian.AppendUint64(nil, 100),
+	})
+
+	// Deducting the whole balance removes it, and refunding re-creates it
+	require.NoError(sm.Deduct(ctx, addr, tsv, 100))
+	_, err := tsv.GetValue(ctx, BalanceKey(addr))
+	require.Error(err)
+	require.NoError(sm.Refund(ctx, addr, tsv, 40))
+	balance, err := GetBalance(ctx, tsv, addr)
+	require.NoError(err)
+	require.Equal(uint64(40), balance)
+}
@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
@@ -11,7 +12,10 @@ import (
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
 
+	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/fees"
@@ -23,9 +27,20 @@ import (
 
 type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 
+var _ state.Immutable = ReadState(nil)
+
+// GetValue allows [ReadState] to be used as a [state.Immutable] when serving
+// RPC queries.
+func (f ReadState) GetValue(ctx context.Context, key []byte) ([]byte, error) {
+	values, errs := f(ctx, [][]byte{key})
+	return values[0], errs[0]
+}
+
 // Metadata
 // 0x0/ (tx)
 //   -> [txID] => timestamp
+// 0x1/ (event)
+//   -> [emitter|height|txIndex|eventIndex] => txID|event
 //
 // State
 // / (height) => store in root
@@ -38,7 +53,8 @@ type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 
 const (
 	// metaDB
-	txPrefix = 0x0
+	txPrefix    = 0x0
+	eventPrefix = 0x1
 
 	// stateDB
 	balancePrefix          = 0x0
@@ -47,11 +63,22 @@ const (
 	feePrefix              = 0x3
 	contractBytecodePrefix = 0x4
 	contractStatePrefix    = 0x5
+	contractMetadataPrefix = 0x6
+	contractABIPrefix      = 0x7
 )
 
 const BalanceChunks uint16 = 1
 const ContractBytecodeChunks uint16 = 2048 // 128kb / 64 bytes
 
+// MaxContractBytecodeSize is the largest bytecode that fits in
+// [ContractBytecodeChunks], which also counts a partially filled last chunk.
+const MaxContractBytecodeSize = int(ContractBytecodeChunks)*64 - 1
+const ContractMetadataChunks uint16 = 2
+const ContractABIChunks uint16 = 256 // 16kb / 64 bytes
+
+// MaxContractABISize is the largest ABI that fits in [ContractABIChunks].
+const MaxContractABISize = int(ContractABIChunks)*64 - 1
+
 var (
 	failureByte  = byte(0x0)
 	successByte  = byte(0x1)
@@ -116,6 +143,88 @@ func GetTransaction(
 	return true, t, success, d, fee, nil
 }
 
+// StoredEvent is an event emitted by an accepted transaction.
+type StoredEvent struct {
+	TxID   ids.ID
+	Height uint64
+	Event  *chain.Event
+}
+
+// [eventPrefix] + [emitter] + [height] + [txIndex] + [eventIndex]
+func EventKey(emitter codec.Address, height uint64, txIndex uint32, eventIndex uint16) (k []byte) {
+	k = make([]byte, 1+codec.AddressLen+consts.Uint64Len+consts.Uint32Len+consts.Uint16Len)
+	k[0] = eventPrefix
+	copy(k[1:], emitter[:])
+	binary.BigEndian.PutUint64(k[1+codec.AddressLen:], height)
+	binary.BigEndian.PutUint32(k[1+codec.AddressLen+consts.Uint64Len:], txIndex)
+	binary.BigEndian.PutUint16(k[1+codec.AddressLen+consts.Uint64Len+consts.Uint32Len:], eventIndex)
+	return
+}
+
+// StoreEvent indexes [event], the [eventIndex]-th event of the [txIndex]-th
+// transaction of the block at [height], by emitter and height.
+func StoreEvent(
+	_ context.Context,
+	db database.KeyValueWriter,
+	height uint64,
+	txIndex uint32,
+	eventIndex uint16,
+	txID ids.ID,
+	event *chain.Event,
+) error {
+	k := EventKey(event.Emitter, height, txIndex, eventIndex)
+	p := codec.NewWriter(ids.IDLen+event.Size(), consts.MaxInt)
+	p.PackID(txID)
+	event.Marshal(p)
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return db.Put(k, p.Bytes())
+}
+
+// GetEvents returns up to [limit] events of [emitter] emitted between
+// [fromHeight] and [toHeight] (inclusive), in the order they were emitted. If
+// [topic] is not empty, only events with that topic are returned.
+func GetEvents(
+	_ context.Context,
+	db database.Iteratee,
+	emitter codec.Address,
+	topic string,
+	fromHeight uint64,
+	toHeight uint64,
+	limit int,
+) ([]*StoredEvent, error) {
+	prefix := make([]byte, 1+codec.AddressLen)
+	prefix[0] = eventPrefix
+	copy(prefix[1:], emitter[:])
+	it := db.NewIteratorWithStartAndPrefix(EventKey(emitter, fromHeight, 0, 0), prefix)
+	defer it.Release()
+
+	events := []*StoredEvent{}
+	for len(events) < limit && it.Next() {
+		height := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		if height > toHeight {
+			break
+		}
+		p := codec.NewReader(it.Value(), consts.MaxInt)
+		var txID ids.ID
+		p.UnpackID(true, &txID)
+		event, err := chain.UnmarshalEvent(p)
+		if err != nil {
+			return nil, err
+		}
+		if len(topic) > 0 && event.Topic != topic {
+			continue
+		}
+		events = append(events, &StoredEvent{
+			TxID:   txID,
+			Height: height,
+			Event:  event,
+		})
+	}
+	return events, it.Error()
+}
+
 // [balancePrefix] + [address]
 func BalanceKey(addr codec.Address) (k []byte) {
 	k = make([]byte, 1+codec.AddressLen+consts.Uint16Len)
@@ -257,3 +366,65 @@ func TimestampKey() (k []byte) {
 func FeeKey() (k []byte) {
 	return feeKey
 }
+
+// ChainHeightKey is the key under which the chain records the height of the
+// last block applied to state.
+func ChainHeightKey() []byte {
+	return chain.HeightKey(heightKey)
+}
+
+// GetParentHeight returns the height of the last block applied to [im]. While
+// a block executes, this is the height of its parent.
+func GetParentHeight(ctx context.Context, im state.Immutable) (uint64, error) {
+	v, err := im.GetValue(ctx, ChainHeightKey())
+	if err != nil {
+		return 0, err
+	}
+	if len(v) != consts.Uint64Len {
+		return 0, fmt.Errorf("invalid height length %d", len(v))
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// ChainTimestampKey is the key under which the chain records the timestamp
+// of the last block applied to state.
+func ChainTimestampKey() []byte {
+	return chain.TimestampKey(timestampKey)
+}
+
+// GetParentTimestamp returns the timestamp, in milliseconds, of the last
+// block applied to [im].
+func GetParentTimestamp(ctx context.Context, im state.Immutable) (int64, error) {
+	v, err := im.GetValue(ctx, ChainTimestampKey())
+	if err != nil {
+		return 0, err
+	}
+	if len(v) != consts.Uint64Len {
+		return 0, fmt.Errorf("invalid timestamp length %d", len(v))
+	}
+	return int64(binary.BigEndian.Uint64(v)), nil
+}
+
+// ReadStateAtRoot reads the state as of [root], which must still be in the
+// history of [db], to serve queries against past blocks. All reads see the
+// same state, even if blocks are accepted meanwhile.
+func ReadStateAtRoot(db merkledb.RangeProofer, root ids.ID) ReadState {
+	return func(ctx context.Context, keys [][]byte) ([][]byte, []error) {
+		values := make([][]byte, len(keys))
+		errs := make([]error, len(keys))
+		for i, key := range keys {
+			proof, err := db.GetRangeProofAtRoot(ctx, root, maybe.Some(key), maybe.Some(key), 1)
+			switch {
+			case errors.Is(err, merkledb.ErrEmptyProof):
+				errs[i] = database.ErrNotFound
+			case err != nil:
+				errs[i] = err
+			case len(proof.KeyValues) == 0 || !bytes.Equal(proof.KeyValues[0].Key, key):
+				errs[i] = database.ErrNotFound
+			default:
+				values[i] = proof.KeyValues[0].Value
+			}
+		}
+		return values, errs
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStateAtRoot(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db, err := merkledb.New(ctx, memdb.New(), merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor16,
+		RootGenConcurrency:          1,
+		HistoryLength:               4,
+		ValueNodeCacheSize:          1024,
+		IntermediateNodeCacheSize:   1024,
+		IntermediateWriteBufferSize: 1024,
+		IntermediateWriteBatchSize:  1024,
+		Reg:                         prometheus.NewRegistry(),
+		TraceLevel:                  merkledb.InfoTrace,
+		Tracer:                      trace.Noop,
+	})
+	require.NoError(err)
+
+	var (
+		changed = []byte{1}
+		deleted = []byte{2}
+		created = []byte{3}
+	)
+	require.NoError(db.Put(changed, []byte{1}))
+	require.NoError(db.Put(deleted, []byte{2}))
+	root, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	require.NoError(db.Put(changed, []byte{10}))
+	require.NoError(db.Delete(deleted))
+	require.NoError(db.Put(created, []byte{3}))
+
+	values, errs := ReadStateAtRoot(db, root)(ctx, [][]byte{changed, deleted, created})
+	require.Equal([][]byte{{1}, {2}, nil}, values)
+	require.NoError(errs[0])
+	require.NoError(errs[1])
+	require.ErrorIs(errs[2], database.ErrNotFound)
+
+	latest, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	values, errs = ReadStateAtRoot(db, latest)(ctx, [][]byte{changed, deleted, created})
+	require.Equal([][]byte{{10}, nil, {3}}, values)
+	require.NoError(errs[0])
+	require.ErrorIs(errs[1], database.ErrNotFound)
+	require.NoError(errs[2])
+}
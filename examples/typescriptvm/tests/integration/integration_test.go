@@ -324,8 +324,8 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 	// read: 2 keys reads
 	// allocate: 1 key created with 1 chunk
 	// write: 2 keys modified
-	transferTxUnits := fees.Dimensions{188, 7, 14, 50, 26}
-	transferTxFee := uint64(285)
+	transferTxUnits := fees.Dimensions{200, 7, 14, 50, 26}
+	transferTxFee := uint64(297)
 
 	ginkgo.It("get currently accepted block ID", func() {
 		for _, inst := range instances {
@@ -343,6 +343,39 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			require.Equal(balance, uint64(10_000_000))
 		})
 
+		ginkgo.By("simulate TransferTx", func() {
+			parser, err := instances[0].lcli.Parser(context.Background())
+			require.NoError(err)
+			_, transferTx, maxFee, err := instances[0].cli.GenerateTransaction(
+				context.Background(),
+				parser,
+				[]chain.Action{&actions.Transfer{
+					To:    addr2,
+					Value: 100_000,
+				}},
+				factory,
+			)
+			require.NoError(err)
+			require.Equal(transferTxFee, maxFee)
+
+			sim, err := instances[0].cli.SimulateTx(context.Background(), transferTx.Bytes())
+			require.NoError(err)
+			require.True(sim.Success, sim.Error)
+			require.Equal([][][]byte{{}}, sim.Outputs)
+			require.Equal(transferTxUnits, sim.Units)
+			require.Equal(transferTxUnits, sim.MaxUnits)
+			require.Equal(transferTxFee, sim.Fee)
+			require.Len(sim.StateKeys, 2)
+			for _, k := range sim.StateKeys {
+				require.True(k.Written)
+				require.Equal(uint16(1), k.Chunks)
+			}
+
+			balance, err := instances[0].lcli.Balance(context.Background(), addrStr)
+			require.NoError(err)
+			require.Equal(balance, uint64(10_000_000))
+		})
+
 		ginkgo.By("issue TransferTx", func() {
 			parser, err := instances[0].lcli.Parser(context.Background())
 			require.NoError(err)
@@ -446,11 +479,61 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 		ginkgo.By("ensure balance is updated", func() {
 			balance, err := instances[1].lcli.Balance(context.Background(), addrStr)
 			require.NoError(err)
-			require.Equal(balance, uint64(9_899_715))
+			require.Equal(balance, uint64(9_899_703))
 			balance2, err := instances[1].lcli.Balance(context.Background(), addrStr2)
 			require.NoError(err)
 			require.Equal(balance2, uint64(100_000))
 		})
+
+		ginkgo.By("explore the accepted block", func() {
+			ctx := context.Background()
+			cli := instances[1].cli
+			blk := blocks[len(blocks)-1]
+
+			byID, err := cli.GetBlock(ctx, blk.ID())
+			require.NoError(err)
+			require.Equal(blk.ID(), byID.Header.ID)
+			require.Equal(blk.Height(), byID.Header.Height)
+			require.Equal(blk.Parent(), byID.Header.Parent)
+			require.Len(byID.Transactions, 1)
+			byHeight, err := cli.GetBlockByHeight(ctx, blk.Height())
+			require.NoError(err)
+			require.Equal(byID, byHeight)
+
+			tx := byID.Transactions[0]
+			require.Equal(transferTxRoot.ID(), tx.ID)
+			require.Equal(lconsts.TransferID, tx.Actions[0].TypeID)
+			var transfer actions.Transfer
+			require.NoError(json.Unmarshal(tx.Actions[0].Action, &transfer))
+			require.Equal(addr2, transfer.To)
+			require.Equal(uint64(100_000), transfer.Value)
+			require.Equal(addr, tx.Actor)
+			require.True(tx.Result.Success)
+			require.Equal(transferTxUnits, tx.Result.Units)
+			require.Equal(transferTxFee, tx.Result.Fee)
+			parser, err := instances[1].lcli.Parser(ctx)
+			require.NoError(err)
+			parsed, err := tx.Parse(parser)
+			require.NoError(err)
+			require.Equal(transferTxRoot.Bytes(), parsed.Bytes())
+
+			accepted, err := cli.GetTx(ctx, transferTxRoot.ID())
+			require.NoError(err)
+			require.Equal(blk.ID(), accepted.BlockID)
+			require.Equal(blk.Height(), accepted.Height)
+			require.Equal(tx, accepted.Tx)
+			_, err = cli.GetTx(ctx, ids.GenerateTestID())
+			require.ErrorContains(err, "not found")
+
+			headers, missing, err := cli.GetBlockHeaders(ctx, 0, 0)
+			require.NoError(err)
+			require.Empty(missing)
+			require.Len(headers, int(blk.Height())+1)
+			require.Equal(byID.Header, headers[len(headers)-1])
+			for i, header := range headers[1:] {
+				require.Equal(headers[i].ID, header.Parent)
+			}
+		})
 	})
 
 	ginkgo.It("ensure multiple txs work ", func() {
@@ -27,6 +27,7 @@ func TestRPCEcho(t *testing.T) {
 		"echo",
 		[]byte{70},
 		prep.addrStr,
+		0,
 	)
 	require.True(t, callResult.Success, string(callResult.Error))
 	require.NoError(t, err)
@@ -41,13 +42,13 @@ func TestExecuteIncrement(t *testing.T) {
 	require.NoError(t, err)
 
 	//should be zero at first call
-	callResult, err := prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "read", []byte{}, prep.addrStr)
+	callResult, err := prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "read", []byte{}, prep.addrStr, 0)
 	require.NoError(t, err)
 	require.Equal(t, []byte("0"), callResult.Result)
 
 	//execute increment only to figure out keys
 
-	callResult, err = prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "increment", []byte{0x7}, prep.addrStr)
+	callResult, err = prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "increment", []byte{0x7}, prep.addrStr, 0)
 	require.NoError(t, err)
 
 	//now execute increment in transaction
@@ -73,7 +74,7 @@ func TestExecuteIncrement(t *testing.T) {
 	require.True(t, results[0].Success, string(results[0].Error))
 
 	//check again
-	callResult, err = prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "read", []byte{}, prep.addrStr)
+	callResult, err = prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "read", []byte{}, prep.addrStr, 0)
 	require.NoError(t, err)
 	require.Equal(t, []byte("7"), callResult.Result)
 }
@@ -90,7 +91,7 @@ func TestExecuteManyReadsAndWrites(t *testing.T) {
 	//execute WRITE_MANY_SLOTS to write slotsToWrite slots
 	writePayload := []byte{slotsToWrite}
 
-	callResult, err := prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "writeManySlots", writePayload, prep.addrStr)
+	callResult, err := prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "writeManySlots", writePayload, prep.addrStr, 0)
 	require.NoError(t, err)
 
 	//now execute increment in transaction
@@ -118,7 +119,7 @@ func TestExecuteManyReadsAndWrites(t *testing.T) {
 	//execute a bunch of reads in a transaction
 	readPayload := []byte{slotsToWrite}
 
-	callResult, err = prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "readManySlots", readPayload, prep.addrStr)
+	callResult, err = prep.instance.lcli.ExecuteContract(context.Background(), contractAddrString, "readManySlots", readPayload, prep.addrStr, 0)
 	require.NoError(t, err)
 
 	//now execute increment in transaction
@@ -162,6 +162,21 @@ func (f *Manager) Consume(d Dimensions, l Dimensions) (bool, Dimension) {
 	return true, 0
 }
 
+// Refund returns [d] previously consumed units, for transactions that used
+// less than they reserved. Consumption never goes below zero.
+func (f *Manager) Refund(d Dimensions) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	for i := Dimension(0); i < FeeDimensions; i++ {
+		consumed, err := math.Sub(f.lastConsumed(i), d[i])
+		if err != nil {
+			consumed = 0
+		}
+		f.setLastConsumed(i, consumed)
+	}
+}
+
 func (f *Manager) Bytes() []byte {
 	f.l.RLock()
 	defer f.l.RUnlock()
@@ -292,6 +307,18 @@ func Add(a, b Dimensions) (Dimensions, error) {
 	return d, nil
 }
 
+func Sub(a, b Dimensions) (Dimensions, error) {
+	d := Dimensions{}
+	for i := Dimension(0); i < FeeDimensions; i++ {
+		v, err := math.Sub(a[i], b[i])
+		if err != nil {
+			return Dimensions{}, err
+		}
+		d[i] = v
+	}
+	return d, nil
+}
+
 func MulSum(a, b Dimensions) (uint64, error) {
 	val := uint64(0)
 	for i := Dimension(0); i < FeeDimensions; i++ {
@@ -15,7 +15,6 @@ import (
 
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/eheap"
-	"github.com/ava-labs/hypersdk/list"
 )
 
 const maxPrealloc = 4_096
@@ -25,8 +24,15 @@ type Item interface {
 
 	Sponsor() codec.Address
 	Size() int
+
+	// Priority orders items in the mempool, highest first. Items of equal
+	// priority are ordered by arrival. When the mempool is full, the item with
+	// the lowest priority is evicted, as every item takes one of its slots.
+	Priority() uint64
 }
 
+// Mempool holds items by descending [Item.Priority], so that block building
+// and gossip see the items that pay the most for inclusion first.
 type Mempool[T Item] struct {
 	tracer trace.Tracer
 
@@ -37,8 +43,15 @@ type Mempool[T Item] struct {
 	maxSize        int
 	maxSponsorSize int // Maximum items allowed by a single sponsor
 
-	queue *list.List[T]
-	eh    *eheap.ExpiryHeap[*list.Element[T]]
+	queue  *queue[T]
+	lowest *queue[T] // reversed [queue], to evict the lowest item when full
+	eh     *eheap.ExpiryHeap[*entry[T]]
+
+	// nextSeq and frontSeq are the sequence numbers of the next item added
+	// and of the next item restored. Restored items are ahead of items of
+	// equal priority.
+	nextSeq  int64
+	frontSeq int64
 
 	// owned tracks the number of items in the mempool owned by a single
 	// [Sponsor]
@@ -69,8 +82,9 @@ func New[T Item](
 		maxSize:        maxSize,
 		maxSponsorSize: maxSponsorSize,
 
-		queue: &list.List[T]{},
-		eh:    eheap.New[*list.Element[T]](min(maxSize, maxPrealloc)),
+		queue:  newQueue[T](min(maxSize, maxPrealloc), false),
+		lowest: newQueue[T](min(maxSize, maxPrealloc), true),
+		eh:     eheap.New[*entry[T]](min(maxSize, maxPrealloc)),
 
 		owned:          map[codec.Address]int{},
 		exemptSponsors: set.Set[codec.Address]{},
@@ -107,9 +121,9 @@ func (m *Mempool[T]) Has(ctx context.Context, itemID ids.ID) bool {
 }
 
 // Add pushes all new items from [items] to m. Does not add a item if
-// the item sponsor is not exempt and their items in the mempool exceed m.maxSponsorSize.
-// If the size of m exceeds m.maxSize, Add pops the lowest value item
-// from m.eh.
+// the item sponsor is not exempt and their items in the mempool exceed m.maxSponsorSize,
+// or if m is full and the item has no higher priority than the lowest item,
+// which is evicted otherwise.
 func (m *Mempool[T]) Add(ctx context.Context, items []T) {
 	_, span := m.tracer.Start(ctx, "Mempool.Add")
 	defer span.End()
@@ -121,7 +135,11 @@ func (m *Mempool[T]) Add(ctx context.Context, items []T) {
 }
 
 func (m *Mempool[T]) add(items []T, front bool) {
-	for _, item := range items {
+	// Restored items keep their relative order
+	if front {
+		m.frontSeq -= int64(len(items))
+	}
+	for i, item := range items {
 		sender := item.Sponsor()
 
 		// Ensure no duplicate
@@ -140,19 +158,28 @@ func (m *Mempool[T]) add(items []T, front bool) {
 			continue // do nothing, wait for items to expire
 		}
 
-		// Ensure mempool isn't full
-		if m.queue.Size() == m.maxSize {
-			continue // do nothing, wait for items to expire
+		// Ensure mempool isn't full, or make room if the item pays more than
+		// the lowest item
+		priority := item.Priority()
+		if m.queue.Len() == m.maxSize {
+			lowest := m.lowest.first()
+			if lowest.priority >= priority {
+				continue // do nothing, wait for items to expire
+			}
+			m.remove(lowest)
 		}
 
 		// Add to mempool
-		var elem *list.Element[T]
+		e := &entry[T]{item: item, priority: priority}
 		if !front {
-			elem = m.queue.PushBack(item)
+			e.seq = m.nextSeq
+			m.nextSeq++
 		} else {
-			elem = m.queue.PushFront(item)
+			e.seq = m.frontSeq + int64(i)
 		}
-		m.eh.Add(elem)
+		m.queue.push(e)
+		m.lowest.push(e)
+		m.eh.Add(e)
 		m.owned[sender]++
 		m.pendingSize += item.Size()
 	}
@@ -167,11 +194,11 @@ func (m *Mempool[T]) PeekNext(ctx context.Context) (T, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	first := m.queue.First()
+	first := m.queue.first()
 	if first == nil {
 		return *new(T), false
 	}
-	return first.Value(), true
+	return first.item, true
 }
 
 // PopNext removes and returns the highest valued item in m.eh.
@@ -187,17 +214,27 @@ func (m *Mempool[T]) PopNext(ctx context.Context) (T, bool) { // O(log N)
 }
 
 func (m *Mempool[T]) popNext() (T, bool) {
-	first := m.queue.First()
+	first := m.queue.pop()
 	if first == nil {
 		return *new(T), false
 	}
-	v := m.queue.Remove(first)
+	v := first.item
+	m.lowest.remove(first)
 	m.eh.Remove(v.ID())
 	m.removeFromOwned(v)
 	m.pendingSize -= v.Size()
 	return v, true
 }
 
+// remove removes [e], which must be in m.
+func (m *Mempool[T]) remove(e *entry[T]) {
+	m.queue.remove(e)
+	m.lowest.remove(e)
+	m.eh.Remove(e.ID())
+	m.removeFromOwned(e.item)
+	m.pendingSize -= e.item.Size()
+}
+
 // Remove removes [items] from m.
 func (m *Mempool[T]) Remove(ctx context.Context, items []T) {
 	_, span := m.tracer.Start(ctx, "Mempool.Remove")
@@ -207,11 +244,12 @@ func (m *Mempool[T]) Remove(ctx context.Context, items []T) {
 	defer m.mu.Unlock()
 
 	for _, item := range items {
-		elem, ok := m.eh.Remove(item.ID())
+		e, ok := m.eh.Remove(item.ID())
 		if !ok {
 			continue
 		}
-		m.queue.Remove(elem)
+		m.queue.remove(e)
+		m.lowest.remove(e)
 		m.removeFromOwned(item)
 		m.pendingSize -= item.Size()
 	}
@@ -247,8 +285,9 @@ func (m *Mempool[T]) SetMinTimestamp(ctx context.Context, t int64) []T {
 	removedElems := m.eh.SetMin(t)
 	removed := make([]T, len(removedElems))
 	for i, remove := range removedElems {
-		m.queue.Remove(remove)
-		v := remove.Value()
+		m.queue.remove(remove)
+		m.lowest.remove(remove)
+		v := remove.item
 		m.removeFromOwned(v)
 		m.pendingSize -= v.Size()
 		removed[i] = v
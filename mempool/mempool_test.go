@@ -21,6 +21,7 @@ type TestItem struct {
 	id        ids.ID
 	sponsor   codec.Address
 	timestamp int64
+	priority  uint64
 }
 
 func (mti *TestItem) ID() ids.ID {
@@ -39,6 +40,10 @@ func (*TestItem) Size() int {
 	return 2 // distinguish from len
 }
 
+func (mti *TestItem) Priority() uint64 {
+	return mti.priority
+}
+
 func GenerateTestItem(sponsor codec.Address, t int64) *TestItem {
 	id := ids.GenerateTestID()
 	return &TestItem{
@@ -192,3 +197,106 @@ func TestMempoolSetMinTimestamp(t *testing.T) {
 	// Mempool has same length
 	require.Equal(5, txm.Len(ctx), "Mempool has incorrect number of txs.")
 }
+
+func TestMempoolPriority(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+
+	txm := New[*TestItem](tracer, 20, 20, nil)
+	// Items are ordered by priority, then by arrival
+	for i, priority := range []uint64{0, 5, 1, 5, 0} {
+		item := GenerateTestItem(testSponsor, int64(i))
+		item.priority = priority
+		txm.Add(ctx, []*TestItem{item})
+	}
+	expected := []int64{1, 3, 2, 0, 4}
+	popped := make([]*TestItem, 0, len(expected))
+	for _, e := range expected {
+		item, ok := txm.PopNext(ctx)
+		require.True(ok)
+		require.Equal(e, item.Expiry())
+		popped = append(popped, item)
+	}
+	_, ok := txm.PopNext(ctx)
+	require.False(ok)
+
+	// Restored items are ahead of items of equal priority and keep their
+	// order
+	late := GenerateTestItem(testSponsor, 5)
+	txm.Add(ctx, []*TestItem{late})
+	txm.StartStreaming(ctx)
+	txm.FinishStreaming(ctx, []*TestItem{popped[3], popped[4]})
+	for _, e := range []int64{0, 4, 5} {
+		item, ok := txm.PopNext(ctx)
+		require.True(ok)
+		require.Equal(e, item.Expiry())
+	}
+}
+
+func TestMempoolPriorityRemove(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+
+	txm := New[*TestItem](tracer, 20, 20, nil)
+	items := make([]*TestItem, 10)
+	for i := range items {
+		items[i] = GenerateTestItem(testSponsor, int64(i))
+		items[i].priority = uint64(i % 3)
+	}
+	txm.Add(ctx, items)
+	txm.Remove(ctx, []*TestItem{items[8], items[4]})
+	require.Len(txm.SetMinTimestamp(ctx, 2), 2)
+
+	for _, e := range []int64{2, 5, 7, 3, 6, 9} {
+		item, ok := txm.PopNext(ctx)
+		require.True(ok)
+		require.Equal(e, item.Expiry())
+	}
+	require.Zero(txm.Len(ctx))
+	require.Zero(txm.Size(ctx))
+}
+
+func TestMempoolAddEvictLowest(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	sponsor := codec.CreateAddress(4, ids.GenerateTestID())
+
+	txm := New[*TestItem](tracer, 3, 2, nil)
+	add := func(sponsor codec.Address, t int64, priority uint64) *TestItem {
+		item := GenerateTestItem(sponsor, t)
+		item.priority = priority
+		txm.Add(ctx, []*TestItem{item})
+		return item
+	}
+	add(testSponsor, 0, 2)
+	lowest := add(sponsor, 1, 1)
+	add(sponsor, 2, 3)
+
+	// Items that don't pay more than the lowest item are dropped
+	item := add(testSponsor, 3, 1)
+	require.False(txm.Has(ctx, item.ID()))
+
+	// Items that pay more evict the lowest item
+	item = add(testSponsor, 4, 4)
+	require.True(txm.Has(ctx, item.ID()))
+	require.False(txm.Has(ctx, lowest.ID()))
+	require.Equal(3, txm.Len(ctx))
+	require.Equal(6, txm.Size(ctx))
+	require.Equal(2, txm.owned[testSponsor])
+	require.Equal(1, txm.owned[sponsor])
+
+	// Items of sponsors at their limit don't evict anything
+	item = add(testSponsor, 5, 5)
+	require.False(txm.Has(ctx, item.ID()))
+	require.Equal(3, txm.Len(ctx))
+
+	for _, e := range []int64{4, 2, 0} {
+		item, ok := txm.PopNext(ctx)
+		require.True(ok)
+		require.Equal(e, item.Expiry())
+	}
+	require.Zero(txm.Len(ctx))
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package mempool
+
+import (
+	"container/heap"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// entry is an item in the [queue]. It is also tracked by the expiry heap of
+// the [Mempool], so it can be removed when it expires.
+type entry[T Item] struct {
+	item     T
+	priority uint64
+
+	// seq breaks ties between items of equal priority, lowest first
+	seq int64
+
+	// index and reversedIndex are the positions of the entry in the queue
+	// and in the reversed queue
+	index         int
+	reversedIndex int
+}
+
+func (e *entry[T]) ID() ids.ID {
+	return e.item.ID()
+}
+
+func (e *entry[T]) Expiry() int64 {
+	return e.item.Expiry()
+}
+
+// queue orders items by descending priority and, for equal priorities, by
+// ascending sequence number. A reversed queue orders them the other way
+// around, so that its first item is the one to evict.
+//
+// This data structure does not perform any synchronization and is not
+// safe to use concurrently without external locking.
+type queue[T Item] struct {
+	entries  []*entry[T]
+	reversed bool
+}
+
+func newQueue[T Item](items int, reversed bool) *queue[T] {
+	return &queue[T]{entries: make([]*entry[T], 0, items), reversed: reversed}
+}
+
+func (q *queue[T]) setIndex(e *entry[T], i int) {
+	if q.reversed {
+		e.reversedIndex = i
+		return
+	}
+	e.index = i
+}
+
+func (q *queue[T]) indexOf(e *entry[T]) int {
+	if q.reversed {
+		return e.reversedIndex
+	}
+	return e.index
+}
+
+func (q *queue[T]) Len() int { return len(q.entries) }
+
+func (q *queue[T]) Less(i, j int) bool {
+	a, b := q.entries[i], q.entries[j]
+	if q.reversed {
+		a, b = b, a
+	}
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+func (q *queue[T]) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.setIndex(q.entries[i], i)
+	q.setIndex(q.entries[j], j)
+}
+
+// Push is called by [heap], use [queue.push] instead.
+func (q *queue[T]) Push(x any) {
+	e := x.(*entry[T])
+	q.setIndex(e, len(q.entries))
+	q.entries = append(q.entries, e)
+}
+
+// Pop is called by [heap], use [queue.pop] instead.
+func (q *queue[T]) Pop() any {
+	n := len(q.entries)
+	e := q.entries[n-1]
+	q.entries[n-1] = nil // avoid memory leak
+	q.entries = q.entries[:n-1]
+	q.setIndex(e, -1)
+	return e
+}
+
+func (q *queue[T]) push(e *entry[T]) {
+	heap.Push(q, e)
+}
+
+// first returns the entry with the highest priority, or nil if [q] is empty.
+func (q *queue[T]) first() *entry[T] {
+	if len(q.entries) == 0 {
+		return nil
+	}
+	return q.entries[0]
+}
+
+func (q *queue[T]) pop() *entry[T] {
+	if len(q.entries) == 0 {
+		return nil
+	}
+	return heap.Pop(q).(*entry[T])
+}
+
+func (q *queue[T]) remove(e *entry[T]) {
+	heap.Remove(q, q.indexOf(e))
+}
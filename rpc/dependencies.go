@@ -10,6 +10,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/fees"
@@ -27,10 +28,21 @@ type VM interface {
 		verifySig bool,
 		txs []*chain.Transaction,
 	) (errs []error)
+	SimulateTx(context.Context, *chain.Transaction) (*chain.Simulation, error)
 	LastAcceptedBlock() *chain.StatelessBlock
 	UnitPrices(context.Context) (fees.Dimensions, error)
 	CurrentValidators(
 		context.Context,
 	) (map[ids.NodeID]*validators.GetValidatorOutput, map[string]struct{})
 	GetVerifyAuth() bool
+	GetMaxConcurrentSimulations() int
+	GetAcceptedBlockWindow() int
+	GetWarpSignatures(
+		ctx context.Context,
+		msgID ids.ID,
+	) (*warp.UnsignedMessage, []*warp.Validator, []*chain.WarpSignature, error)
+	GetAcceptedBlock(context.Context, ids.ID) (*chain.StatelessBlock, error)
+	GetAcceptedBlockByHeight(context.Context, uint64) (*chain.StatelessBlock, error)
+	GetAcceptedBlockResults(*chain.StatelessBlock) ([]*chain.Result, error)
+	GetTxIDHeight(ids.ID) (uint64, error)
 }
@@ -9,4 +9,6 @@ var (
 	ErrClosed         = errors.New("closed")
 	ErrExpired        = errors.New("expired")
 	ErrMessageMissing = errors.New("message missing")
+	ErrBusy           = errors.New("too many simulations in progress")
+	ErrTxFailed       = errors.New("tx failed")
 )
@@ -10,8 +10,12 @@ import (
 	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/requester"
 	"github.com/ava-labs/hypersdk/utils"
@@ -119,6 +123,41 @@ type Modifier interface {
 	Base(*chain.Base)
 }
 
+// PriorityFee is a [Modifier] paying a priority fee, on top of the max fee,
+// to be included ahead of other transactions during congestion.
+type PriorityFee uint64
+
+func (p PriorityFee) Base(b *chain.Base) {
+	b.PriorityFee = uint64(p)
+	b.MaxFee += uint64(p)
+}
+
+// TxModifier is an optional extension of [Modifier] that changes the
+// transaction before it is signed.
+type TxModifier interface {
+	Modifier
+	Tx(*chain.Transaction)
+}
+
+// WarpMessage is a [Modifier] attaching a signed warp message to the
+// transaction, see [JSONRPCClient.GenerateAggregateWarpSignature].
+type WarpMessage struct {
+	Message *warp.Message
+}
+
+func (WarpMessage) Base(*chain.Base) {}
+
+func (w WarpMessage) Tx(tx *chain.Transaction) {
+	tx.WarpMessage = w.Message
+}
+
+// GenerateTransaction signs [actions] with a max fee covering their units at
+// the current unit prices. Those are the pessimistic [chain.EstimateUnits] or,
+// if [authFactory] is a [chain.UnsignedAuthFactory], the units reserved by a
+// simulation of the transaction before it is signed. Transactions that fail
+// when simulated return an error. If the node cannot simulate the transaction
+// (because it is busy, times out or doesn't support simulations), the estimate
+// is used.
 func (cli *JSONRPCClient) GenerateTransaction(
 	ctx context.Context,
 	parser chain.Parser,
@@ -140,11 +179,176 @@ func (cli *JSONRPCClient) GenerateTransaction(
 	if err != nil {
 		return nil, nil, 0, err
 	}
+	if factory, ok := authFactory.(chain.UnsignedAuthFactory); ok {
+		sim, err := cli.simulateUnsigned(ctx, parser, actions, factory, maxFee, modifiers...)
+		switch {
+		case ctx.Err() != nil:
+			return nil, nil, 0, ctx.Err()
+		case err != nil:
+			// Fall back to the estimate
+		case !sim.Success:
+			return nil, nil, 0, fmt.Errorf("failed to simulate transaction: %w: %s", ErrTxFailed, sim.Error)
+		default:
+			maxFee, err = fees.MulSum(unitPrices, sim.MaxUnits)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+		}
+	}
 	f, tx, err := cli.GenerateTransactionManual(parser, actions, authFactory, maxFee, modifiers...)
 	if err != nil {
 		return nil, nil, 0, err
 	}
-	return f, tx, maxFee, nil
+	return f, tx, tx.Base.MaxFee, nil
+}
+
+// simulateUnsigned simulates the transaction [GenerateTransactionManual] would
+// create, with the unsigned auth of [factory].
+func (cli *JSONRPCClient) simulateUnsigned(
+	ctx context.Context,
+	parser chain.Parser,
+	actions []chain.Action,
+	factory chain.UnsignedAuthFactory,
+	maxFee uint64,
+	modifiers ...Modifier,
+) (*SimulateTxReply, error) {
+	auth, err := factory.Unsigned()
+	if err != nil {
+		return nil, err
+	}
+	tx := newTx(parser, actions, maxFee, modifiers...)
+	tx.Auth = auth
+	p := codec.NewWriter(0, consts.NetworkSizeLimit)
+	if err := tx.Marshal(p); err != nil {
+		return nil, err
+	}
+	return cli.SimulateTx(ctx, p.Bytes())
+}
+
+// SimulateTx executes [tx] on top of the preferred block of the node. The
+// signature of [tx] is not verified.
+func (cli *JSONRPCClient) SimulateTx(ctx context.Context, tx []byte) (*SimulateTxReply, error) {
+	resp := new(SimulateTxReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"simulateTx",
+		&SimulateTxArgs{Tx: tx},
+		resp,
+	)
+	return resp, err
+}
+
+// GetBlock returns the accepted block [blkID] with its transactions and their
+// results.
+func (cli *JSONRPCClient) GetBlock(ctx context.Context, blkID ids.ID) (*GetBlockReply, error) {
+	resp := new(GetBlockReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"getBlock",
+		&GetBlockArgs{BlockID: blkID},
+		resp,
+	)
+	return resp, err
+}
+
+// GetBlockByHeight returns the accepted block at [height] with its
+// transactions and their results.
+func (cli *JSONRPCClient) GetBlockByHeight(ctx context.Context, height uint64) (*GetBlockReply, error) {
+	resp := new(GetBlockReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"getBlockByHeight",
+		&GetBlockByHeightArgs{Height: height},
+		resp,
+	)
+	return resp, err
+}
+
+// GetBlockHeaders returns up to [limit] headers of accepted blocks from
+// [start] on, or from the oldest block retained by the node if it is later.
+// A [limit] of 0 uses the maximum allowed by the node. The heights of the
+// blocks the node is missing in the range are returned alongside.
+func (cli *JSONRPCClient) GetBlockHeaders(ctx context.Context, start uint64, limit int) ([]*BlockHeader, []uint64, error) {
+	resp := new(GetBlockHeadersReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"getBlockHeaders",
+		&GetBlockHeadersArgs{Start: start, Limit: limit},
+		resp,
+	)
+	return resp.Headers, resp.Missing, err
+}
+
+// GetTx returns the accepted transaction [txID] with its result and the block
+// that included it.
+func (cli *JSONRPCClient) GetTx(ctx context.Context, txID ids.ID) (*GetTxReply, error) {
+	resp := new(GetTxReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"getTx",
+		&GetTxArgs{TxID: txID},
+		resp,
+	)
+	return resp, err
+}
+
+// GetWarpSignatures returns the warp message [msgID] sent by an accepted
+// transaction, the canonical validator set of the subnet at the current
+// P-Chain height and the signatures of the message collected by the node.
+func (cli *JSONRPCClient) GetWarpSignatures(
+	ctx context.Context,
+	msgID ids.ID,
+) (*warp.UnsignedMessage, []*warp.Validator, []*chain.WarpSignature, error) {
+	resp := new(GetWarpSignaturesReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"getWarpSignatures",
+		&GetWarpSignaturesArgs{MessageID: msgID},
+		resp,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	msg, err := warp.ParseUnsignedMessage(resp.Message)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	vdrs := make([]*warp.Validator, len(resp.Validators))
+	for i, vdr := range resp.Validators {
+		pk, err := bls.PublicKeyFromCompressedBytes(vdr.PublicKey)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		vdrs[i] = &warp.Validator{
+			PublicKey:      pk,
+			PublicKeyBytes: bls.PublicKeyToUncompressedBytes(pk),
+			Weight:         vdr.Weight,
+			NodeIDs:        vdr.NodeIDs,
+		}
+	}
+	return msg, vdrs, resp.Signatures, nil
+}
+
+// GenerateAggregateWarpSignature aggregates the signatures of the warp message
+// [msgID] collected by the node. It returns the signed message, the weight of
+// its signers and the total weight of the validators.
+func (cli *JSONRPCClient) GenerateAggregateWarpSignature(
+	ctx context.Context,
+	msgID ids.ID,
+) (*warp.Message, uint64, uint64, error) {
+	unsigned, vdrs, signatures, err := cli.GetWarpSignatures(ctx, msgID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	totalWeight, err := warp.SumWeight(vdrs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	msg, weight, err := chain.AggregateWarpSignatures(unsigned, vdrs, signatures)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return msg, weight, totalWeight, nil
 }
 
 func (cli *JSONRPCClient) GenerateTransactionManual(
@@ -154,7 +358,27 @@ func (cli *JSONRPCClient) GenerateTransactionManual(
 	maxFee uint64,
 	modifiers ...Modifier,
 ) (func(context.Context) error, *chain.Transaction, error) {
-	// Construct transaction
+	actionRegistry, authRegistry := parser.Registry()
+	tx, err := newTx(parser, actions, maxFee, modifiers...).Sign(authFactory, actionRegistry, authRegistry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to sign transaction", err)
+	}
+
+	// Return max fee and transaction for issuance
+	return func(ictx context.Context) error {
+		_, err := cli.SubmitTx(ictx, tx.Bytes())
+		return err
+	}, tx, nil
+}
+
+// newTx constructs the unsigned transaction of [actions], expiring after the
+// validity window.
+func newTx(
+	parser chain.Parser,
+	actions []chain.Action,
+	maxFee uint64,
+	modifiers ...Modifier,
+) *chain.Transaction {
 	now := time.Now().UnixMilli()
 	rules := parser.Rules(now)
 	base := &chain.Base{
@@ -169,18 +393,13 @@ func (cli *JSONRPCClient) GenerateTransactionManual(
 	}
 
 	// Build transaction
-	actionRegistry, authRegistry := parser.Registry()
 	tx := chain.NewTx(base, actions)
-	tx, err := tx.Sign(authFactory, actionRegistry, authRegistry)
-	if err != nil {
-		return nil, nil, fmt.Errorf("%w: failed to sign transaction", err)
+	for _, m := range modifiers {
+		if tm, ok := m.(TxModifier); ok {
+			tm.Tx(tx)
+		}
 	}
-
-	// Return max fee and transaction for issuance
-	return func(ictx context.Context) error {
-		_, err := cli.SubmitTx(ictx, tx.Bytes())
-		return err
-	}, tx, nil
+	return tx
 }
 
 func Wait(ctx context.Context, check func(ctx context.Context) (bool, error)) error {
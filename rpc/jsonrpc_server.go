@@ -4,11 +4,14 @@
 package rpc
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
@@ -18,10 +21,16 @@ import (
 
 type JSONRPCServer struct {
 	vm VM
+
+	// simulations holds a slot for every [SimulateTx] in progress
+	simulations chan struct{}
 }
 
 func NewJSONRPCServer(vm VM) *JSONRPCServer {
-	return &JSONRPCServer{vm}
+	return &JSONRPCServer{
+		vm:          vm,
+		simulations: make(chan struct{}, vm.GetMaxConcurrentSimulations()),
+	}
 }
 
 type PingReply struct {
@@ -85,6 +94,77 @@ func (j *JSONRPCServer) SubmitTx(
 	return j.vm.Submit(ctx, false, []*chain.Transaction{tx})[0]
 }
 
+type SimulateTxArgs struct {
+	// Tx is a transaction whose signature is not verified
+	Tx []byte `json:"tx"`
+}
+
+type SimulateTxReply struct {
+	Success bool       `json:"success"`
+	Error   string     `json:"error"`
+	Outputs [][][]byte `json:"outputs"`
+
+	// Units are the units consumed and Fee the fee charged, including
+	// refunds and the priority fee
+	Units fees.Dimensions `json:"units"`
+	Fee   uint64          `json:"fee"`
+
+	// MaxUnits are the units reserved by the transaction, which its max fee
+	// must cover
+	MaxUnits fees.Dimensions `json:"maxUnits"`
+
+	// StateKeys are the declared keys the transaction touched
+	StateKeys []*chain.SimulatedKey `json:"stateKeys"`
+
+	// WarpMessage is the unsigned warp message sent by the transaction, if
+	// any
+	WarpMessage []byte `json:"warpMessage"`
+}
+
+// SimulateTx executes a transaction on top of the preferred block without
+// verifying its signature, to find its outputs and exact fee before signing
+// it. It returns [ErrBusy] if too many simulations are already in progress.
+func (j *JSONRPCServer) SimulateTx(
+	req *http.Request,
+	args *SimulateTxArgs,
+	reply *SimulateTxReply,
+) error {
+	ctx, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.SimulateTx")
+	defer span.End()
+
+	select {
+	case j.simulations <- struct{}{}:
+		defer func() { <-j.simulations }()
+	default:
+		return ErrBusy
+	}
+
+	actionRegistry, authRegistry := j.vm.Registry()
+	rtx := codec.NewReader(args.Tx, consts.NetworkSizeLimit)
+	tx, err := chain.UnmarshalTx(rtx, actionRegistry, authRegistry)
+	if err != nil {
+		return fmt.Errorf("%w: unable to unmarshal on public service", err)
+	}
+	if !rtx.Empty() {
+		return errors.New("tx has extra bytes")
+	}
+	sim, err := j.vm.SimulateTx(ctx, tx)
+	if err != nil {
+		return err
+	}
+	reply.Success = sim.Result.Success
+	reply.Error = string(sim.Result.Error)
+	reply.Outputs = sim.Result.Outputs
+	reply.Units = sim.Result.Units
+	reply.Fee = sim.Result.Fee
+	reply.MaxUnits = sim.MaxUnits
+	reply.StateKeys = sim.StateKeys
+	if sim.Result.WarpMessage != nil {
+		reply.WarpMessage = sim.Result.WarpMessage.Bytes()
+	}
+	return nil
+}
+
 type LastAcceptedReply struct {
 	Height    uint64 `json:"height"`
 	BlockID   ids.ID `json:"blockId"`
@@ -118,3 +198,330 @@ func (j *JSONRPCServer) UnitPrices(
 	reply.UnitPrices = unitPrices
 	return nil
 }
+
+type GetWarpSignaturesArgs struct {
+	MessageID ids.ID `json:"messageId"`
+}
+
+type WarpValidator struct {
+	PublicKey []byte       `json:"publicKey"` // compressed
+	Weight    uint64       `json:"weight"`
+	NodeIDs   []ids.NodeID `json:"nodeIds"`
+}
+
+type GetWarpSignaturesReply struct {
+	Message []byte `json:"message"`
+
+	// Validators is the canonical validator set of the subnet at the current
+	// P-Chain height
+	Validators []*WarpValidator       `json:"validators"`
+	Signatures []*chain.WarpSignature `json:"signatures"`
+}
+
+// GetWarpSignatures returns the signatures collected by the node for a warp
+// message sent by an accepted transaction.
+func (j *JSONRPCServer) GetWarpSignatures(
+	req *http.Request,
+	args *GetWarpSignaturesArgs,
+	reply *GetWarpSignaturesReply,
+) error {
+	ctx, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.GetWarpSignatures")
+	defer span.End()
+
+	msg, vdrs, signatures, err := j.vm.GetWarpSignatures(ctx, args.MessageID)
+	if err != nil {
+		return err
+	}
+	reply.Message = msg.Bytes()
+	reply.Validators = make([]*WarpValidator, len(vdrs))
+	for i, vdr := range vdrs {
+		reply.Validators[i] = &WarpValidator{
+			PublicKey: bls.PublicKeyToCompressedBytes(vdr.PublicKey),
+			Weight:    vdr.Weight,
+			NodeIDs:   vdr.NodeIDs,
+		}
+	}
+	reply.Signatures = signatures
+	return nil
+}
+
+// maxBlockHeadersLimit bounds the headers returned by a single
+// [JSONRPCServer.GetBlockHeaders] call.
+const maxBlockHeadersLimit = 100
+
+type BlockHeader struct {
+	ID        ids.ID `json:"id"`
+	Parent    ids.ID `json:"parent"`
+	Height    uint64 `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	StateRoot ids.ID `json:"stateRoot"`
+	Txs       int    `json:"txs"`
+	Size      int    `json:"size"`
+}
+
+type ActionInfo struct {
+	TypeID uint8           `json:"typeId"`
+	Action json.RawMessage `json:"action"`
+
+	// Outputs are empty if the transaction failed
+	Outputs [][]byte `json:"outputs"`
+}
+
+type TxResultInfo struct {
+	Success bool            `json:"success"`
+	Error   string          `json:"error"`
+	Units   fees.Dimensions `json:"units"`
+	Fee     uint64          `json:"fee"`
+
+	// WarpMessage is the unsigned warp message sent by the transaction, if
+	// any
+	WarpMessage []byte `json:"warpMessage"`
+}
+
+type TxInfo struct {
+	ID      ids.ID        `json:"id"`
+	Base    *chain.Base   `json:"base"`
+	Actions []*ActionInfo `json:"actions"`
+
+	AuthTypeID uint8         `json:"authTypeId"`
+	Actor      codec.Address `json:"actor"`
+	Sponsor    codec.Address `json:"sponsor"`
+
+	// WarpMessage is the signed warp message carried by the transaction, if
+	// any
+	WarpMessage []byte `json:"warpMessage"`
+
+	// Bytes can be parsed with the registries of the chain, see [TxInfo.Parse]
+	Bytes []byte `json:"bytes"`
+
+	// Result is null if the node accepted the block without executing it
+	// (during state sync)
+	Result *TxResultInfo `json:"result"`
+}
+
+// Parse decodes the transaction with the registries of [parser].
+func (t *TxInfo) Parse(parser chain.Parser) (*chain.Transaction, error) {
+	actionRegistry, authRegistry := parser.Registry()
+	p := codec.NewReader(t.Bytes, consts.NetworkSizeLimit)
+	tx, err := chain.UnmarshalTx(p, actionRegistry, authRegistry)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Empty() {
+		return nil, errors.New("tx has extra bytes")
+	}
+	return tx, nil
+}
+
+func newBlockHeader(blk *chain.StatelessBlock) *BlockHeader {
+	return &BlockHeader{
+		ID:        blk.ID(),
+		Parent:    blk.Prnt,
+		Height:    blk.Hght,
+		Timestamp: blk.Tmstmp,
+		StateRoot: blk.StateRoot,
+		Txs:       len(blk.Txs),
+		Size:      len(blk.Bytes()),
+	}
+}
+
+func newTxInfo(tx *chain.Transaction, result *chain.Result) (*TxInfo, error) {
+	info := &TxInfo{
+		ID:         tx.ID(),
+		Base:       tx.Base,
+		Actions:    make([]*ActionInfo, len(tx.Actions)),
+		AuthTypeID: tx.Auth.GetTypeID(),
+		Actor:      tx.Auth.Actor(),
+		Sponsor:    tx.Auth.Sponsor(),
+		Bytes:      tx.Bytes(),
+	}
+	if tx.WarpMessage != nil {
+		info.WarpMessage = tx.WarpMessage.Bytes()
+	}
+	for i, action := range tx.Actions {
+		actionJSON, err := json.Marshal(action)
+		if err != nil {
+			return nil, err
+		}
+		info.Actions[i] = &ActionInfo{
+			TypeID: action.GetTypeID(),
+			Action: actionJSON,
+		}
+		if result != nil && i < len(result.Outputs) {
+			info.Actions[i].Outputs = result.Outputs[i]
+		}
+	}
+	if result != nil {
+		info.Result = &TxResultInfo{
+			Success: result.Success,
+			Error:   string(result.Error),
+			Units:   result.Units,
+			Fee:     result.Fee,
+		}
+		if result.WarpMessage != nil {
+			info.Result.WarpMessage = result.WarpMessage.Bytes()
+		}
+	}
+	return info, nil
+}
+
+type GetBlockArgs struct {
+	BlockID ids.ID `json:"blockId"`
+}
+
+type GetBlockByHeightArgs struct {
+	Height uint64 `json:"height"`
+}
+
+type GetBlockReply struct {
+	Header       *BlockHeader `json:"header"`
+	Transactions []*TxInfo    `json:"transactions"`
+}
+
+func (j *JSONRPCServer) newGetBlockReply(blk *chain.StatelessBlock, reply *GetBlockReply) error {
+	results, err := j.vm.GetAcceptedBlockResults(blk)
+	if err != nil {
+		return err
+	}
+	reply.Header = newBlockHeader(blk)
+	reply.Transactions = make([]*TxInfo, len(blk.Txs))
+	for i, tx := range blk.Txs {
+		var result *chain.Result
+		if results != nil {
+			result = results[i]
+		}
+		info, err := newTxInfo(tx, result)
+		if err != nil {
+			return err
+		}
+		reply.Transactions[i] = info
+	}
+	return nil
+}
+
+// GetBlock returns the accepted block [BlockID] with its transactions and
+// their results. Only blocks within the accepted block window are available.
+func (j *JSONRPCServer) GetBlock(req *http.Request, args *GetBlockArgs, reply *GetBlockReply) error {
+	ctx, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.GetBlock")
+	defer span.End()
+
+	blk, err := j.vm.GetAcceptedBlock(ctx, args.BlockID)
+	if err != nil {
+		return err
+	}
+	return j.newGetBlockReply(blk, reply)
+}
+
+// GetBlockByHeight is [JSONRPCServer.GetBlock] for the accepted block at
+// [Height].
+func (j *JSONRPCServer) GetBlockByHeight(req *http.Request, args *GetBlockByHeightArgs, reply *GetBlockReply) error {
+	ctx, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.GetBlockByHeight")
+	defer span.End()
+
+	blk, err := j.vm.GetAcceptedBlockByHeight(ctx, args.Height)
+	if err != nil {
+		return err
+	}
+	return j.newGetBlockReply(blk, reply)
+}
+
+type GetBlockHeadersArgs struct {
+	// Start is raised to the oldest block the node retains
+	Start uint64 `json:"start"`
+
+	// Limit defaults to, and is capped at, [maxBlockHeadersLimit]
+	Limit int `json:"limit"`
+}
+
+type GetBlockHeadersReply struct {
+	// Headers stop at the last accepted block
+	Headers []*BlockHeader `json:"headers"`
+
+	// Missing are the heights in the range whose blocks the node does not have,
+	// for example because they were accepted while it state synced.
+	Missing []uint64 `json:"missing"`
+}
+
+// GetBlockHeaders returns the headers of the accepted blocks from height
+// [Start] on, in height order. Blocks that left the accepted block window have
+// been deleted, so older heights are skipped. Blocks missing within the window
+// are skipped and reported in [GetBlockHeadersReply.Missing].
+func (j *JSONRPCServer) GetBlockHeaders(req *http.Request, args *GetBlockHeadersArgs, reply *GetBlockHeadersReply) error {
+	ctx, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.GetBlockHeaders")
+	defer span.End()
+
+	limit := args.Limit
+	if limit <= 0 || limit > maxBlockHeadersLimit {
+		limit = maxBlockHeadersLimit
+	}
+	last := j.vm.LastAcceptedBlock().Hght
+	start := args.Start
+	if window := uint64(j.vm.GetAcceptedBlockWindow()); last > window {
+		start = max(start, last-window+1)
+	}
+	reply.Headers = make([]*BlockHeader, 0, limit)
+	reply.Missing = []uint64{}
+	for height := start; height <= last && len(reply.Headers)+len(reply.Missing) < limit; height++ {
+		blk, err := j.vm.GetAcceptedBlockByHeight(ctx, height)
+		if errors.Is(err, database.ErrNotFound) {
+			reply.Missing = append(reply.Missing, height)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%w: height %d", err, height)
+		}
+		reply.Headers = append(reply.Headers, newBlockHeader(blk))
+	}
+	return nil
+}
+
+type GetTxArgs struct {
+	TxID ids.ID `json:"txId"`
+}
+
+type GetTxReply struct {
+	BlockID   ids.ID  `json:"blockId"`
+	Height    uint64  `json:"height"`
+	Timestamp int64   `json:"timestamp"`
+	Tx        *TxInfo `json:"tx"`
+}
+
+// GetTx returns the accepted transaction [TxID] with its result. Only
+// transactions in blocks within the accepted block window are available.
+func (j *JSONRPCServer) GetTx(req *http.Request, args *GetTxArgs, reply *GetTxReply) error {
+	ctx, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.GetTx")
+	defer span.End()
+
+	height, err := j.vm.GetTxIDHeight(args.TxID)
+	if err != nil {
+		return err
+	}
+	blk, err := j.vm.GetAcceptedBlockByHeight(ctx, height)
+	if err != nil {
+		return err
+	}
+	results, err := j.vm.GetAcceptedBlockResults(blk)
+	if err != nil {
+		return err
+	}
+	for i, tx := range blk.Txs {
+		if tx.ID() != args.TxID {
+			continue
+		}
+		var result *chain.Result
+		if results != nil {
+			result = results[i]
+		}
+		info, err := newTxInfo(tx, result)
+		if err != nil {
+			return err
+		}
+		reply.BlockID = blk.ID()
+		reply.Height = blk.Hght
+		reply.Timestamp = blk.Tmstmp
+		reply.Tx = info
+		return nil
+	}
+	// Should never happen, the index is written with the block
+	return database.ErrNotFound
+}
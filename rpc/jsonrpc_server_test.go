@@ -0,0 +1,171 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// testVM serves the blocks accepted up to [last], of which only those in
+// [blocks] are still stored.
+type testVM struct {
+	VM
+
+	window int
+	last   uint64
+	blocks map[uint64]*chain.StatelessBlock
+	txs    map[ids.ID]uint64
+}
+
+func newTestVM(window int, last uint64, stored ...uint64) *testVM {
+	vm := &testVM{
+		window: window,
+		last:   last,
+		blocks: map[uint64]*chain.StatelessBlock{},
+		txs:    map[ids.ID]uint64{},
+	}
+	for _, height := range stored {
+		vm.blocks[height] = &chain.StatelessBlock{
+			StatefulBlock: &chain.StatefulBlock{Hght: height, Tmstmp: int64(height)},
+		}
+	}
+	return vm
+}
+
+func (*testVM) Tracer() trace.Tracer {
+	return trace.Noop
+}
+
+func (vm *testVM) LastAcceptedBlock() *chain.StatelessBlock {
+	return &chain.StatelessBlock{StatefulBlock: &chain.StatefulBlock{Hght: vm.last}}
+}
+
+func (vm *testVM) GetAcceptedBlockWindow() int {
+	return vm.window
+}
+
+func (vm *testVM) GetAcceptedBlockByHeight(_ context.Context, height uint64) (*chain.StatelessBlock, error) {
+	blk, ok := vm.blocks[height]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return blk, nil
+}
+
+func (*testVM) GetAcceptedBlockResults(*chain.StatelessBlock) ([]*chain.Result, error) {
+	return nil, nil
+}
+
+func (vm *testVM) GetTxIDHeight(txID ids.ID) (uint64, error) {
+	height, ok := vm.txs[txID]
+	if !ok {
+		return 0, database.ErrNotFound
+	}
+	return height, nil
+}
+
+func newTestRequest() *http.Request {
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", nil)
+	return req
+}
+
+func TestGetBlockByHeight(t *testing.T) {
+	require := require.New(t)
+
+	// Blocks up to height 5 left the window of 5 blocks
+	server := &JSONRPCServer{vm: newTestVM(5, 10, 6, 7, 8, 9, 10)}
+
+	reply := new(GetBlockReply)
+	require.NoError(server.GetBlockByHeight(newTestRequest(), &GetBlockByHeightArgs{Height: 6}, reply))
+	require.Equal(uint64(6), reply.Header.Height)
+	require.Equal(int64(6), reply.Header.Timestamp)
+	require.Empty(reply.Transactions)
+
+	for _, height := range []uint64{5, 11} {
+		err := server.GetBlockByHeight(newTestRequest(), &GetBlockByHeightArgs{Height: height}, new(GetBlockReply))
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+}
+
+func TestGetBlockHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    uint64
+		limit    int
+		stored   []uint64
+		expected []uint64
+		missing  []uint64
+	}{
+		{
+			name:     "start before the pruning boundary",
+			start:    0,
+			stored:   []uint64{6, 7, 8, 9, 10},
+			expected: []uint64{6, 7, 8, 9, 10},
+			missing:  []uint64{},
+		},
+		{
+			name:     "start after the pruning boundary",
+			start:    8,
+			stored:   []uint64{6, 7, 8, 9, 10},
+			expected: []uint64{8, 9, 10},
+			missing:  []uint64{},
+		},
+		{
+			name:     "missing block in the window",
+			start:    0,
+			stored:   []uint64{6, 7, 9, 10},
+			expected: []uint64{6, 7, 9, 10},
+			missing:  []uint64{8},
+		},
+		{
+			name:     "limit counts missing blocks",
+			start:    0,
+			limit:    3,
+			stored:   []uint64{6, 7, 9, 10},
+			expected: []uint64{6, 7},
+			missing:  []uint64{8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			server := &JSONRPCServer{vm: newTestVM(5, 10, tt.stored...)}
+			reply := new(GetBlockHeadersReply)
+			require.NoError(server.GetBlockHeaders(newTestRequest(), &GetBlockHeadersArgs{Start: tt.start, Limit: tt.limit}, reply))
+			heights := make([]uint64, len(reply.Headers))
+			for i, header := range reply.Headers {
+				heights[i] = header.Height
+			}
+			require.Equal(tt.expected, heights)
+			require.Equal(tt.missing, reply.Missing)
+		})
+	}
+}
+
+func TestGetTxPruned(t *testing.T) {
+	require := require.New(t)
+
+	vm := newTestVM(5, 10, 6, 7, 8, 9, 10)
+	server := &JSONRPCServer{vm: vm}
+
+	// Transactions of pruned blocks are removed from the index
+	err := server.GetTx(newTestRequest(), &GetTxArgs{TxID: ids.GenerateTestID()}, new(GetTxReply))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// So are their blocks, if the index was not pruned yet
+	txID := ids.GenerateTestID()
+	vm.txs[txID] = 5
+	err = server.GetTx(newTestRequest(), &GetTxArgs{TxID: txID}, new(GetTxReply))
+	require.ErrorIs(err, database.ErrNotFound)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/pubsub"
 	"github.com/ava-labs/hypersdk/utils"
@@ -30,6 +31,7 @@ type WebSocketClient struct {
 
 	pendingBlocks chan []byte
 	pendingTxs    chan []byte
+	pendingEvents chan []byte
 
 	startedClose bool
 	closed       bool
@@ -64,6 +66,7 @@ func NewWebSocketClient(uri string, handshakeTimeout time.Duration, pending int,
 		writeStopped:  make(chan struct{}),
 		pendingBlocks: make(chan []byte, pending),
 		pendingTxs:    make(chan []byte, pending),
+		pendingEvents: make(chan []byte, pending),
 	}
 	go func() {
 		defer close(wc.readStopped)
@@ -91,6 +94,8 @@ func NewWebSocketClient(uri string, handshakeTimeout time.Duration, pending int,
 					wc.pendingBlocks <- tmsg
 				case TxMode:
 					wc.pendingTxs <- tmsg
+				case EventMode:
+					wc.pendingEvents <- tmsg
 				default:
 					utils.Outf("{{orange}}unexpected message mode:{{/}} %x\n", msg[0])
 					continue
@@ -178,6 +183,32 @@ func (c *WebSocketClient) ListenTx(ctx context.Context) (ids.ID, error, *chain.R
 	}
 }
 
+// RegisterEvents subscribes to the events emitted by [emitter] with [topic]. If
+// [topic] is empty, events of every topic are streamed.
+func (c *WebSocketClient) RegisterEvents(emitter codec.Address, topic string) error {
+	if c.closed {
+		return ErrClosed
+	}
+	msg, err := PackEventsRequest(emitter, topic)
+	if err != nil {
+		return err
+	}
+	return c.mb.Send(append([]byte{EventMode}, msg...))
+}
+
+// ListenEvent listens for events from the streaming server. Events are only
+// streamed once the transaction that emitted them is accepted.
+func (c *WebSocketClient) ListenEvent(ctx context.Context) (ids.ID, *chain.Event, error) {
+	select {
+	case msg := <-c.pendingEvents:
+		return UnpackEventMessage(msg)
+	case <-c.readStopped:
+		return ids.Empty, nil, c.err
+	case <-ctx.Done():
+		return ids.Empty, nil, ctx.Err()
+	}
+}
+
 // Close closes [c]'s connection to the decision rpc server.
 func (c *WebSocketClient) Close() error {
 	var err error
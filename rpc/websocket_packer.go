@@ -17,6 +17,7 @@ import (
 const (
 	BlockMode byte = 0
 	TxMode    byte = 1
+	EventMode byte = 2
 )
 
 func PackBlockMessage(b *chain.StatelessBlock) ([]byte, error) {
@@ -106,3 +107,49 @@ func UnpackTxMessage(msg []byte) (ids.ID, error, *chain.Result, error) {
 	}
 	return txID, nil, result, p.Err()
 }
+
+// PackEventsRequest packs a subscription to the events of [emitter]. If
+// [topic] is empty, events of every topic are streamed.
+func PackEventsRequest(emitter codec.Address, topic string) ([]byte, error) {
+	size := codec.AddressLen + codec.StringLen(topic)
+	p := codec.NewWriter(size, consts.NetworkSizeLimit)
+	p.PackAddress(emitter)
+	p.PackString(topic)
+	return p.Bytes(), p.Err()
+}
+
+func UnpackEventsRequest(msg []byte) (codec.Address, string, error) {
+	p := codec.NewReader(msg, consts.NetworkSizeLimit)
+	var emitter codec.Address
+	p.UnpackAddress(&emitter)
+	topic := p.UnpackString(false)
+	if !p.Empty() {
+		return codec.EmptyAddress, "", chain.ErrInvalidObject
+	}
+	return emitter, topic, p.Err()
+}
+
+// Packs an event emitted by an accepted transaction
+func PackEventMessage(txID ids.ID, event *chain.Event) ([]byte, error) {
+	size := ids.IDLen + event.Size()
+	p := codec.NewWriter(size, consts.MaxInt)
+	p.PackID(txID)
+	event.Marshal(p)
+	return p.Bytes(), p.Err()
+}
+
+// Unpacks an event message from [msg]. Returns the ID of the transaction
+// that emitted the event and the event.
+func UnpackEventMessage(msg []byte) (ids.ID, *chain.Event, error) {
+	p := codec.NewReader(msg, consts.MaxInt)
+	var txID ids.ID
+	p.UnpackID(true, &txID)
+	event, err := chain.UnmarshalEvent(p)
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	if !p.Empty() {
+		return ids.Empty, nil, chain.ErrInvalidObject
+	}
+	return txID, event, p.Err()
+}
@@ -27,6 +27,16 @@ type WebSocketServer struct {
 	txL         sync.Mutex
 	txListeners map[ids.ID]*pubsub.Connections
 	expiringTxs *emap.EMap[*chain.Transaction] // ensures all tx listeners are eventually responded to
+
+	eventL         sync.Mutex
+	eventListeners map[eventFilter]*pubsub.Connections
+}
+
+// eventFilter selects the events of [emitter] with [topic]. An empty [topic]
+// matches every topic.
+type eventFilter struct {
+	emitter codec.Address
+	topic   string
 }
 
 func NewWebSocketServer(vm VM, maxPendingMessages int) (*WebSocketServer, *pubsub.Server) {
@@ -35,6 +45,7 @@ func NewWebSocketServer(vm VM, maxPendingMessages int) (*WebSocketServer, *pubsu
 		blockListeners: pubsub.NewConnections(),
 		txListeners:    map[ids.ID]*pubsub.Connections{},
 		expiringTxs:    emap.NewEMap[*chain.Transaction](),
+		eventListeners: map[eventFilter]*pubsub.Connections{},
 	}
 	cfg := pubsub.NewDefaultServerConfig()
 	cfg.MaxPendingMessages = maxPendingMessages
@@ -57,6 +68,19 @@ func (w *WebSocketServer) AddTxListener(tx *chain.Transaction, c *pubsub.Connect
 	w.expiringTxs.Add([]*chain.Transaction{tx})
 }
 
+// Note: event listeners are removed once their connection is closed.
+func (w *WebSocketServer) AddEventListener(emitter codec.Address, topic string, c *pubsub.Connection) {
+	w.eventL.Lock()
+	defer w.eventL.Unlock()
+
+	// TODO: limit max number of event listeners a single connection can create
+	filter := eventFilter{emitter, topic}
+	if _, ok := w.eventListeners[filter]; !ok {
+		w.eventListeners[filter] = pubsub.NewConnections()
+	}
+	w.eventListeners[filter].Add(c)
+}
+
 // If never possible for a tx to enter mempool, call this
 func (w *WebSocketServer) RemoveTx(txID ids.ID, err error) error {
 	w.txL.Lock()
@@ -108,6 +132,10 @@ func (w *WebSocketServer) AcceptBlock(b *chain.StatelessBlock) error {
 		}
 	}
 
+	if err := w.acceptEvents(b); err != nil {
+		return err
+	}
+
 	w.txL.Lock()
 	defer w.txL.Unlock()
 	results := b.Results()
@@ -129,6 +157,79 @@ func (w *WebSocketServer) AcceptBlock(b *chain.StatelessBlock) error {
 	return nil
 }
 
+// acceptEvents publishes the events emitted by the successful transactions of
+// [b] to the listeners of their emitter and topic.
+func (w *WebSocketServer) acceptEvents(b *chain.StatelessBlock) error {
+	w.eventL.Lock()
+	defer w.eventL.Unlock()
+
+	if len(w.eventListeners) == 0 {
+		return nil
+	}
+	results := b.Results()
+	for i, tx := range b.Txs {
+		result := results[i]
+		if !result.Success {
+			continue
+		}
+		for j, action := range tx.Actions {
+			eventAction, ok := action.(chain.EventAction)
+			if !ok {
+				continue
+			}
+			events, err := eventAction.Events(result.Outputs[j])
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				if err := w.publishEvent(tx.ID(), event); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (w *WebSocketServer) publishEvent(txID ids.ID, event *chain.Event) error {
+	filters := []eventFilter{{event.Emitter, ""}}
+	if len(event.Topic) > 0 {
+		filters = append(filters, eventFilter{event.Emitter, event.Topic})
+	}
+
+	// A connection listening to both filters only receives the event once
+	listeners := pubsub.NewConnections()
+	for _, filter := range filters {
+		if conns, ok := w.eventListeners[filter]; ok {
+			for _, conn := range conns.Conns() {
+				listeners.Add(conn)
+			}
+		}
+	}
+	if listeners.Len() == 0 {
+		return nil
+	}
+
+	bytes, err := PackEventMessage(txID, event)
+	if err != nil {
+		return err
+	}
+	inactiveConnection := w.s.Publish(append([]byte{EventMode}, bytes...), listeners)
+	for _, filter := range filters {
+		conns, ok := w.eventListeners[filter]
+		if !ok {
+			continue
+		}
+		for _, conn := range inactiveConnection {
+			conns.Remove(conn)
+		}
+		if conns.Len() == 0 {
+			delete(w.eventListeners, filter)
+		}
+	}
+	return nil
+}
+
 func (w *WebSocketServer) MessageCallback(vm VM) pubsub.Callback {
 	// Assumes controller is initialized before this is called
 	var (
@@ -194,6 +295,17 @@ func (w *WebSocketServer) MessageCallback(vm VM) pubsub.Callback {
 				return
 			}
 			log.Debug("submitted tx", zap.Stringer("id", txID))
+		case EventMode:
+			emitter, topic, err := UnpackEventsRequest(msgBytes[1:])
+			if err != nil {
+				log.Error("failed to unmarshal events request",
+					zap.Int("len", len(msgBytes)),
+					zap.Error(err),
+				)
+				return
+			}
+			w.AddEventListener(emitter, topic, c)
+			log.Debug("added event listener", zap.String("topic", topic))
 		default:
 			log.Error("unexpected message type",
 				zap.Int("len", len(msgBytes)),
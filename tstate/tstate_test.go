@@ -11,6 +11,7 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 	"github.com/stretchr/testify/require"
@@ -911,3 +912,24 @@ func TestInsertAllocate(t *testing.T) {
 		})
 	}
 }
+
+func TestTouchedKeys(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	scope := state.Keys{key1str: state.All, key2str: state.All, key3str: state.All}
+	tsv := New(10).NewView(scope, map[string][]byte{key1str: testVal})
+	require.Nil(tsv.TouchedKeys())
+	tsv.TrackTouchedKeys()
+
+	// Rolled back operations still touch their keys
+	_, err := tsv.GetValue(ctx, key1)
+	require.NoError(err)
+	require.NoError(tsv.Insert(ctx, key2, testVal))
+	tsv.Rollback(ctx, 0)
+	require.Equal(set.Of(key1str, key2str), tsv.TouchedKeys())
+
+	// Invalid operations don't
+	require.ErrorIs(tsv.Insert(ctx, testKey, testVal), ErrInvalidKeyOrPermission)
+	require.Equal(set.Of(key1str, key2str), tsv.TouchedKeys())
+}
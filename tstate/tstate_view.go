@@ -9,6 +9,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
 
 	"github.com/ava-labs/hypersdk/keys"
 	"github.com/ava-labs/hypersdk/state"
@@ -50,6 +51,9 @@ type TStateView struct {
 	// Store which keys are modified and how large their values were.
 	allocates map[string]uint16
 	writes    map[string]uint16
+
+	// touched holds the keys accessed, if tracked (see [TrackTouchedKeys])
+	touched set.Set[string]
 }
 
 func (ts *TState) NewView(scope state.Keys, storage map[string][]byte) *TStateView {
@@ -137,12 +141,28 @@ func (ts *TStateView) OpIndex() int {
 // If an operation is performed more than once during this time, the largest
 // operation will be returned here (if 1 chunk then 2 chunks are written to a key,
 // this function will return 2 chunks).
-//
-// TODO: this function is no longer used but could be a useful metric
 func (ts *TStateView) KeyOperations() (map[string]uint16, map[string]uint16) {
 	return ts.allocates, ts.writes
 }
 
+// TrackTouchedKeys records the keys read, written or removed from now on,
+// including by operations that are rolled back, see [TouchedKeys]. It is not
+// needed to execute blocks.
+func (ts *TStateView) TrackTouchedKeys() {
+	ts.touched = set.Set[string]{}
+}
+
+// TouchedKeys returns the keys accessed since [TrackTouchedKeys] was called.
+func (ts *TStateView) TouchedKeys() set.Set[string] {
+	return ts.touched
+}
+
+func (ts *TStateView) touch(k string) {
+	if ts.touched != nil {
+		ts.touched.Add(k)
+	}
+}
+
 // checkScope returns whether [k] is in scope and has appropriate permissions.
 func (ts *TStateView) checkScope(_ context.Context, k []byte, perm state.Permissions) bool {
 	return ts.scope[string(k)].Has(perm)
@@ -157,6 +177,7 @@ func (ts *TStateView) GetValue(ctx context.Context, key []byte) ([]byte, error)
 		return nil, ErrInvalidKeyOrPermission
 	}
 	k := string(key)
+	ts.touch(k)
 	v, exists := ts.getValue(ctx, k)
 	if !exists {
 		return nil, database.ErrNotFound
@@ -204,6 +225,7 @@ func (ts *TStateView) Insert(ctx context.Context, key []byte, value []byte) erro
 	}
 	valueChunks, _ := keys.NumChunks(value) // not possible to fail
 	k := string(key)
+	ts.touch(k)
 	// Invariant: [getValue] is safe to call here because with [state.Write], it
 	// will provide Read and Write access to the state
 	past, exists := ts.getValue(ctx, k)
@@ -251,6 +273,7 @@ func (ts *TStateView) Remove(ctx context.Context, key []byte) error {
 		return ErrInvalidKeyOrPermission
 	}
 	k := string(key)
+	ts.touch(k)
 	past, exists := ts.getValue(ctx, k)
 	if !exists {
 		// We do not update writes if the key does not exist.
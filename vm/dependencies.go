@@ -54,6 +54,8 @@ type Config interface {
 	GetProcessingBuildSkip() int
 	GetTargetGossipDuration() time.Duration
 	GetBlockCompactionFrequency() int
+	GetSimulationTimeout() time.Duration // how long a simulated transaction may run
+	GetMaxConcurrentSimulations() int    // how many transactions may be simulated at once
 }
 
 type Genesis interface {
@@ -37,6 +37,7 @@ type Metrics struct {
 	emptyBlockBuilt          prometheus.Counter
 	clearedMempool           prometheus.Counter
 	deletedBlocks            prometheus.Counter
+	failedWarpMessages       prometheus.Counter
 	blocksFromDisk           prometheus.Counter
 	blocksHeightsFromDisk    prometheus.Counter
 	executorBuildBlocked     prometheus.Counter
@@ -199,6 +200,11 @@ func newMetrics() (*prometheus.Registry, *Metrics, error) {
 			Name:      "deleted_blocks",
 			Help:      "number of blocks deleted",
 		}),
+		failedWarpMessages: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "failed_warp_messages",
+			Help:      "number of accepted warp messages that could not be signed or stored",
+		}),
 		blocksFromDisk: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: "vm",
 			Name:      "blocks_from_disk",
@@ -286,6 +292,7 @@ func newMetrics() (*prometheus.Registry, *Metrics, error) {
 		r.Register(m.emptyBlockBuilt),
 		r.Register(m.clearedMempool),
 		r.Register(m.deletedBlocks),
+		r.Register(m.failedWarpMessages),
 		r.Register(m.blocksFromDisk),
 		r.Register(m.blocksHeightsFromDisk),
 		r.Register(m.executorBuildBlocked),
@@ -26,11 +26,12 @@ import (
 )
 
 var (
-	_ chain.VM              = (*VM)(nil)
-	_ gossiper.VM           = (*VM)(nil)
-	_ builder.VM            = (*VM)(nil)
-	_ block.ChainVM         = (*VM)(nil)
-	_ block.StateSyncableVM = (*VM)(nil)
+	_ chain.VM                           = (*VM)(nil)
+	_ gossiper.VM                        = (*VM)(nil)
+	_ builder.VM                         = (*VM)(nil)
+	_ block.ChainVM                      = (*VM)(nil)
+	_ block.BuildBlockWithContextChainVM = (*VM)(nil)
+	_ block.StateSyncableVM              = (*VM)(nil)
 )
 
 func (vm *VM) ChainID() ids.ID {
@@ -174,6 +175,21 @@ func (vm *VM) processAcceptedBlock(b *chain.StatelessBlock) {
 		vm.Fatal("accepted processing failed", zap.Error(err))
 	}
 
+	// Sign outgoing warp messages and request the signatures of the other
+	// validators
+	for _, result := range b.Results() {
+		if result.WarpMessage == nil {
+			continue
+		}
+		if err := vm.warpManager.Accepted(context.TODO(), result.WarpMessage); err != nil {
+			vm.metrics.failedWarpMessages.Inc()
+			vm.Logger().Error("unable to process warp message",
+				zap.Stringer("msgID", result.WarpMessage.ID()),
+				zap.Error(err),
+			)
+		}
+	}
+
 	// TODO: consider removing this (unused and requires an extra iteration)
 	for _, tx := range b.Txs {
 		// Only cache auth for accepted blocks to prevent cache manipulation from RPC submissions
@@ -410,6 +426,14 @@ func (vm *VM) GetTargetGossipDuration() time.Duration {
 	return vm.config.GetTargetGossipDuration()
 }
 
+func (vm *VM) GetMaxConcurrentSimulations() int {
+	return vm.config.GetMaxConcurrentSimulations()
+}
+
+func (vm *VM) GetAcceptedBlockWindow() int {
+	return vm.config.GetAcceptedBlockWindow()
+}
+
 func (vm *VM) RecordEmptyBlockBuilt() {
 	vm.metrics.emptyBlockBuilt.Inc()
 }
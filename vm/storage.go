@@ -9,11 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 	"time"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"go.uber.org/zap"
 
 	"github.com/ava-labs/hypersdk/chain"
@@ -33,6 +36,12 @@ const (
 	blockPrefix         = 0x0 // TODO: move to flat files (https://github.com/ava-labs/hypersdk/issues/553)
 	blockIDHeightPrefix = 0x1 // ID -> Height
 	blockHeightIDPrefix = 0x2 // Height -> ID (don't always need full block from disk)
+	warpMessagePrefix   = 0x3 // ID -> Unsigned warp message
+	warpSignaturePrefix = 0x4 // ID + PublicKey -> Signature
+	blockResultsPrefix  = 0x5 // Height -> Results
+	txIDHeightPrefix    = 0x6 // TxID -> Height
+	blockWarpIDsPrefix  = 0x7 // Height -> Warp message IDs
+	blockTxIDsPrefix    = 0x8 // Height -> TxIDs
 )
 
 var (
@@ -61,6 +70,49 @@ func PrefixBlockHeightIDKey(height uint64) []byte {
 	return k
 }
 
+func PrefixBlockResultsKey(height uint64) []byte {
+	k := make([]byte, 1+consts.Uint64Len)
+	k[0] = blockResultsPrefix
+	binary.BigEndian.PutUint64(k[1:], height)
+	return k
+}
+
+func PrefixTxIDHeightKey(id ids.ID) []byte {
+	k := make([]byte, 1+ids.IDLen)
+	k[0] = txIDHeightPrefix
+	copy(k[1:], id[:])
+	return k
+}
+
+func PrefixBlockWarpIDsKey(height uint64) []byte {
+	k := make([]byte, 1+consts.Uint64Len)
+	k[0] = blockWarpIDsPrefix
+	binary.BigEndian.PutUint64(k[1:], height)
+	return k
+}
+
+func PrefixBlockTxIDsKey(height uint64) []byte {
+	k := make([]byte, 1+consts.Uint64Len)
+	k[0] = blockTxIDsPrefix
+	binary.BigEndian.PutUint64(k[1:], height)
+	return k
+}
+
+func PrefixWarpMessageKey(id ids.ID) []byte {
+	k := make([]byte, 1+ids.IDLen)
+	k[0] = warpMessagePrefix
+	copy(k[1:], id[:])
+	return k
+}
+
+func PrefixWarpSignatureKey(id ids.ID, publicKey []byte) []byte {
+	k := make([]byte, 1+ids.IDLen+bls.PublicKeyLen)
+	k[0] = warpSignaturePrefix
+	copy(k[1:], id[:])
+	copy(k[1+ids.IDLen:], publicKey)
+	return k
+}
+
 func (vm *VM) HasGenesis() (bool, error) {
 	return vm.HasDiskBlock(0)
 }
@@ -97,8 +149,12 @@ func (vm *VM) shouldComapct(expiryHeight uint64) bool {
 // adds [blk] to the [acceptedCache], and deletes any expired blocks from
 // disk.
 //
-// Blocks written to disk are only used when restarting the node. During normal
-// operation, we only fetch blocks from memory.
+// Blocks written to disk are only used when restarting the node and to serve
+// explorers. During normal operation, we only fetch blocks from memory.
+//
+// The results of [blk] (if it was executed) and the height of each of its
+// transactions are stored alongside it, and expire with it. So do the warp
+// messages it sent and the signatures gathered for them.
 //
 // We store blocks by height because it doesn't cause nearly as much
 // compaction as storing blocks randomly on-disk (when using [block.ID]).
@@ -118,12 +174,57 @@ func (vm *VM) UpdateLastAccepted(blk *chain.StatelessBlock) error {
 	if err := batch.Put(PrefixBlockHeightIDKey(blk.Height()), blkID[:]); err != nil {
 		return err
 	}
+	if results := blk.Results(); results != nil {
+		resultsBytes, err := chain.MarshalResults(results)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(PrefixBlockResultsKey(blk.Height()), resultsBytes); err != nil {
+			return err
+		}
+		var warpIDs []byte
+		for _, result := range results {
+			if result.WarpMessage != nil {
+				id := result.WarpMessage.ID()
+				warpIDs = append(warpIDs, id[:]...)
+			}
+		}
+		if len(warpIDs) > 0 {
+			if err := batch.Put(PrefixBlockWarpIDsKey(blk.Height()), warpIDs); err != nil {
+				return err
+			}
+		}
+	}
+	// The IDs are also listed under the height, to remove them from the index
+	// when the block expires
+	txIDs := make([]byte, 0, len(blk.Txs)*ids.IDLen)
+	for _, tx := range blk.Txs {
+		txID := tx.ID()
+		if err := batch.Put(PrefixTxIDHeightKey(txID), bigEndianHeight); err != nil {
+			return err
+		}
+		txIDs = append(txIDs, txID[:]...)
+	}
+	if len(txIDs) > 0 {
+		if err := batch.Put(PrefixBlockTxIDsKey(blk.Height()), txIDs); err != nil {
+			return err
+		}
+	}
 	expiryHeight := blk.Height() - uint64(vm.config.GetAcceptedBlockWindow())
 	var expired bool
 	if expiryHeight > 0 && expiryHeight < blk.Height() { // ensure we don't free genesis
+		if err := vm.deleteDiskTxIDHeights(batch, expiryHeight); err != nil {
+			return err
+		}
 		if err := batch.Delete(PrefixBlockKey(expiryHeight)); err != nil {
 			return err
 		}
+		if err := batch.Delete(PrefixBlockResultsKey(expiryHeight)); err != nil {
+			return err
+		}
+		if err := vm.deleteDiskWarpMessages(batch, expiryHeight); err != nil {
+			return err
+		}
 		blkID, err := vm.vmDB.Get(PrefixBlockHeightIDKey(expiryHeight))
 		if err == nil {
 			if err := batch.Delete(PrefixBlockIDHeightKey(ids.ID(blkID))); err != nil {
@@ -186,6 +287,78 @@ func (vm *VM) GetBlockIDHeight(blkID ids.ID) (uint64, error) {
 	return binary.BigEndian.Uint64(b), nil
 }
 
+// deleteDiskTxIDHeights removes the transactions of the block at [height] from
+// the transaction index.
+//
+// The IDs are listed when the block is stored, so there is nothing to remove
+// if the block had no transactions or was never stored (before the node state
+// synced).
+func (vm *VM) deleteDiskTxIDHeights(batch database.Batch, height uint64) error {
+	k := PrefixBlockTxIDsKey(height)
+	b, err := vm.vmDB.Get(k)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: unable to delete txIDs at height %d", err, height)
+	}
+	for i := 0; i+ids.IDLen <= len(b); i += ids.IDLen {
+		if err := batch.Delete(PrefixTxIDHeightKey(ids.ID(b[i : i+ids.IDLen]))); err != nil {
+			return err
+		}
+	}
+	return batch.Delete(k)
+}
+
+// deleteDiskWarpMessages removes the warp messages sent by the block at
+// [height], and the signatures gathered for them.
+func (vm *VM) deleteDiskWarpMessages(batch database.Batch, height uint64) error {
+	k := PrefixBlockWarpIDsKey(height)
+	b, err := vm.vmDB.Get(k)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: unable to delete warp messages at height %d", err, height)
+	}
+	for i := 0; i+ids.IDLen <= len(b); i += ids.IDLen {
+		id := ids.ID(b[i : i+ids.IDLen])
+		if err := batch.Delete(PrefixWarpMessageKey(id)); err != nil {
+			return err
+		}
+		iter := vm.vmDB.NewIteratorWithPrefix(PrefixWarpSignatureKey(id, nil)[:1+ids.IDLen])
+		for iter.Next() {
+			if err := batch.Delete(slices.Clone(iter.Key())); err != nil {
+				iter.Release()
+				return err
+			}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+	return batch.Delete(k)
+}
+
+// GetDiskBlockResults returns the results of the accepted block at [height].
+// Blocks accepted without being executed (during state sync) have no results.
+func (vm *VM) GetDiskBlockResults(height uint64) ([]*chain.Result, error) {
+	b, err := vm.vmDB.Get(PrefixBlockResultsKey(height))
+	if err != nil {
+		return nil, err
+	}
+	return chain.UnmarshalResults(b)
+}
+
+func (vm *VM) GetTxIDHeight(txID ids.ID) (uint64, error) {
+	b, err := vm.vmDB.Get(PrefixTxIDHeightKey(txID))
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
 // CompactDiskBlocks forces compaction on the entire range of blocks up to [lastExpired].
 //
 // This can be used to ensure we clean up all large tombstoned keys on a regular basis instead
@@ -194,6 +367,47 @@ func (vm *VM) CompactDiskBlocks(lastExpired uint64) error {
 	return vm.vmDB.Compact([]byte{blockPrefix}, PrefixBlockKey(lastExpired))
 }
 
+func (vm *VM) PutDiskWarpMessage(msg *warp.UnsignedMessage) error {
+	return vm.vmDB.Put(PrefixWarpMessageKey(msg.ID()), msg.Bytes())
+}
+
+func (vm *VM) GetDiskWarpMessage(id ids.ID) (*warp.UnsignedMessage, error) {
+	b, err := vm.vmDB.Get(PrefixWarpMessageKey(id))
+	if err != nil {
+		return nil, err
+	}
+	return warp.ParseUnsignedMessage(b)
+}
+
+func (vm *VM) PutDiskWarpSignature(id ids.ID, publicKey []byte, signature []byte) error {
+	return vm.vmDB.Put(PrefixWarpSignatureKey(id, publicKey), signature)
+}
+
+func (vm *VM) HasDiskWarpSignature(id ids.ID, publicKey []byte) (bool, error) {
+	return vm.vmDB.Has(PrefixWarpSignatureKey(id, publicKey))
+}
+
+func (vm *VM) GetDiskWarpSignature(id ids.ID, publicKey []byte) ([]byte, error) {
+	return vm.vmDB.Get(PrefixWarpSignatureKey(id, publicKey))
+}
+
+// GetWarpSignatures returns all signatures of the warp message [id] that
+// were collected, including our own.
+func (vm *VM) GetDiskWarpSignatures(id ids.ID) ([]*chain.WarpSignature, error) {
+	prefix := PrefixWarpSignatureKey(id, nil)[:1+ids.IDLen]
+	iter := vm.vmDB.NewIteratorWithPrefix(prefix)
+	defer iter.Release()
+
+	signatures := []*chain.WarpSignature{}
+	for iter.Next() {
+		signatures = append(signatures, &chain.WarpSignature{
+			PublicKey: slices.Clone(iter.Key()[len(prefix):]),
+			Signature: slices.Clone(iter.Value()),
+		})
+	}
+	return signatures, iter.Error()
+}
+
 func (vm *VM) GetDiskIsSyncing() (bool, error) {
 	v, err := vm.vmDB.Get(isSyncing)
 	if errors.Is(err, database.ErrNotFound) {
@@ -6,6 +6,7 @@ package vm
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/profiler"
 	"github.com/ava-labs/avalanchego/utils/set"
@@ -114,6 +116,10 @@ type VM struct {
 	// Network manager routes p2p messages to pre-registered handlers
 	networkManager *network.Manager
 
+	// Warp manager signs outgoing warp messages and collects the signatures
+	// of other validators
+	warpManager *WarpManager
+
 	metrics  *Metrics
 	profiler profiler.ContinuousProfiler
 
@@ -178,6 +184,19 @@ func (vm *VM) Initialize(
 		return fmt.Errorf("implementation initialization failed: %w", err)
 	}
 
+	// Ensure the upgrades are valid and applied by the implementation, so
+	// that a bad schedule is caught on startup rather than when it activates
+	upgrades, err := chain.ParseUpgrades(upgradeBytes)
+	if err != nil {
+		return err
+	}
+	if err := upgrades.Verify(vm.actionRegistry, vm.authRegistry); err != nil {
+		return err
+	}
+	if err := upgrades.VerifyApplied(vm.c.Rules); err != nil {
+		return err
+	}
+
 	// Setup tracer
 	vm.tracer, err = trace.New(vm.config.GetTraceConfig())
 	if err != nil {
@@ -376,6 +395,11 @@ func (vm *VM) Initialize(
 	gossipHandler, gossipSender := vm.networkManager.Register()
 	vm.networkManager.SetHandler(gossipHandler, NewTxGossipHandler(vm))
 
+	// Setup warp signature collection
+	warpHandler, warpSender := vm.networkManager.Register()
+	vm.warpManager = NewWarpManager(vm, warpSender)
+	vm.networkManager.SetHandler(warpHandler, NewWarpHandler(vm))
+
 	// Startup block builder and gossiper
 	go vm.builder.Run()
 	go vm.gossiper.Run(gossipSender)
@@ -654,6 +678,47 @@ func (vm *VM) GetStatelessBlock(ctx context.Context, blkID ids.ID) (*chain.State
 	return vm.GetDiskBlock(ctx, blkHeight)
 }
 
+// GetAcceptedBlock returns the accepted block [blkID], if it is within the
+// accepted block window.
+func (vm *VM) GetAcceptedBlock(ctx context.Context, blkID ids.ID) (*chain.StatelessBlock, error) {
+	if blk, ok := vm.acceptedBlocksByID.Get(blkID); ok {
+		return blk, nil
+	}
+	height, err := vm.GetBlockIDHeight(blkID)
+	if err != nil {
+		return nil, err
+	}
+	return vm.GetAcceptedBlockByHeight(ctx, height)
+}
+
+// GetAcceptedBlockByHeight returns the accepted block at [height], if it is
+// within the accepted block window.
+func (vm *VM) GetAcceptedBlockByHeight(ctx context.Context, height uint64) (*chain.StatelessBlock, error) {
+	if height > vm.lastAccepted.Hght {
+		return nil, database.ErrNotFound
+	}
+	if blkID, ok := vm.acceptedBlocksByHeight.Get(height); ok {
+		if blk, ok := vm.acceptedBlocksByID.Get(blkID); ok {
+			return blk, nil
+		}
+	}
+	vm.metrics.blocksFromDisk.Inc()
+	return vm.GetDiskBlock(ctx, height)
+}
+
+// GetAcceptedBlockResults returns the results of the accepted block [blk], or
+// nil if it was accepted without being executed.
+func (vm *VM) GetAcceptedBlockResults(blk *chain.StatelessBlock) ([]*chain.Result, error) {
+	if results := blk.Results(); results != nil {
+		return results, nil
+	}
+	results, err := vm.GetDiskBlockResults(blk.Hght)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	return results, err
+}
+
 // implements "block.ChainVM.commom.VM.Parser"
 // replaces "core.SnowmanVM.ParseBlock"
 func (vm *VM) ParseBlock(ctx context.Context, source []byte) (snowman.Block, error) {
@@ -701,6 +766,15 @@ func (vm *VM) ParseBlock(ctx context.Context, source []byte) (snowman.Block, err
 
 // implements "block.ChainVM"
 func (vm *VM) BuildBlock(ctx context.Context) (snowman.Block, error) {
+	return vm.buildBlock(ctx, nil)
+}
+
+// implements "block.BuildBlockWithContextChainVM"
+func (vm *VM) BuildBlockWithContext(ctx context.Context, blockContext *block.Context) (snowman.Block, error) {
+	return vm.buildBlock(ctx, blockContext)
+}
+
+func (vm *VM) buildBlock(ctx context.Context, blockContext *block.Context) (snowman.Block, error) {
 	start := time.Now()
 	defer func() {
 		vm.metrics.blockBuild.Observe(float64(time.Since(start)))
@@ -738,7 +812,7 @@ func (vm *VM) BuildBlock(ctx context.Context) (snowman.Block, error) {
 		vm.snowCtx.Log.Warn("unable to get preferred block", zap.Error(err))
 		return nil, err
 	}
-	blk, err := chain.BuildBlock(ctx, vm, preferredBlk)
+	blk, err := chain.BuildBlock(ctx, vm, preferredBlk, blockContext)
 	if err != nil {
 		// This is a DEBUG log because BuildBlock may fail before
 		// the min build gap (especially when there are no transactions).
@@ -794,6 +868,10 @@ func (vm *VM) Submit(
 		return []error{err}
 	}
 
+	// Warp messages are verified at the current P-Chain height, which is
+	// only fetched if needed
+	var pChainHeight uint64
+
 	validTxs := []*chain.Transaction{}
 	for i, tx := range txs {
 		// Check if transaction is a repeat before doing any extra work
@@ -838,6 +916,22 @@ func (vm *VM) Submit(
 			}
 		}
 
+		// Verify warp message, so that transactions with invalid messages are
+		// not charged a fee
+		if tx.WarpMessage != nil {
+			if pChainHeight == 0 {
+				pChainHeight, err = vm.snowCtx.ValidatorState.GetCurrentHeight(ctx)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+			if err := tx.VerifyWarpMessage(ctx, r, vm.snowCtx.ValidatorState, pChainHeight); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
 		// PreExecute does not make any changes to state
 		//
 		// This may fail if the state we are utilizing is invalidated (if a trie
@@ -860,6 +954,48 @@ func (vm *VM) Submit(
 	return errs
 }
 
+// SimulateTx executes [tx] on top of the preferred block, as if it was
+// included in the next block, without verifying its signature. It is aborted
+// after [Config.GetSimulationTimeout].
+func (vm *VM) SimulateTx(ctx context.Context, tx *chain.Transaction) (*chain.Simulation, error) {
+	ctx, span := vm.tracer.Start(ctx, "VM.SimulateTx")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, vm.config.GetSimulationTimeout())
+	defer cancel()
+
+	if !vm.isReady() {
+		return nil, ErrNotReady
+	}
+	blk, err := vm.GetStatelessBlock(ctx, vm.preferred)
+	if err != nil {
+		return nil, err
+	}
+	view, err := blk.View(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	feeRaw, err := view.GetValue(ctx, chain.FeeKey(vm.StateManager().FeeKey()))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	r := vm.c.Rules(now)
+	feeManager, err := fees.NewManager(feeRaw).ComputeNext(blk.Tmstmp, now, r)
+	if err != nil {
+		return nil, err
+	}
+	warpVerified := false
+	if tx.WarpMessage != nil {
+		pChainHeight, err := vm.snowCtx.ValidatorState.GetCurrentHeight(ctx)
+		if err != nil {
+			return nil, err
+		}
+		warpVerified = tx.VerifyWarpMessage(ctx, r, vm.snowCtx.ValidatorState, pChainHeight) == nil
+	}
+	return tx.Simulate(ctx, feeManager, vm.c.StateManager(), r, view, now, warpVerified)
+}
+
 // "SetPreference" implements "block.ChainVM"
 // replaces "core.SnowmanVM.SetPreference"
 func (vm *VM) SetPreference(_ context.Context, id ids.ID) error {
@@ -8,10 +8,13 @@ import (
 	"testing"
 
 	"github.com/ava-labs/avalanchego/api/metrics"
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
@@ -80,3 +83,76 @@ func TestBlockCache(t *testing.T) {
 	require.NoError(err)
 	require.Equal(blk, blk2)
 }
+
+func TestDeleteDiskTxIDHeights(t *testing.T) {
+	require := require.New(t)
+
+	vm := VM{vmDB: memdb.New()}
+
+	// The transactions of the block at height 7 are listed under its height
+	expired, kept := ids.GenerateTestID(), ids.GenerateTestID()
+	require.NoError(vm.vmDB.Put(PrefixTxIDHeightKey(expired), []byte{7}))
+	require.NoError(vm.vmDB.Put(PrefixTxIDHeightKey(kept), []byte{8}))
+	require.NoError(vm.vmDB.Put(PrefixBlockTxIDsKey(7), expired[:]))
+
+	// Blocks that were never stored have no transactions in the index
+	batch := vm.vmDB.NewBatch()
+	require.NoError(vm.deleteDiskTxIDHeights(batch, 6))
+	require.NoError(vm.deleteDiskTxIDHeights(batch, 7))
+	require.NoError(batch.Write())
+	for k, expected := range map[string]bool{
+		string(PrefixTxIDHeightKey(expired)): false,
+		string(PrefixTxIDHeightKey(kept)):    true,
+		string(PrefixBlockTxIDsKey(7)):       false,
+	} {
+		has, err := vm.vmDB.Has([]byte(k))
+		require.NoError(err)
+		require.Equal(expected, has)
+	}
+
+	// Other errors are returned
+	require.NoError(vm.vmDB.Close())
+	require.ErrorIs(vm.deleteDiskTxIDHeights(vm.vmDB.NewBatch(), 7), database.ErrClosed)
+}
+
+func TestDeleteDiskWarpMessages(t *testing.T) {
+	require := require.New(t)
+
+	vm := VM{vmDB: memdb.New()}
+	publicKeys := [][]byte{{1}, {2}}
+	msgs := make([]*warp.UnsignedMessage, 3)
+	for i := range msgs {
+		msg, err := warp.NewUnsignedMessage(constants.UnitTestID, ids.GenerateTestID(), []byte{byte(i)})
+		require.NoError(err)
+		require.NoError(vm.PutDiskWarpMessage(msg))
+		for _, publicKey := range publicKeys {
+			require.NoError(vm.PutDiskWarpSignature(msg.ID(), publicKey, []byte{byte(i)}))
+		}
+		msgs[i] = msg
+	}
+
+	// The first two messages were sent by the block at height 7
+	first, second := msgs[0].ID(), msgs[1].ID()
+	require.NoError(vm.vmDB.Put(PrefixBlockWarpIDsKey(7), append(first[:], second[:]...)))
+
+	batch := vm.vmDB.NewBatch()
+	require.NoError(vm.deleteDiskWarpMessages(batch, 6))
+	require.NoError(vm.deleteDiskWarpMessages(batch, 7))
+	require.NoError(batch.Write())
+
+	for i, msg := range msgs {
+		_, err := vm.GetDiskWarpMessage(msg.ID())
+		signatures, sigErr := vm.GetDiskWarpSignatures(msg.ID())
+		require.NoError(sigErr)
+		if i < 2 {
+			require.ErrorIs(err, database.ErrNotFound)
+			require.Empty(signatures)
+		} else {
+			require.NoError(err)
+			require.Len(signatures, len(publicKeys))
+		}
+	}
+	has, err := vm.vmDB.Has(PrefixBlockWarpIDsKey(7))
+	require.NoError(err)
+	require.False(has)
+}
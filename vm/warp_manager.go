@@ -0,0 +1,228 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+const (
+	// Validators may not have accepted a message yet when we request their
+	// signature, so we retry a few times
+	maxWarpSignatureAttempts = 5
+	warpSignatureRetryDelay  = 2 * time.Second
+)
+
+type warpJob struct {
+	msg       *warp.UnsignedMessage
+	nodeID    ids.NodeID
+	publicKey *bls.PublicKey
+	attempts  int
+}
+
+// WarpManager signs the warp messages sent by accepted transactions and
+// requests the signatures of the other validators of the subnet, so that
+// relayers can aggregate them.
+type WarpManager struct {
+	vm        *VM
+	appSender common.AppSender
+
+	l         sync.Mutex
+	requestID uint32
+	jobs      map[uint32]*warpJob
+}
+
+func NewWarpManager(vm *VM, appSender common.AppSender) *WarpManager {
+	return &WarpManager{
+		vm:        vm,
+		appSender: appSender,
+		jobs:      map[uint32]*warpJob{},
+	}
+}
+
+// Accepted stores [msg] with our signature and requests the signatures of
+// the other validators.
+func (w *WarpManager) Accepted(ctx context.Context, msg *warp.UnsignedMessage) error {
+	if err := w.vm.PutDiskWarpMessage(msg); err != nil {
+		return err
+	}
+	signature, err := w.vm.snowCtx.WarpSigner.Sign(msg)
+	if err != nil {
+		return err
+	}
+	if err := w.vm.PutDiskWarpSignature(msg.ID(), w.vm.pkBytes, signature); err != nil {
+		return err
+	}
+	w.Gather(ctx, msg)
+	return nil
+}
+
+// Gather requests the signature of [msg] from the current validators that
+// have not provided one yet.
+func (w *WarpManager) Gather(ctx context.Context, msg *warp.UnsignedMessage) {
+	vdrs, _ := w.vm.CurrentValidators(ctx)
+	for nodeID, vdr := range vdrs {
+		if nodeID == w.vm.snowCtx.NodeID || vdr.PublicKey == nil {
+			continue
+		}
+		exists, err := w.vm.HasDiskWarpSignature(msg.ID(), bls.PublicKeyToCompressedBytes(vdr.PublicKey))
+		if err != nil {
+			w.vm.Logger().Warn("unable to check warp signature", zap.Error(err))
+			continue
+		}
+		if exists {
+			continue
+		}
+		w.request(ctx, &warpJob{msg: msg, nodeID: nodeID, publicKey: vdr.PublicKey})
+	}
+}
+
+func (w *WarpManager) request(ctx context.Context, job *warpJob) {
+	select {
+	case <-w.vm.stop:
+		return
+	default:
+	}
+
+	w.l.Lock()
+	requestID := w.requestID
+	w.requestID++
+	w.jobs[requestID] = job
+	w.l.Unlock()
+
+	msgID := job.msg.ID()
+	if err := w.appSender.SendAppRequest(ctx, set.Of(job.nodeID), requestID, msgID[:]); err != nil {
+		w.vm.Logger().Warn("unable to request warp signature",
+			zap.Stringer("nodeID", job.nodeID),
+			zap.Stringer("msgID", msgID),
+			zap.Error(err),
+		)
+		w.l.Lock()
+		delete(w.jobs, requestID)
+		w.l.Unlock()
+	}
+}
+
+func (w *WarpManager) retry(job *warpJob) {
+	job.attempts++
+	if job.attempts >= maxWarpSignatureAttempts {
+		w.vm.Logger().Info("could not get warp signature",
+			zap.Stringer("nodeID", job.nodeID),
+			zap.Stringer("msgID", job.msg.ID()),
+		)
+		return
+	}
+	time.AfterFunc(warpSignatureRetryDelay, func() {
+		w.request(context.Background(), job)
+	})
+}
+
+func (w *WarpManager) popJob(requestID uint32) *warpJob {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	job, ok := w.jobs[requestID]
+	if !ok {
+		return nil
+	}
+	delete(w.jobs, requestID)
+	return job
+}
+
+// AppRequest serves our signature of the warp message requested, if we
+// accepted it.
+func (w *WarpManager) AppRequest(
+	ctx context.Context,
+	nodeID ids.NodeID,
+	requestID uint32,
+	request []byte,
+) error {
+	if len(request) != ids.IDLen {
+		w.vm.Logger().Debug("dropping invalid warp signature request", zap.Stringer("nodeID", nodeID))
+		return nil
+	}
+	signature, err := w.vm.GetDiskWarpSignature(ids.ID(request), w.vm.pkBytes)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		w.vm.Logger().Warn("unable to get warp signature", zap.Error(err))
+	}
+	// An empty response means we don't have the message (yet)
+	return w.appSender.SendAppResponse(ctx, nodeID, requestID, signature)
+}
+
+func (w *WarpManager) AppResponse(
+	_ context.Context,
+	nodeID ids.NodeID,
+	requestID uint32,
+	response []byte,
+) error {
+	job := w.popJob(requestID)
+	if job == nil {
+		return nil
+	}
+	if len(response) == 0 {
+		w.retry(job)
+		return nil
+	}
+	signature, err := bls.SignatureFromBytes(response)
+	if err != nil || !bls.Verify(job.publicKey, signature, job.msg.Bytes()) {
+		w.vm.Logger().Warn("received invalid warp signature",
+			zap.Stringer("nodeID", nodeID),
+			zap.Stringer("msgID", job.msg.ID()),
+		)
+		return nil
+	}
+	publicKey := bls.PublicKeyToCompressedBytes(job.publicKey)
+	if err := w.vm.PutDiskWarpSignature(job.msg.ID(), publicKey, response); err != nil {
+		w.vm.Logger().Error("unable to store warp signature", zap.Error(err))
+	}
+	return nil
+}
+
+func (w *WarpManager) AppRequestFailed(_ context.Context, _ ids.NodeID, requestID uint32) error {
+	job := w.popJob(requestID)
+	if job == nil {
+		return nil
+	}
+	w.retry(job)
+	return nil
+}
+
+// GetWarpSignatures returns the warp message [msgID] sent by an accepted
+// transaction, the canonical validator set of the subnet at the current
+// P-Chain height, and the signatures of the message collected so far.
+func (vm *VM) GetWarpSignatures(
+	ctx context.Context,
+	msgID ids.ID,
+) (*warp.UnsignedMessage, []*warp.Validator, []*chain.WarpSignature, error) {
+	msg, err := vm.GetDiskWarpMessage(msgID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pChainHeight, err := vm.snowCtx.ValidatorState.GetCurrentHeight(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	vdrs, _, err := warp.GetCanonicalValidatorSet(ctx, vm.snowCtx.ValidatorState, pChainHeight, vm.snowCtx.SubnetID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	signatures, err := vm.GetDiskWarpSignatures(msgID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return msg, vdrs, signatures, nil
+}